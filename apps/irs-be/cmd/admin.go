@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"irs-be/internal/backup"
+	"irs-be/internal/config"
+	"irs-be/internal/services"
+)
+
+const adminUsage = `Usage: irs-be admin <command> [flags]
+
+Commands:
+  dump     Write the ticket table to a versioned NDJSON archive
+  restore  Write the tickets of an archive into the ticket table
+  migrate  List or run table migrations
+  seed     Generate an incident history for load tests and demos
+  archive  Apply the retention rules, moving matching tickets to the archive
+
+Run "irs-be admin <command> -h" for the flags of a command.
+`
+
+// runAdmin runs an admin subcommand and returns the process exit code
+func runAdmin(cfg config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, adminUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "dump":
+		return runDump(cfg, args[1:])
+	case "restore":
+		return runRestore(cfg, args[1:])
+	case "migrate":
+		return runMigrate(cfg, args[1:])
+	case "seed":
+		return runSeed(cfg, args[1:])
+	case "archive":
+		return runArchive(cfg, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown admin command %q\n\n%s", args[0], adminUsage)
+		return 2
+	}
+}
+
+func runDump(cfg config.Config, args []string) int {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+	table := flags.String("table", cfg.DynamoDB.TableName, "table to dump")
+	out := flags.String("out", "", "archive file to write (required)")
+	flags.Parse(args)
+
+	if *out == "" {
+		fmt.Fprintln(os.Stderr, "-out is required")
+		flags.Usage()
+		return 2
+	}
+
+	backupService, err := newBackupService(cfg, *table)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", *out, err)
+		return 1
+	}
+
+	count, err := backupService.Dump(context.Background(), file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to dump %s after %d tickets: %v\n", *table, count, err)
+		return 1
+	}
+
+	fmt.Printf("Dumped %d tickets from %s to %s\n", count, *table, *out)
+	if failures := backupService.DecodeFailures(); len(failures) > 0 {
+		fmt.Fprintf(os.Stderr, "Left out %d items that could not be decoded:\n", len(failures))
+		for _, failure := range failures {
+			fmt.Fprintf(os.Stderr, "  %s\n", failure.Error)
+		}
+		return 1
+	}
+	return 0
+}
+
+func runRestore(cfg config.Config, args []string) int {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	table := flags.String("table", cfg.DynamoDB.TableName, "table to restore into")
+	in := flags.String("in", "", "archive file to read (required)")
+	mode := flags.String("mode", string(backup.RestoreUpsert), "upsert overwrites existing tickets, insert skips them")
+	dryRun := flags.Bool("dry-run", false, "validate the archive without writing")
+	flags.Parse(args)
+
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "-in is required")
+		flags.Usage()
+		return 2
+	}
+
+	backupService, err := newBackupService(cfg, *table)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	file, err := os.Open(*in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open %s: %v\n", *in, err)
+		return 1
+	}
+	defer file.Close()
+
+	report, err := backupService.Restore(context.Background(), file, backup.RestoreMode(*mode), *dryRun)
+	if report != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to restore %s: %v\n", *table, err)
+		return 1
+	}
+	if report.Failed > 0 || len(report.Invalid) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func newBackupService(cfg config.Config, table string) (*services.BackupService, error) {
+	cfg.DynamoDB.TableName = table
+
+	dynamoClient, err := services.NewDynamoDBClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize DynamoDB client: %v", err)
+	}
+
+	ticketService := services.NewTicketService(dynamoClient, cfg, nil)
+	return services.NewBackupService(dynamoClient, ticketService, cfg), nil
+}
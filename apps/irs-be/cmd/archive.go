@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"irs-be/internal/config"
+	"irs-be/internal/services"
+)
+
+// runArchive applies the retention rules once, like POST /api/admin/retention/run
+func runArchive(cfg config.Config, args []string) int {
+	flags := flag.NewFlagSet("archive", flag.ExitOnError)
+	rules := flags.String("rules", cfg.Retention.Rules, "retention rules, e.g. closed:180d,deleted:30d")
+	archiveURL := flags.String("archive", cfg.Retention.ArchiveURL, "archive to write to, file:///path or s3://bucket/prefix")
+	dryRun := flags.Bool("dry-run", false, "count the matching tickets without archiving them")
+	flags.Parse(args)
+
+	cfg.Retention.Rules = *rules
+	cfg.Retention.ArchiveURL = *archiveURL
+	if *rules == "" {
+		fmt.Fprintln(os.Stderr, "No retention rules, set RETENTION_RULES or pass -rules")
+		return 2
+	}
+
+	dynamoClient, err := services.NewDynamoDBClient(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize DynamoDB client: %v\n", err)
+		return 1
+	}
+	ticketService := services.NewTicketService(dynamoClient, cfg, nil)
+	archiveService, err := services.NewArchiveService(context.Background(), ticketService, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	report, err := archiveService.Run(context.Background(), *dryRun)
+	if report != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to apply retention rules: %v\n", err)
+		return 1
+	}
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
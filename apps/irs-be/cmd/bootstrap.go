@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"irs-be/internal/backup"
+	"irs-be/internal/bootstrap"
+	"irs-be/internal/config"
+	"irs-be/internal/services"
+)
+
+// runBootstrap creates or verifies the tables and returns the process exit code
+func runBootstrap(cfg config.Config, args []string) int {
+	flags := flag.NewFlagSet("bootstrap", flag.ExitOnError)
+	seed := flags.Bool("seed", false, "load the development fixtures into the ticket table, skipping tickets that exist")
+	force := flags.Bool("force", false, "allow -seed without DYNAMODB_ENDPOINT, i.e. against an AWS table")
+	timeout := flags.Duration("timeout", 5*time.Minute, "how long to wait for tables and indexes to become active")
+	flags.Parse(args)
+
+	if *seed && cfg.DynamoDB.Endpoint == "" && !*force {
+		fmt.Fprintf(os.Stderr, "Refusing to seed %s without DYNAMODB_ENDPOINT, pass -force to seed it anyway\n", cfg.DynamoDB.TableName)
+		return 2
+	}
+
+	dynamoClient, err := services.NewDynamoDBClient(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize DynamoDB client: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	failed := false
+	for _, table := range bootstrap.Tables(cfg) {
+		result, err := bootstrap.Ensure(ctx, dynamoClient, table)
+		switch {
+		case err != nil:
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+		case result.Created:
+			fmt.Printf("Created table %s\n", table.Name)
+		case len(result.CreatedIndexes) > 0:
+			fmt.Printf("Added %s to table %s\n", strings.Join(result.CreatedIndexes, ", "), table.Name)
+		case !result.EnabledTTL && !result.EnabledStream:
+			fmt.Printf("Table %s is up to date\n", table.Name)
+		}
+		if err == nil && result.EnabledTTL {
+			fmt.Printf("Enabled TTL on %s.%s\n", table.Name, table.TTLAttribute)
+		}
+		if err == nil && result.EnabledStream {
+			fmt.Printf("Enabled %s stream on %s\n", table.StreamViewType, table.Name)
+		}
+	}
+	if failed {
+		return 1
+	}
+
+	if *seed {
+		return seedTickets(cfg)
+	}
+	return 0
+}
+
+// seedTickets imports the fixtures in insert mode, so tickets seeded before, and
+// any changes made to them since, are left alone
+func seedTickets(cfg config.Config) int {
+	backupService, err := newBackupService(cfg, cfg.DynamoDB.TableName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	report, err := backupService.Import(context.Background(), bootstrap.Fixtures(time.Now()), backup.RestoreInsert, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to seed %s: %v\n", cfg.DynamoDB.TableName, err)
+		return 1
+	}
+	fmt.Printf("Seeded %d tickets into %s, %d were already there\n", report.Written, cfg.DynamoDB.TableName, report.Skipped)
+	if report.Failed > 0 {
+		for _, message := range report.Errors {
+			fmt.Fprintln(os.Stderr, message)
+		}
+		return 1
+	}
+	return 0
+}
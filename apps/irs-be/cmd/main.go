@@ -1,24 +1,112 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"irs-be/internal/auth"
+	"irs-be/internal/changefeed"
+	"irs-be/internal/conditional"
 	"irs-be/internal/config"
+	"irs-be/internal/deadline"
 	"irs-be/internal/handlers"
+	"irs-be/internal/logging"
+	"irs-be/internal/metrics"
+	"irs-be/internal/models"
+	"irs-be/internal/resilience"
 	"irs-be/internal/services"
-	"log"
+	"irs-be/internal/tenant"
+	"irs-be/internal/tracing"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 )
 
 func main() {
 	cfg := config.LoadConfig()
+	logging.Setup(cfg.Log.Level)
+	slog.Info("Configuration loaded", "config", cfg)
+	models.SetLegacyJSON(cfg.Server.LegacyFieldNames)
+	if !tenant.ValidTeam(cfg.Tenancy.DefaultTeam) {
+		fatal("Invalid DEFAULT_TEAM", fmt.Errorf("%q: use lowercase letters, digits, - and _", cfg.Tenancy.DefaultTeam))
+	}
+	tenant.SetDefault(cfg.Tenancy.DefaultTeam)
+
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		os.Exit(runAdmin(cfg, os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "bootstrap" {
+		os.Exit(runBootstrap(cfg, os.Args[2:]))
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		fatal("Failed to initialize tracing", err)
+	}
+
+	dynamoClient, err := services.NewDynamoDBClient(cfg)
+	if err != nil {
+		fatal("Failed to initialize DynamoDB client", err)
+	}
+
+	teams, err := tenant.Load(cfg.Tenancy.File, cfg.Metrics.SLA)
+	if err != nil {
+		fatal("Failed to load team settings", err)
+	}
+	verifier := auth.NewVerifier(cfg.Auth)
+	if verifier == nil {
+		slog.Warn("AUTH_JWT_SECRET is not set, requests act as members of the default team", "team", tenant.Default())
+	}
 
-	ticketService, err := services.NewTicketService(cfg)
+	events := services.NewEventBus()
+	ticketService := services.NewTicketService(dynamoClient, cfg, events)
+	// Encoded ticket responses live as long as the cached reads they are built from
+	responses := conditional.NewStore(cfg.Cache.TTL, cfg.Cache.MaxEntries)
+	ticketService.SubscribeInvalidations(responses.Purge)
+	webhookService := services.NewWebhookService(dynamoClient, cfg)
+	chatOpsService := services.NewChatOpsService(ticketService, teams, cfg)
+	reportService := services.NewReportService(ticketService, cfg)
+	postmortemService, err := services.NewPostmortemService(dynamoClient, ticketService, cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize TicketService: %v", err)
+		fatal("Failed to initialize PostmortemService", err)
+	}
+	backupService := services.NewBackupService(dynamoClient, ticketService, cfg)
+	ticketStatsService := services.NewTicketStatsService(ticketService, teams, cfg)
+	archiveService, err := services.NewArchiveService(context.Background(), ticketService, cfg)
+	if err != nil {
+		fatal("Failed to initialize ArchiveService", err)
+	}
+	events.Subscribe(webhookService.HandleEvent)
+	events.Subscribe(chatOpsService.HandleEvent)
+	webhookService.Start()
+	ticketStatsService.Start()
+	archiveService.Start()
+
+	var changeFeed *changefeed.Consumer
+	if cfg.Stream.Enabled {
+		if changeFeed, err = services.NewChangeFeed(context.Background(), dynamoClient, cfg); err != nil {
+			fatal("Failed to initialize the ticket table stream consumer", err)
+		}
+		changeFeed.Subscribe(ticketService.HandleChange)
+		if cfg.Stream.PublishEvents {
+			changeFeed.Subscribe(services.PublishExternalChanges(events))
+		}
+		changeFeed.Start()
 	}
-	ticketHandler := handlers.NewTicketHandler(ticketService)
+
+	ticketHandler := handlers.NewTicketHandler(ticketService, cfg.Server.StreamTimeout)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	chatOpsHandler := handlers.NewChatOpsHandler(chatOpsService)
+	reportHandler := handlers.NewReportHandler(reportService)
+	postmortemHandler := handlers.NewPostmortemHandler(postmortemService)
+	metaHandler := handlers.NewMetaHandler()
+	tenantHandler := handlers.NewTenantHandler(teams)
+	adminHandler := handlers.NewAdminHandler(backupService, archiveService, cfg.Admin.APIToken.Reveal(), cfg.Server.StreamTimeout)
 
 	app := fiber.New(fiber.Config{
 		AppName: "IRS Backend API",
@@ -28,29 +116,94 @@ func main() {
 				code = e.Code
 			}
 			return c.Status(code).JSON(fiber.Map{
-				"success": false,
-				"error":   err.Error(),
+				"success":   false,
+				"error":     err.Error(),
+				"requestId": logging.RequestID(c),
 			})
 		},
 	})
 
-	app.Use(logger.New())
+	app.Use(logging.RequestIDMiddleware())
+	app.Use(tracing.Middleware())
+	app.Use(metrics.Middleware())
+	app.Use(logging.AccessLogMiddleware())
+	app.Use(resilience.Middleware())
+	app.Use(deadline.Middleware(cfg.Server.RequestTimeout))
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: cfg.Server.CORSOrigin,
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
-		AllowMethods: "GET, POST, PUT, DELETE, OPTIONS",
+		AllowOrigins:  cfg.Server.CORSOrigin,
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, If-None-Match, X-Request-ID, traceparent, tracestate",
+		ExposeHeaders: "ETag, X-Request-ID, X-IRS-Query-Plan, X-IRS-Stale-Age, Retry-After",
+		AllowMethods:  "GET, POST, PUT, PATCH, DELETE, OPTIONS",
 	}))
 
+	app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
+
 	api := app.Group("/api")
 	api.Get("/health", ticketHandler.HealthCheck)
-	tickets := api.Group("/tickets")
-	tickets.Get("/", ticketHandler.GetAllTickets)
-	tickets.Get("/:id", ticketHandler.GetTicketByID)
-	tickets.Get("/status/:status", ticketHandler.GetTicketsByStatus)
-	tickets.Get("/severity/:severity", ticketHandler.GetTicketsBySeverity)
-	tickets.Get("/incident-type/:incidentType", ticketHandler.GetTicketsByIncidentType)
+	api.Get("/meta/enums", metaHandler.GetEnums)
+	// Ticket data is scoped to the caller's team; see auth.Middleware
+	authenticate := auth.Middleware(verifier)
+	api.Get("/me", authenticate, tenantHandler.GetMe)
+	tickets := api.Group("/tickets", authenticate, conditional.Middleware())
+	// Reads served from the ticket cache also keep their encoded responses
+	cached := responses.Cache(handlers.QueryPlanHeader)
+	tickets.Get("/", cached, ticketHandler.GetAllTickets)
+	tickets.Post("/", ticketHandler.CreateTicket)
+	// Static routes must be registered before /:id so they are not captured as an ID
+	tickets.Get("/export", ticketHandler.ExportTickets)
+	tickets.Get("/deleted", ticketHandler.GetDeletedTickets)
 	tickets.Get("/search", ticketHandler.SearchTickets)
-	tickets.Get("/filter", ticketHandler.GetTicketsWithFilters)
+	tickets.Get("/filter", cached, ticketHandler.GetTicketsWithFilters)
+	tickets.Get("/:id", cached, ticketHandler.GetTicketByID)
+	tickets.Patch("/:id", ticketHandler.UpdateTicket)
+	tickets.Delete("/:id", ticketHandler.DeleteTicket)
+	tickets.Post("/:id/undelete", ticketHandler.UndeleteTicket)
+	tickets.Post("/:id/ack", ticketHandler.AcknowledgeTicket)
+	tickets.Post("/:id/resolve", ticketHandler.ResolveTicket)
+	tickets.Get("/:id/links", ticketHandler.GetTicketLinks)
+	tickets.Post("/:id/links", ticketHandler.LinkTicket)
+	tickets.Delete("/:id/links/:linkedId", ticketHandler.UnlinkTicket)
+	tickets.Post("/:id/report\\:regenerate", deadline.Override(cfg.Server.ReportTimeout), reportHandler.RegenerateReport)
+	tickets.Get("/:id/postmortem", postmortemHandler.GetPostmortemDocument)
+	tickets.Get("/status/:status", cached, ticketHandler.GetTicketsByStatus)
+	tickets.Get("/severity/:severity", cached, ticketHandler.GetTicketsBySeverity)
+	tickets.Get("/incident-type/:incidentType", cached, ticketHandler.GetTicketsByIncidentType)
+
+	api.Get("/reports/prompts", reportHandler.GetPromptVersions)
+
+	postmortems := api.Group("/postmortems", authenticate)
+	postmortems.Get("/", postmortemHandler.GetPostmortems)
+	postmortems.Get("/action-items", postmortemHandler.GetActionItems)
+	postmortems.Get("/:ticketId", postmortemHandler.GetPostmortem)
+	postmortems.Put("/:ticketId", postmortemHandler.UpsertPostmortem)
+	postmortems.Post("/:ticketId/action-items", postmortemHandler.CreateActionItem)
+	postmortems.Patch("/:ticketId/action-items/:itemId", postmortemHandler.UpdateActionItem)
+	postmortems.Delete("/:ticketId/action-items/:itemId", postmortemHandler.DeleteActionItem)
+
+	webhooks := api.Group("/webhooks", authenticate)
+	webhooks.Post("/", webhookHandler.CreateWebhook)
+	webhooks.Get("/", webhookHandler.GetWebhooks)
+	webhooks.Get("/:id", webhookHandler.GetWebhookByID)
+	webhooks.Delete("/:id", webhookHandler.DeleteWebhook)
+	webhooks.Get("/:id/deliveries", webhookHandler.GetWebhookDeliveries)
+	webhooks.Post("/:id/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhook)
+
+	// The cross-team view, for platform admins only
+	platform := api.Group("/platform", authenticate, auth.RequirePlatformAdmin(), conditional.Middleware())
+	platform.Get("/teams", tenantHandler.GetTeams)
+	platform.Get("/tickets", cached, ticketHandler.GetTicketsWithFilters)
+	platform.Get("/tickets/export", ticketHandler.ExportTickets)
+	platform.Get("/tickets/:id", cached, ticketHandler.GetTicketByID)
+
+	slack := api.Group("/chatops/slack", chatOpsHandler.VerifySlackSignature)
+	slack.Post("/commands", chatOpsHandler.SlashCommand)
+	slack.Post("/interactions", chatOpsHandler.Interaction)
+
+	admin := api.Group("/admin", adminHandler.RequireAdminToken)
+	admin.Get("/tickets/backup", adminHandler.BackupTickets)
+	admin.Get("/tickets/undecodable", adminHandler.GetUndecodableTickets)
+	admin.Post("/tickets/restore", deadline.Override(cfg.Server.StreamTimeout), adminHandler.RestoreTickets)
+	admin.Post("/retention/run", deadline.Override(cfg.Server.StreamTimeout), adminHandler.RunRetention)
 
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -59,13 +212,37 @@ func main() {
 			"version": "1.0.0",
 			"endpoints": fiber.Map{
 				"health":                   "/api/health",
+				"metrics":                  "/metrics",
+				"enums":                    "/api/meta/enums",
 				"tickets":                  "/api/tickets",
 				"ticket_by_id":             "/api/tickets/:id",
+				"undelete_ticket":          "/api/tickets/:id/undelete",
+				"deleted_tickets":          "/api/tickets/deleted",
 				"tickets_by_status":        "/api/tickets/status/:status",
 				"tickets_by_severity":      "/api/tickets/severity/:severity",
 				"tickets_by_incident_type": "/api/tickets/incident-type/:incidentType",
 				"search_tickets":           "/api/tickets/search?q=query",
-				"filter_tickets":           "/api/tickets/filter?severity=critical&category=kubernetes",
+				"filter_tickets":           "/api/tickets/filter?q=severity:(critical OR high) -status:closed&sort=-createdAt",
+				"export_tickets":           "/api/tickets/export?format=csv|ndjson|json&fields=id,title",
+				"regenerate_report":        "/api/tickets/:id/report:regenerate",
+				"ticket_links":             "/api/tickets/:id/links?depth=2",
+				"unlink_ticket":            "/api/tickets/:id/links/:linkedId",
+				"report_prompts":           "/api/reports/prompts",
+				"postmortem_document":      "/api/tickets/:id/postmortem?format=md|html",
+				"postmortems":              "/api/postmortems",
+				"action_items":             "/api/postmortems/action-items",
+				"webhooks":                 "/api/webhooks",
+				"me":                       "/api/me",
+				"platform_teams":           "/api/platform/teams",
+				"platform_tickets":         "/api/platform/tickets?q=team:payments status:open",
+				"platform_export":          "/api/platform/tickets/export?format=csv|ndjson|json",
+				"webhook_deliveries":       "/api/webhooks/:id/deliveries",
+				"slack_commands":           "/api/chatops/slack/commands",
+				"slack_interactions":       "/api/chatops/slack/interactions",
+				"admin_backup":             "/api/admin/tickets/backup",
+				"admin_restore":            "/api/admin/tickets/restore?mode=upsert|insert&dryRun=true",
+				"admin_undecodable":        "/api/admin/tickets/undecodable",
+				"admin_retention":          "/api/admin/retention/run?dryRun=true",
 			},
 		})
 	})
@@ -79,8 +256,45 @@ func main() {
 		host = "0.0.0.0"
 	}
 
-	log.Printf("Starting server on %s:%s", host, port)
-	if err := app.Listen(host + ":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	listenErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting server", "host", host, "port", port)
+		listenErr <- app.Listen(host + ":" + port)
+	}()
+
+	// Kubernetes sends SIGTERM on rolling updates; stop accepting connections and
+	// let in-flight requests finish before the pod is killed
+	signals, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	select {
+	case err := <-listenErr:
+		fatal("Failed to start server", err)
+	case <-signals.Done():
+	}
+
+	slog.Info("Shutting down, draining in-flight requests", "timeout", cfg.Server.ShutdownTimeout.String())
+	if err := app.ShutdownWithTimeout(cfg.Server.ShutdownTimeout); err != nil {
+		slog.Error("Failed to drain requests before shutdown", "error", err)
+	}
+
+	if changeFeed != nil {
+		changeFeed.Stop()
 	}
+	archiveService.Stop()
+	ticketStatsService.Stop()
+	webhookService.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// fatal logs an unrecoverable startup error and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
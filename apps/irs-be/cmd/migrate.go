@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"irs-be/internal/config"
+	"irs-be/internal/migration"
+	"irs-be/internal/services"
+)
+
+const migrateUsage = `Usage: irs-be admin migrate <command> [flags]
+
+Commands:
+  list  List the registered migrations
+  run   Backfill a migration over the ticket table, resuming from its checkpoint
+
+Run "irs-be admin migrate run -h" for its flags.
+`
+
+// runMigrate runs a migrate subcommand and returns the process exit code
+func runMigrate(cfg config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		for _, m := range migration.All() {
+			fmt.Printf("%s  %s\n", m.ID, m.Description)
+		}
+		return 0
+	case "run":
+		return runMigration(cfg, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown migrate command %q\n\n%s", args[0], migrateUsage)
+		return 2
+	}
+}
+
+func runMigration(cfg config.Config, args []string) int {
+	flags := flag.NewFlagSet("migrate run", flag.ExitOnError)
+	id := flags.String("id", "", "migration to run (required), see irs-be admin migrate list")
+	table := flags.String("table", cfg.DynamoDB.TableName, "table to migrate")
+	checkpoint := flags.String("checkpoint", "", "progress file to resume from (default <table>.<id>.json)")
+	pageSize := flags.Int("page-size", 100, "items read per scan page")
+	dryRun := flags.Bool("dry-run", false, "count the items that need the migration without writing")
+	restart := flags.Bool("restart", false, "ignore an existing checkpoint and scan the whole table again")
+	flags.Parse(args)
+
+	m, ok := migration.Lookup(*id)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown migration %q\n", *id)
+		flags.Usage()
+		return 2
+	}
+	if *checkpoint == "" {
+		*checkpoint = fmt.Sprintf("%s.%s.json", *table, m.ID)
+	}
+	if *restart {
+		if err := os.Remove(*checkpoint); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Failed to remove %s: %v\n", *checkpoint, err)
+			return 1
+		}
+	}
+
+	cfg.DynamoDB.TableName = *table
+	client, err := services.NewDynamoDBClient(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize DynamoDB client: %v\n", err)
+		return 1
+	}
+
+	// Stop after the current page on Ctrl-C so the checkpoint is consistent
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	runner := &migration.Runner{
+		Client:     client,
+		Table:      *table,
+		PageSize:   int32(*pageSize),
+		Checkpoint: *checkpoint,
+		DryRun:     *dryRun,
+		Report: func(p migration.Progress) {
+			fmt.Printf("%s: scanned %d of ~%d (%.0f%%), updated %d, conflicts %d, failed %d\n",
+				p.Migration, p.Scanned, p.EstimatedItems, p.Percent(), p.Updated, p.Conflicts, p.Failed)
+		},
+	}
+
+	progress, err := runner.Run(ctx, m)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Migration %s stopped: %v\nRun the same command again to resume from %s\n", m.ID, err, *checkpoint)
+		return 1
+	}
+	for _, e := range progress.Errors {
+		fmt.Fprintln(os.Stderr, e)
+	}
+
+	verb := "Updated"
+	if progress.DryRun {
+		verb = "Would update"
+	}
+	fmt.Printf("Migration %s complete on %s: %s %d of %d scanned items, %d conflicts, %d failed\n",
+		m.ID, *table, verb, progress.Updated, progress.Scanned, progress.Conflicts, progress.Failed)
+	if progress.Failed > 0 || progress.Conflicts > 0 {
+		fmt.Println("Run again with -restart to retry the items that were not updated")
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"irs-be/internal/backup"
+	"irs-be/internal/config"
+	"irs-be/internal/models"
+	"irs-be/internal/seed"
+)
+
+func runSeed(cfg config.Config, args []string) int {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	seedValue := flags.Int64("seed", 1, "random seed; the same seed, end and options generate the same tickets")
+	days := flags.Int("days", 180, "days of history to generate")
+	rate := flags.Float64("rate", 1, "volume multiplier, 1 is about 12 incidents a day")
+	end := flags.String("end", "", "RFC 3339 time the history ends at (default now, to the hour)")
+	table := flags.String("table", cfg.DynamoDB.TableName, "table to write to")
+	out := flags.String("out", "", "write an archive to this file instead of the table")
+	mode := flags.String("mode", string(backup.RestoreInsert), "upsert overwrites existing tickets, insert skips them")
+	dryRun := flags.Bool("dry-run", false, "generate and report without writing")
+	force := flags.Bool("force", false, "allow writing without DYNAMODB_ENDPOINT, i.e. to an AWS table")
+	flags.Parse(args)
+
+	endTime := time.Now().UTC().Truncate(time.Hour)
+	if *end != "" {
+		parsed, err := time.Parse(time.RFC3339, *end)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -end %q: %v\n", *end, err)
+			return 2
+		}
+		endTime = parsed
+	}
+	if *days <= 0 || *rate <= 0 {
+		fmt.Fprintln(os.Stderr, "-days and -rate must be positive")
+		return 2
+	}
+	if *out == "" && !*dryRun && cfg.DynamoDB.Endpoint == "" && !*force {
+		fmt.Fprintf(os.Stderr, "Refusing to seed %s without DYNAMODB_ENDPOINT, pass -force to seed it anyway\n", *table)
+		return 2
+	}
+
+	tickets := seed.Generate(seed.Options{Seed: *seedValue, End: endTime, Days: *days, Rate: *rate})
+	fmt.Fprintf(os.Stderr, "Generated %d tickets with -seed %d -end %s\n", len(tickets), *seedValue, endTime.Format(time.RFC3339))
+
+	if *out != "" {
+		return writeSeedArchive(*out, *table, tickets)
+	}
+
+	backupService, err := newBackupService(cfg, *table)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	report, err := backupService.Import(context.Background(), tickets, backup.RestoreMode(*mode), *dryRun)
+	if report != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to seed %s: %v\n", *table, err)
+		return 1
+	}
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// writeSeedArchive writes the tickets as an archive that irs-be admin restore can load
+func writeSeedArchive(path, table string, tickets []models.IncidentTicket) int {
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", path, err)
+		return 1
+	}
+
+	writer, err := backup.NewWriter(file, table)
+	for i := 0; err == nil && i < len(tickets); i++ {
+		err = writer.Write(tickets[i])
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", path, err)
+		return 1
+	}
+
+	fmt.Printf("Wrote %d tickets to %s\n", len(tickets), path)
+	return 0
+}
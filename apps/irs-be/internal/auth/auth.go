@@ -0,0 +1,186 @@
+// Package auth resolves the principal behind an API request from its bearer token
+// and scopes the request to the principal's team.
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"irs-be/internal/config"
+)
+
+// clockSkew is how far exp and nbf may be off before a token is rejected
+const clockSkew = time.Minute
+
+// ErrInvalidToken is returned for tokens that are malformed, badly signed or expired
+var ErrInvalidToken = errors.New("invalid token")
+
+// Principal is the caller of an API request
+type Principal struct {
+	Subject string   `json:"subject"`
+	Team    string   `json:"team"`
+	Roles   []string `json:"roles"`
+	// PlatformAdmin grants the cross-team view
+	PlatformAdmin bool `json:"platformAdmin"`
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the principal
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal of a request context
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// Verifier checks HS256 JWTs issued by the identity provider in front of irs-be
+type Verifier struct {
+	secret    []byte
+	issuer    string
+	audience  string
+	teamClaim string
+	adminRole string
+}
+
+// NewVerifier creates a verifier from the auth configuration. It returns nil when
+// no secret is configured, which leaves authentication off.
+func NewVerifier(cfg config.AuthConfig) *Verifier {
+	if !cfg.JWTSecret.IsSet() {
+		return nil
+	}
+	return &Verifier{
+		secret:    []byte(cfg.JWTSecret.Reveal()),
+		issuer:    cfg.Issuer,
+		audience:  cfg.Audience,
+		teamClaim: cfg.TeamClaim,
+		adminRole: cfg.PlatformAdminRole,
+	}
+}
+
+// Verify checks a token's signature, lifetime, issuer and audience and returns its
+// principal. The team is left empty when the token has no team claim.
+func (v *Verifier) Verify(token string, now time.Time) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, err
+	}
+	// Only accept the algorithm we sign with; "none" and asymmetric algorithms
+	// would let a caller pick how the token is checked
+	if header.Alg != "HS256" {
+		return Principal{}, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return Principal{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var claims map[string]json.RawMessage
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Principal{}, err
+	}
+
+	var exp, nbf float64
+	var issuer string
+	if err := claim(claims, "exp", &exp); err != nil || exp == 0 {
+		return Principal{}, fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return Principal{}, fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if err := claim(claims, "nbf", &nbf); err != nil {
+		return Principal{}, err
+	}
+	if nbf != 0 && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return Principal{}, fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+	if err := claim(claims, "iss", &issuer); err != nil {
+		return Principal{}, err
+	}
+	if v.issuer != "" && issuer != v.issuer {
+		return Principal{}, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if v.audience != "" && !hasAudience(claims["aud"], v.audience) {
+		return Principal{}, fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+
+	var principal Principal
+	if err := claim(claims, "sub", &principal.Subject); err != nil {
+		return Principal{}, err
+	}
+	if err := claim(claims, v.teamClaim, &principal.Team); err != nil {
+		return Principal{}, err
+	}
+	if err := claim(claims, "roles", &principal.Roles); err != nil {
+		return Principal{}, err
+	}
+	for _, role := range principal.Roles {
+		principal.PlatformAdmin = principal.PlatformAdmin || role == v.adminRole
+	}
+	return principal, nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a token
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+	return nil
+}
+
+// claim decodes an optional claim, leaving v unchanged when it is absent
+func claim(claims map[string]json.RawMessage, name string, v interface{}) error {
+	raw, ok := claims[name]
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%w: malformed %s claim", ErrInvalidToken, name)
+	}
+	return nil
+}
+
+// hasAudience reports whether the aud claim, a string or a list of strings, names audience
+func hasAudience(raw json.RawMessage, audience string) bool {
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) != nil {
+		return false
+	}
+	for _, aud := range list {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"irs-be/internal/models"
+	"irs-be/internal/tenant"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// anonymous is the principal of requests while authentication is off
+const anonymous = "anonymous"
+
+// Middleware resolves the principal of every request and scopes the request to the
+// principal's team. Without a verifier, authentication is off and every request
+// acts as a member of the default team, which owns all tickets of a deployment
+// that never assigned teams.
+func Middleware(verifier *Verifier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal := Principal{Subject: anonymous, Team: tenant.Default(), Roles: []string{}}
+
+		if verifier != nil {
+			token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+			if !ok || token == "" {
+				return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+					Success: false,
+					Error:   "Missing bearer token",
+				})
+			}
+
+			var err error
+			if principal, err = verifier.Verify(token, time.Now()); err != nil {
+				return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+					Success: false,
+					Error:   err.Error(),
+				})
+			}
+			if principal.Roles == nil {
+				principal.Roles = []string{}
+			}
+
+			// Platform admins without a team of their own work in the default team
+			// outside of the cross-team view
+			switch {
+			case principal.Team == "" && principal.PlatformAdmin:
+				principal.Team = tenant.Default()
+			case principal.Team == "":
+				return c.Status(http.StatusForbidden).JSON(models.APIResponse{
+					Success: false,
+					Error:   "Token does not name a team",
+				})
+			case !tenant.ValidTeam(principal.Team):
+				return c.Status(http.StatusForbidden).JSON(models.APIResponse{
+					Success: false,
+					Error:   "Token names an invalid team: " + principal.Team,
+				})
+			}
+		}
+
+		ctx := WithPrincipal(c.UserContext(), principal)
+		c.SetUserContext(tenant.WithScope(ctx, tenant.Team(principal.Team)))
+		return c.Next()
+	}
+}
+
+// RequirePlatformAdmin rejects callers without the platform admin role and widens
+// the scope of the others to every team. It must run after Middleware.
+func RequirePlatformAdmin() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := FromContext(c.UserContext())
+		if !ok || !principal.PlatformAdmin {
+			return c.Status(http.StatusForbidden).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Platform admin role required",
+			})
+		}
+
+		c.SetUserContext(tenant.WithScope(c.UserContext(), tenant.All()))
+		return c.Next()
+	}
+}
@@ -0,0 +1,210 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"irs-be/internal/models"
+)
+
+const (
+	// ArchiveFormat identifies a ticket archive in its header line
+	ArchiveFormat = "irs-ticket-archive"
+	// ArchiveVersion is the archive layout written by this build. Readers accept
+	// this version and older ones.
+	ArchiveVersion = 1
+
+	// maxLineSize bounds a single archived ticket, reports included
+	maxLineSize = 4 * 1024 * 1024
+)
+
+// ErrInvalidArchive is returned when the archive header is missing or not understood
+var ErrInvalidArchive = errors.New("invalid ticket archive")
+
+// Header is the first line of an archive; every following line is one ticket
+type Header struct {
+	Format    string `json:"format"`
+	Version   int    `json:"version"`
+	Table     string `json:"table"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// Writer writes a versioned NDJSON ticket archive
+type Writer struct {
+	encoder *json.Encoder
+	count   int
+}
+
+// NewWriter writes the archive header for the source table
+func NewWriter(w io.Writer, table string) (*Writer, error) {
+	encoder := json.NewEncoder(w)
+	err := encoder.Encode(Header{
+		Format:    ArchiveFormat,
+		Version:   ArchiveVersion,
+		Table:     table,
+		CreatedAt: models.FormatTimestamp(time.Now()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write archive header: %v", err)
+	}
+	return &Writer{encoder: encoder}, nil
+}
+
+// Write appends one ticket to the archive
+func (w *Writer) Write(ticket models.IncidentTicket) error {
+	if err := w.encoder.Encode(ticket); err != nil {
+		return fmt.Errorf("failed to write ticket %s: %v", ticket.ID, err)
+	}
+	w.count++
+	return nil
+}
+
+// Count returns the number of tickets written so far
+func (w *Writer) Count() int {
+	return w.count
+}
+
+// RecordError describes an archive line that could not be mapped to a ticket
+type RecordError struct {
+	Line    int    `json:"line"`
+	ID      string `json:"id,omitempty"`
+	Message string `json:"error"`
+}
+
+func (e *RecordError) Error() string {
+	if e.ID != "" {
+		return fmt.Sprintf("line %d (%s): %s", e.Line, e.ID, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Reader reads a ticket archive one record at a time
+type Reader struct {
+	scanner *bufio.Scanner
+	header  Header
+	line    int
+}
+
+// NewReader reads and checks the archive header
+func NewReader(r io.Reader) (*Reader, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	reader := &Reader{scanner: scanner}
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read archive header: %v", err)
+		}
+		return nil, fmt.Errorf("%w: archive is empty", ErrInvalidArchive)
+	}
+	reader.line = 1
+
+	if err := json.Unmarshal(scanner.Bytes(), &reader.header); err != nil {
+		return nil, fmt.Errorf("%w: unreadable header: %v", ErrInvalidArchive, err)
+	}
+	if reader.header.Format != ArchiveFormat {
+		return nil, fmt.Errorf("%w: unexpected format %q", ErrInvalidArchive, reader.header.Format)
+	}
+	if reader.header.Version < 1 || reader.header.Version > ArchiveVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d, this build reads up to version %d",
+			ErrInvalidArchive, reader.header.Version, ArchiveVersion)
+	}
+
+	return reader, nil
+}
+
+// Header returns the archive header
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Next returns the next ticket. A record that cannot be mapped to a ticket is
+// returned as a *RecordError so callers can report it and keep reading; io.EOF
+// marks the end of the archive.
+func (r *Reader) Next() (models.IncidentTicket, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := bytes.TrimSpace(r.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		return decodeTicket(r.line, data)
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return models.IncidentTicket{}, fmt.Errorf("failed to read archive line %d: %v", r.line+1, err)
+	}
+	return models.IncidentTicket{}, io.EOF
+}
+
+func decodeTicket(line int, data []byte) (models.IncidentTicket, error) {
+	var ticket models.IncidentTicket
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&ticket); err != nil {
+		// Recover the ID when possible so the report points at the right ticket
+		var partial struct {
+			ID string `json:"id"`
+		}
+		_ = json.Unmarshal(data, &partial)
+		return models.IncidentTicket{}, &RecordError{Line: line, ID: partial.ID, Message: err.Error()}
+	}
+
+	if problem := validateTicket(ticket); problem != "" {
+		return models.IncidentTicket{}, &RecordError{Line: line, ID: ticket.ID, Message: problem}
+	}
+	return ticket, nil
+}
+
+// validateTicket checks the attributes every ticket must carry
+func validateTicket(ticket models.IncidentTicket) string {
+	switch {
+	case ticket.ID == "":
+		return "missing id"
+	case ticket.Title == "":
+		return "missing title"
+	case ticket.Status == "":
+		return "missing status"
+	case ticket.Severity == "":
+		return "missing severity"
+	case ticket.CreatedAt == "":
+		return "missing createdAt"
+	}
+	if _, err := models.ParseTimestamp(ticket.CreatedAt); err != nil {
+		return fmt.Sprintf("invalid createdAt %q", ticket.CreatedAt)
+	}
+	return ""
+}
+
+// RestoreMode controls how restored tickets treat tickets already in the table
+type RestoreMode string
+
+const (
+	// RestoreUpsert overwrites existing tickets, so repeating a restore is idempotent
+	RestoreUpsert RestoreMode = "upsert"
+	// RestoreInsert only writes tickets whose ID is not in the table yet
+	RestoreInsert RestoreMode = "insert"
+)
+
+// IsValid reports whether the restore mode is known
+func (m RestoreMode) IsValid() bool {
+	return m == RestoreUpsert || m == RestoreInsert
+}
+
+// RestoreReport summarizes a restore
+type RestoreReport struct {
+	Header  Header         `json:"header"`
+	Mode    RestoreMode    `json:"mode"`
+	DryRun  bool           `json:"dryRun"`
+	Read    int            `json:"read"`
+	Written int            `json:"written"`
+	Skipped int            `json:"skipped"`
+	Failed  int            `json:"failed"`
+	Invalid []*RecordError `json:"invalid"`
+	Errors  []string       `json:"errors,omitempty"`
+}
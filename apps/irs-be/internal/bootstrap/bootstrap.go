@@ -0,0 +1,360 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// pollInterval is how often a table is described while waiting for it to become active
+const pollInterval = 2 * time.Second
+
+// Client is the subset of the DynamoDB API bootstrapping uses
+type Client interface {
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	UpdateTable(ctx context.Context, params *dynamodb.UpdateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTableOutput, error)
+	DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error)
+	UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error)
+}
+
+// Result reports what Ensure changed
+type Result struct {
+	Table          string   `json:"table"`
+	Created        bool     `json:"created"`
+	CreatedIndexes []string `json:"createdIndexes,omitempty"`
+	EnabledTTL     bool     `json:"enabledTtl,omitempty"`
+	EnabledStream  bool     `json:"enabledStream,omitempty"`
+}
+
+// MismatchError reports a table or index whose key, or a table whose TTL attribute,
+// differs from the one irs-be uses. It is not fixed in place: the table has to be
+// recreated, migrated or reconfigured by hand.
+type MismatchError struct {
+	Table    string
+	Problems []string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("table %s does not match: %s", e.Table, strings.Join(e.Problems, "; "))
+}
+
+// Ensure creates a table with its indexes, or adds the indexes a table is missing,
+// waits until the table and its indexes are active and turns on its TTL and stream. It is safe
+// to run again: an existing table is only verified. New tables are billed on demand.
+func Ensure(ctx context.Context, client Client, table Table) (*Result, error) {
+	result := &Result{Table: table.Name}
+
+	description, err := describe(ctx, client, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	if description == nil {
+		if err := create(ctx, client, table); err != nil {
+			return nil, err
+		}
+		result.Created = true
+		if err := wait(ctx, client, table.Name); err != nil {
+			return result, err
+		}
+		result.EnabledTTL, err = ensureTTL(ctx, client, table)
+		return result, err
+	}
+
+	if problems := verify(description, table); len(problems) > 0 {
+		return result, &MismatchError{Table: table.Name, Problems: problems}
+	}
+
+	existing := make(map[string]bool, len(description.GlobalSecondaryIndexes))
+	for _, index := range description.GlobalSecondaryIndexes {
+		existing[aws.ToString(index.IndexName)] = true
+	}
+	for _, index := range table.Indexes {
+		if existing[index.Name] {
+			continue
+		}
+		// DynamoDB adds one index per UpdateTable call, and only to an active table
+		if err := wait(ctx, client, table.Name); err != nil {
+			return result, err
+		}
+		if err := createIndex(ctx, client, description, index); err != nil {
+			return result, err
+		}
+		result.CreatedIndexes = append(result.CreatedIndexes, index.Name)
+	}
+	if err := wait(ctx, client, table.Name); err != nil {
+		return result, err
+	}
+	if result.EnabledTTL, err = ensureTTL(ctx, client, table); err != nil {
+		return result, err
+	}
+	result.EnabledStream, err = ensureStream(ctx, client, description, table)
+	return result, err
+}
+
+// ensureStream turns on the table's stream and reports whether it was off
+func ensureStream(ctx context.Context, client Client, description *types.TableDescription, table Table) (bool, error) {
+	if table.StreamViewType == "" {
+		return false, nil
+	}
+
+	if spec := description.StreamSpecification; spec != nil && aws.ToBool(spec.StreamEnabled) {
+		if spec.StreamViewType != table.StreamViewType {
+			// The view of a stream cannot change; replacing the stream is left to its owner
+			return false, &MismatchError{Table: table.Name, Problems: []string{
+				fmt.Sprintf("stream view is %s, want %s", spec.StreamViewType, table.StreamViewType),
+			}}
+		}
+		return false, nil
+	}
+
+	_, err := client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+		TableName: aws.String(table.Name),
+		StreamSpecification: &types.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: table.StreamViewType,
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to enable the stream of table %s: %v", table.Name, err)
+	}
+	return true, wait(ctx, client, table.Name)
+}
+
+// ensureTTL turns on TTL for the table's TTL attribute and reports whether it was off
+func ensureTTL(ctx context.Context, client Client, table Table) (bool, error) {
+	if table.TTLAttribute == "" {
+		return false, nil
+	}
+
+	output, err := client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String(table.Name)})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe TTL of table %s: %v", table.Name, err)
+	}
+	if ttl := output.TimeToLiveDescription; ttl != nil {
+		switch ttl.TimeToLiveStatus {
+		case types.TimeToLiveStatusEnabled, types.TimeToLiveStatusEnabling:
+			if name := aws.ToString(ttl.AttributeName); name != table.TTLAttribute {
+				// A table has a single TTL attribute; replacing it is left to its owner
+				return false, &MismatchError{Table: table.Name, Problems: []string{
+					fmt.Sprintf("TTL attribute is %s, want %s", name, table.TTLAttribute),
+				}}
+			}
+			return false, nil
+		}
+	}
+
+	_, err = client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(table.Name),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(table.TTLAttribute),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to enable TTL on table %s: %v", table.Name, err)
+	}
+	return true, nil
+}
+
+// describe returns the table description, or nil when the table does not exist
+func describe(ctx context.Context, client Client, name string) (*types.TableDescription, error) {
+	output, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)})
+	var notFound *types.ResourceNotFoundException
+	if errors.As(err, &notFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe table %s: %v", name, err)
+	}
+	return output.Table, nil
+}
+
+func create(ctx context.Context, client Client, table Table) error {
+	keys := []Key{table.Key}
+	indexes := make([]types.GlobalSecondaryIndex, len(table.Indexes))
+	for i, index := range table.Indexes {
+		keys = append(keys, index.Key)
+		indexes[i] = types.GlobalSecondaryIndex{
+			IndexName:  aws.String(index.Name),
+			KeySchema:  keySchema(index.Key),
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}
+	}
+
+	input := &dynamodb.CreateTableInput{
+		TableName:            aws.String(table.Name),
+		KeySchema:            keySchema(table.Key),
+		AttributeDefinitions: attributeDefinitions(keys...),
+		BillingMode:          types.BillingModePayPerRequest,
+	}
+	if len(indexes) > 0 {
+		input.GlobalSecondaryIndexes = indexes
+	}
+	if table.StreamViewType != "" {
+		input.StreamSpecification = &types.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: table.StreamViewType,
+		}
+	}
+
+	_, err := client.CreateTable(ctx, input)
+	var inUse *types.ResourceInUseException
+	if errors.As(err, &inUse) {
+		// Created concurrently; wait for it like for our own
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create table %s: %v", table.Name, err)
+	}
+	return nil
+}
+
+func createIndex(ctx context.Context, client Client, table *types.TableDescription, index Index) error {
+	action := &types.CreateGlobalSecondaryIndexAction{
+		IndexName:  aws.String(index.Name),
+		KeySchema:  keySchema(index.Key),
+		Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+	}
+	// An index of a provisioned table needs its own capacity; give it the table's
+	if billing := table.BillingModeSummary; (billing == nil || billing.BillingMode == types.BillingModeProvisioned) && table.ProvisionedThroughput != nil {
+		action.ProvisionedThroughput = &types.ProvisionedThroughput{
+			ReadCapacityUnits:  table.ProvisionedThroughput.ReadCapacityUnits,
+			WriteCapacityUnits: table.ProvisionedThroughput.WriteCapacityUnits,
+		}
+	}
+
+	_, err := client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+		TableName:                   table.TableName,
+		AttributeDefinitions:        attributeDefinitions(index.Key),
+		GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{{Create: action}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create index %s on %s: %v", index.Name, aws.ToString(table.TableName), err)
+	}
+	return nil
+}
+
+// verify compares the keys of an existing table and its indexes with the expected ones
+func verify(description *types.TableDescription, table Table) []string {
+	attributeTypes := make(map[string]types.ScalarAttributeType, len(description.AttributeDefinitions))
+	for _, definition := range description.AttributeDefinitions {
+		attributeTypes[aws.ToString(definition.AttributeName)] = definition.AttributeType
+	}
+
+	var problems []string
+	check := func(what string, schema []types.KeySchemaElement, want Key) {
+		got := keyOf(schema)
+		if got != want {
+			problems = append(problems, fmt.Sprintf("%s key is %s, want %s", what, got, want))
+			return
+		}
+		for _, name := range []string{want.Hash, want.Range} {
+			if name != "" && attributeTypes[name] != types.ScalarAttributeTypeS {
+				problems = append(problems, fmt.Sprintf("%s key attribute %s is of type %s, want S", what, name, attributeTypes[name]))
+			}
+		}
+	}
+
+	check("table", description.KeySchema, table.Key)
+	existing := make(map[string]types.GlobalSecondaryIndexDescription, len(description.GlobalSecondaryIndexes))
+	for _, index := range description.GlobalSecondaryIndexes {
+		existing[aws.ToString(index.IndexName)] = index
+	}
+	for _, index := range table.Indexes {
+		found, ok := existing[index.Name]
+		if !ok {
+			continue
+		}
+		check("index "+index.Name, found.KeySchema, index.Key)
+		// Queries on an index decode whole tickets
+		if found.Projection == nil || found.Projection.ProjectionType != types.ProjectionTypeAll {
+			problems = append(problems, fmt.Sprintf("index %s does not project all attributes", index.Name))
+		}
+	}
+	return problems
+}
+
+// wait polls the table until it and all of its indexes are active
+func wait(ctx context.Context, client Client, name string) error {
+	for {
+		description, err := describe(ctx, client, name)
+		if err != nil {
+			return err
+		}
+		if description != nil && active(description) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("table %s is not active yet: %v", name, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func active(description *types.TableDescription) bool {
+	if description.TableStatus != types.TableStatusActive {
+		return false
+	}
+	for _, index := range description.GlobalSecondaryIndexes {
+		if index.IndexStatus != types.IndexStatusActive {
+			return false
+		}
+	}
+	return true
+}
+
+func keySchema(key Key) []types.KeySchemaElement {
+	schema := []types.KeySchemaElement{{AttributeName: aws.String(key.Hash), KeyType: types.KeyTypeHash}}
+	if key.Range != "" {
+		schema = append(schema, types.KeySchemaElement{AttributeName: aws.String(key.Range), KeyType: types.KeyTypeRange})
+	}
+	return schema
+}
+
+func keyOf(schema []types.KeySchemaElement) Key {
+	var key Key
+	for _, element := range schema {
+		switch element.KeyType {
+		case types.KeyTypeHash:
+			key.Hash = aws.ToString(element.AttributeName)
+		case types.KeyTypeRange:
+			key.Range = aws.ToString(element.AttributeName)
+		}
+	}
+	return key
+}
+
+// attributeDefinitions declares the key attributes as strings, each once
+func attributeDefinitions(keys ...Key) []types.AttributeDefinition {
+	var definitions []types.AttributeDefinition
+	seen := map[string]bool{}
+	for _, key := range keys {
+		for _, name := range []string{key.Hash, key.Range} {
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			definitions = append(definitions, types.AttributeDefinition{
+				AttributeName: aws.String(name),
+				AttributeType: types.ScalarAttributeTypeS,
+			})
+		}
+	}
+	return definitions
+}
+
+func (k Key) String() string {
+	if k.Range == "" {
+		return k.Hash
+	}
+	return k.Hash + "+" + k.Range
+}
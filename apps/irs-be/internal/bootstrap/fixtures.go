@@ -0,0 +1,245 @@
+package bootstrap
+
+import (
+	"time"
+
+	"irs-be/internal/models"
+)
+
+// fixture is a ticket with its timestamps relative to the time of seeding
+type fixture struct {
+	ticket models.IncidentTicket
+	// age is how long before seeding the ticket was created
+	age time.Duration
+	// resolvedAfter is how long after creation the ticket was resolved, 0 if it is not
+	resolvedAfter time.Duration
+	// emailAfter is how long after creation the notification went out, 0 if it did not
+	emailAfter time.Duration
+}
+
+var fixtures = []fixture{
+	{
+		ticket: models.IncidentTicket{
+			ID:               "INC-SEED-0001",
+			Title:            "High CPU usage on api-gateway",
+			Description:      "Threshold Crossed: 1 out of the last 1 datapoints [97.2] was greater than the threshold (90.0).",
+			Report:           "CPU utilization on the api-gateway instance stayed above 95% for 10 minutes after a traffic spike from the mobile app release. Request latency rose to 2.4s at p99.",
+			Suggestions:      []string{"Scale the api-gateway Auto Scaling group out by two instances", "Enable request throttling for the mobile client", "Review the new release for retry storms"},
+			Severity:         models.SeverityCritical,
+			Category:         models.CategoryInfrastructure,
+			IncidentType:     models.IncidentTypeCPUHigh,
+			Environment:      models.EnvironmentProduction,
+			ActionStatus:     models.ActionStatusAuto,
+			Status:           models.StatusOpen,
+			Reporter:         "cloudwatch-alarm",
+			AffectedServices: []string{"api-gateway", "mobile-bff"},
+			Tags:             []string{"cpu", "production", "auto-detected"},
+		},
+		age:        25 * time.Minute,
+		emailAfter: time.Minute,
+	},
+	{
+		ticket: models.IncidentTicket{
+			ID:               "INC-SEED-0002",
+			Title:            "Memory pressure on payment-service pods",
+			Description:      "Threshold Crossed: 3 out of the last 3 datapoints [88.1, 91.4, 93.0] were greater than the threshold (85.0).",
+			Report:           "Memory usage of the payment-service pods grows by about 40 MB per hour until the pods are OOM killed. The growth started with the deployment of version 2.14.0.",
+			Suggestions:      []string{"Roll back payment-service to 2.13.2", "Capture a heap profile from one of the pods before it restarts"},
+			Severity:         models.SeverityHigh,
+			Category:         models.CategoryKubernetes,
+			IncidentType:     models.IncidentTypeMemHigh,
+			Environment:      models.EnvironmentProduction,
+			ActionStatus:     models.ActionStatusManual,
+			Status:           models.StatusManualInterventionRequired,
+			Reporter:         "cloudwatch-alarm",
+			AffectedServices: []string{"payment-service"},
+			Tags:             []string{"memory", "production", "kubernetes"},
+		},
+		age:        3 * time.Hour,
+		emailAfter: 2 * time.Minute,
+	},
+	{
+		ticket: models.IncidentTicket{
+			ID:               "INC-SEED-0003",
+			Title:            "order-service crashed with exit code 137",
+			Description:      "Process order-service exited unexpectedly with exit code 137.",
+			Report:           "order-service was killed by the kernel OOM killer during the nightly batch import. The service was restarted automatically and processed the remaining batch.",
+			Suggestions:      []string{"Raise the memory limit of the batch import worker", "Split the nightly import into smaller batches"},
+			Severity:         models.SeverityHigh,
+			Category:         models.CategoryInfrastructure,
+			IncidentType:     models.IncidentTypeAppCrash,
+			Environment:      models.EnvironmentProduction,
+			ActionStatus:     models.ActionStatusAuto,
+			Status:           models.StatusResolved,
+			Reporter:         "cloudwatch-alarm",
+			AffectedServices: []string{"order-service"},
+			Tags:             []string{"crash", "production", "auto-detected"},
+		},
+		age:           26 * time.Hour,
+		resolvedAfter: 6 * time.Minute,
+		emailAfter:    time.Minute,
+	},
+	{
+		ticket: models.IncidentTicket{
+			ID:               "INC-SEED-0004",
+			Title:            "Elevated 5xx errors from checkout",
+			Description:      "Error rate of checkout rose to 7.8% over 5 minutes.",
+			Report:           "checkout returned 502 responses while its database connection pool was exhausted. Long-running report queries held connections for more than 30 seconds.",
+			Suggestions:      []string{"Move reporting queries to the read replica", "Set a statement timeout for the checkout database user"},
+			Severity:         models.SeverityMedium,
+			Category:         models.CategoryInfrastructure,
+			IncidentType:     models.IncidentTypeAppError,
+			Environment:      models.EnvironmentProduction,
+			ActionStatus:     models.ActionStatusManual,
+			Status:           models.StatusInProgress,
+			Reporter:         "oncall@company.com",
+			AffectedServices: []string{"checkout", "orders-db"},
+			Tags:             []string{"errors", "database"},
+		},
+		age: 5 * time.Hour,
+	},
+	{
+		ticket: models.IncidentTicket{
+			ID:               "INC-SEED-0005",
+			Title:            "notification-worker shut down on staging",
+			Description:      "Process notification-worker stopped after receiving SIGTERM.",
+			Report:           "notification-worker did not come back after the staging hosts were patched. The systemd unit was disabled by the patch playbook.",
+			Suggestions:      []string{"Re-enable the notification-worker unit", "Add a post-patch health check to the playbook"},
+			Severity:         models.SeverityLow,
+			Category:         models.CategoryInfrastructure,
+			IncidentType:     models.IncidentTypeAppShutdown,
+			Environment:      models.EnvironmentStaging,
+			ActionStatus:     models.ActionStatusAuto,
+			Status:           models.StatusSolved,
+			Reporter:         "cloudwatch-alarm",
+			AffectedServices: []string{"notification-worker"},
+			Tags:             []string{"shutdown", "staging"},
+		},
+		age:           3 * 24 * time.Hour,
+		resolvedAfter: 45 * time.Minute,
+	},
+	{
+		ticket: models.IncidentTicket{
+			ID:               "INC-SEED-0006",
+			Title:            "Deployment pipeline failing on integration tests",
+			Description:      "The main branch pipeline failed 4 times in a row at the integration test stage.",
+			Report:           "The integration tests time out while pulling the database image because the CI runners hit the registry rate limit.",
+			Suggestions:      []string{"Mirror the database image to the internal registry", "Authenticate the CI runners against the registry"},
+			Severity:         models.SeverityMedium,
+			Category:         models.CategoryCICD,
+			IncidentType:     models.IncidentTypeOther,
+			Environment:      models.EnvironmentDevelopment,
+			ActionStatus:     models.ActionStatusManual,
+			Status:           models.StatusClosed,
+			Reporter:         "ci-bot",
+			AffectedServices: []string{"ci-runners"},
+			Tags:             []string{"ci-cd", "registry"},
+		},
+		age:           9 * 24 * time.Hour,
+		resolvedAfter: 20 * time.Hour,
+	},
+	{
+		ticket: models.IncidentTicket{
+			ID:               "INC-SEED-0007",
+			Title:            "High CPU usage on search-indexer",
+			Description:      "Threshold Crossed: 2 out of the last 2 datapoints [93.5, 96.8] were greater than the threshold (90.0).",
+			Report:           "The search-indexer reindexed the whole catalog after a mapping change. Automatic remediation restarted the indexer with a lower concurrency and the load returned to normal.",
+			Suggestions:      []string{"Schedule full reindexes outside business hours"},
+			Severity:         models.SeverityMedium,
+			Category:         models.CategoryInfrastructure,
+			IncidentType:     models.IncidentTypeCPUHigh,
+			Environment:      models.EnvironmentStaging,
+			ActionStatus:     models.ActionStatusAuto,
+			Status:           models.StatusResolved,
+			Reporter:         "cloudwatch-alarm",
+			AffectedServices: []string{"search-indexer"},
+			Tags:             []string{"cpu", "staging", "auto-detected"},
+		},
+		age:           14 * 24 * time.Hour,
+		resolvedAfter: 12 * time.Minute,
+		emailAfter:    time.Minute,
+	},
+	{
+		ticket: models.IncidentTicket{
+			ID:               "INC-SEED-0008",
+			Title:            "auth-service crash loop after config change",
+			Description:      "Pod auth-service restarted 12 times in 10 minutes.",
+			Report:           "auth-service failed to start because the new JWKS URL in its ConfigMap had a typo. Automatic remediation could not fix the configuration and the ticket was escalated.",
+			Suggestions:      []string{"Correct the JWKS URL in the auth-service ConfigMap", "Validate ConfigMaps in the deployment pipeline"},
+			Severity:         models.SeverityCritical,
+			Category:         models.CategoryKubernetes,
+			IncidentType:     models.IncidentTypeAppCrash,
+			Environment:      models.EnvironmentProduction,
+			ActionStatus:     models.ActionStatusManual,
+			Status:           models.StatusFailed,
+			Reporter:         "cloudwatch-alarm",
+			AffectedServices: []string{"auth-service", "api-gateway"},
+			Tags:             []string{"crash", "production", "kubernetes"},
+		},
+		age:        30 * 24 * time.Hour,
+		emailAfter: time.Minute,
+	},
+	{
+		ticket: models.IncidentTicket{
+			ID:               "INC-SEED-0009",
+			Title:            "Memory usage high on reporting-db",
+			Description:      "Threshold Crossed: 1 out of the last 1 datapoints [89.9] was greater than the threshold (85.0).",
+			Report:           "Freeable memory on the reporting database dropped during the month-end export. It recovered once the export finished.",
+			Severity:         models.SeverityLow,
+			Category:         models.CategoryInfrastructure,
+			IncidentType:     models.IncidentTypeMemHigh,
+			Environment:      models.EnvironmentProduction,
+			ActionStatus:     models.ActionStatusPending,
+			Status:           models.StatusPending,
+			Reporter:         "cloudwatch-alarm",
+			AffectedServices: []string{"reporting-db"},
+			Tags:             []string{"memory", "production", "database"},
+		},
+		age: 40 * time.Minute,
+	},
+	{
+		ticket: models.IncidentTicket{
+			ID:               "INC-SEED-0010",
+			Title:            "Sandbox API returning errors",
+			Description:      "Error rate of sandbox-api rose to 15% over 5 minutes.",
+			Report:           "The sandbox-api in development lost access to its queue after the IAM role was rotated. Remediation is running.",
+			Suggestions:      []string{"Attach the queue policy to the new IAM role"},
+			Severity:         models.SeverityLow,
+			Category:         models.CategoryOther,
+			IncidentType:     models.IncidentTypeAppError,
+			Environment:      models.EnvironmentDevelopment,
+			ActionStatus:     models.ActionStatusAuto,
+			Status:           models.StatusProcessing,
+			Reporter:         "cloudwatch-alarm",
+			AffectedServices: []string{"sandbox-api"},
+			Tags:             []string{"errors", "development", "iam"},
+		},
+		age: 10 * time.Minute,
+	},
+}
+
+// Fixtures returns a small set of realistic tickets for local development, dated
+// relative to now. Their IDs are fixed, so seeding again does not duplicate them.
+func Fixtures(now time.Time) []models.IncidentTicket {
+	tickets := make([]models.IncidentTicket, len(fixtures))
+	for i, f := range fixtures {
+		ticket := f.ticket
+		created := now.Add(-f.age)
+		ticket.CreatedAt = models.FormatTimestamp(created)
+		if f.resolvedAfter > 0 {
+			resolved := models.FormatTimestamp(created.Add(f.resolvedAfter))
+			ticket.ResolutionTime = &resolved
+		}
+		if f.emailAfter > 0 {
+			sent := models.FormatTimestamp(created.Add(f.emailAfter))
+			ticket.EmailSent = true
+			ticket.EmailSentAt = &sent
+		}
+		if ticket.ActionStatus == models.ActionStatusAuto && f.resolvedAfter > 0 {
+			action := "Auto resolution completed: " + ticket.Report
+			ticket.ActionTaken = &action
+		}
+		tickets[i] = ticket
+	}
+	return tickets
+}
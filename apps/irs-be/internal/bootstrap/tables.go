@@ -0,0 +1,55 @@
+package bootstrap
+
+import (
+	"irs-be/internal/config"
+	"irs-be/internal/retention"
+	"irs-be/internal/ticketcodec"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Key is the key schema of a table or index. Every key attribute irs-be uses is a
+// string.
+type Key struct {
+	Hash  string
+	Range string
+}
+
+// Index is a global secondary index projecting all attributes
+type Index struct {
+	Name string
+	Key  Key
+}
+
+// Table is a table irs-be reads from or writes to, with the indexes it queries
+type Table struct {
+	Name    string
+	Key     Key
+	Indexes []Index
+	// TTLAttribute is the epoch seconds attribute DynamoDB expires items by, if any
+	TTLAttribute string
+	// StreamViewType is the stream the table must have, if any
+	StreamViewType types.StreamViewType
+}
+
+// Tables lists the tables of the configuration
+func Tables(cfg config.Config) []Table {
+	return []Table{
+		{
+			Name: cfg.DynamoDB.TableName,
+			Key:  Key{Hash: "id"},
+			Indexes: []Index{
+				{Name: "StatusIndex", Key: Key{Hash: "status"}},
+				{Name: "SeverityIndex", Key: Key{Hash: "severity"}},
+				{Name: "IncidentTypeIndex", Key: Key{Hash: ticketcodec.LegacyIncidentTypeAttribute}},
+			},
+			// Archived tickets expire from the table once the retention TTL delay passed
+			TTLAttribute: retention.ExpiresAttribute,
+			// The change feed needs both images to tell what changed and who changed it
+			StreamViewType: types.StreamViewTypeNewAndOldImages,
+		},
+		{Name: cfg.Webhook.SubscriptionTable, Key: Key{Hash: "id"}},
+		{Name: cfg.Webhook.DeliveryTable, Key: Key{Hash: "subscriptionId", Range: "id"}},
+		{Name: cfg.Postmortem.TableName, Key: Key{Hash: "ticketId"}},
+	}
+}
@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Result tells how a Get was answered, for metrics
+type Result string
+
+const (
+	// Hit means a fresh entry was returned
+	Hit Result = "hit"
+	// Miss means the loader was called
+	Miss Result = "miss"
+	// Shared means the caller waited for a load already in flight for the same key
+	Shared Result = "shared"
+)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// TTL is an in-process cache whose entries expire after a fixed time. Concurrent
+// misses for the same key share a single load. A zero TTL disables caching, in
+// which case every Get calls the loader.
+type TTL[V any] struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+	group      singleflight.Group
+
+	mu         sync.Mutex
+	entries    map[string]entry[V]
+	generation uint64
+}
+
+// NewTTL creates a cache holding at most maxEntries entries
+func NewTTL[V any](ttl time.Duration, maxEntries int) *TTL[V] {
+	return &TTL[V]{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    map[string]entry[V]{},
+	}
+}
+
+// Enabled reports whether the cache stores anything
+func (c *TTL[V]) Enabled() bool {
+	return c.ttl > 0 && c.maxEntries > 0
+}
+
+// Get returns the cached value for key, or calls load once for all concurrent
+// callers and caches its result. Errors are not cached. The returned value is
+// shared, so callers must not modify it.
+func (c *TTL[V]) Get(key string, load func() (V, error)) (V, Result, error) {
+	if !c.Enabled() {
+		value, err := load()
+		return value, Miss, err
+	}
+
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && c.now().Before(e.expiresAt) {
+		c.mu.Unlock()
+		return e.value, Hit, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	value, err, shared := c.group.Do(key, func() (interface{}, error) {
+		value, err := load()
+		if err != nil {
+			return value, err
+		}
+
+		c.mu.Lock()
+		// A write invalidated the cache while this load was running; its result may
+		// predate the write, so hand it to the waiting callers without caching it
+		if c.generation == generation {
+			c.store(key, value)
+		}
+		c.mu.Unlock()
+		return value, nil
+	})
+
+	result := Miss
+	if shared {
+		result = Shared
+	}
+	if err != nil {
+		var zero V
+		return zero, result, err
+	}
+	return value.(V), result, nil
+}
+
+// Peek returns the fresh entry for key without loading it, along with the
+// generation to hand to Put once the caller has built a value itself
+func (c *TTL[V]) Peek(key string) (V, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok && c.Enabled() && c.now().Before(e.expiresAt) {
+		return e.value, c.generation, true
+	}
+	var zero V
+	return zero, c.generation, false
+}
+
+// Put stores a value built after a Peek, unless the cache was invalidated since;
+// like a load, a value that may predate a write is not cached
+func (c *TTL[V]) Put(key string, value V, generation uint64) {
+	if !c.Enabled() {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.store(key, value)
+	}
+}
+
+// store adds an entry, making room first when the cache is full. Callers hold mu.
+func (c *TTL[V]) store(key string, value V) {
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+	c.entries[key] = entry[V]{value: value, expiresAt: c.now().Add(c.ttl)}
+}
+
+// evict drops expired entries, or the entry closest to expiring when none are
+func (c *TTL[V]) evict() {
+	now := c.now()
+	var oldestKey string
+	var oldest time.Time
+	for key, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || e.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, e.expiresAt
+		}
+	}
+	if len(c.entries) >= c.maxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// Invalidate drops the given keys and every key starting with one of the prefixes.
+// Loads in flight when Invalidate is called do not populate the cache.
+func (c *TTL[V]) Invalidate(keys []string, prefixes []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	if len(prefixes) == 0 {
+		return
+	}
+	for key := range c.entries {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				delete(c.entries, key)
+				break
+			}
+		}
+	}
+}
+
+// Purge drops every entry
+func (c *TTL[V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = map[string]entry[V]{}
+}
+
+// Len returns the number of entries, expired ones included
+func (c *TTL[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
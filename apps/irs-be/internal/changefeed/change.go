@@ -0,0 +1,125 @@
+// Package changefeed reads the ticket table's DynamoDB Stream and hands every change
+// to in-process subscribers, so irs-be learns about the writes it did not make
+// itself, such as those of the lambdas or of other replicas.
+package changefeed
+
+import (
+	"log/slog"
+	"time"
+
+	"irs-be/internal/models"
+	"irs-be/internal/ticketcodec"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// ChangeType identifies what happened to a ticket item
+type ChangeType string
+
+const (
+	ChangeInsert ChangeType = "insert"
+	ChangeModify ChangeType = "modify"
+	ChangeRemove ChangeType = "remove"
+	// ChangeReset reports that changes were lost because the consumer fell behind
+	// the stream's 24 hour retention. Subscribers should drop whatever they derived
+	// from the table.
+	ChangeReset ChangeType = "reset"
+)
+
+// Change is a change to a ticket item read from the stream
+type Change struct {
+	Type ChangeType
+	// ID is the ticket ID, empty for resets
+	ID string
+	// Old and New are the ticket before and after the change. Old is nil for
+	// inserts and New for removals; either is nil when the stream does not carry
+	// the image or it could not be decoded.
+	Old *models.IncidentTicket
+	New *models.IncidentTicket
+	// External is set for changes irs-be did not make, such as those of the
+	// lambdas and of DynamoDB TTL; see ticketcodec.WriteIDAttribute
+	External bool
+	// Expired is set for items removed by DynamoDB TTL
+	Expired bool
+	// Shard and SequenceNumber locate the record in the stream
+	Shard          string
+	SequenceNumber string
+	// At is when the change was made, to the second
+	At time.Time
+}
+
+// Handler receives changes. Changes of a shard arrive in order, one at a time, but
+// the shards are read concurrently: a handler must be safe for concurrent use and
+// must not block. Changes to the same ticket always come from the same shard or
+// one of its descendants, which are read after it.
+type Handler func(change Change)
+
+// newChange maps a stream record to a change
+func newChange(shard string, record types.Record) Change {
+	change := Change{Shard: shard}
+	switch record.EventName {
+	case types.OperationTypeInsert:
+		change.Type = ChangeInsert
+	case types.OperationTypeModify:
+		change.Type = ChangeModify
+	case types.OperationTypeRemove:
+		change.Type = ChangeRemove
+	}
+	if identity := record.UserIdentity; identity != nil {
+		change.Expired = aws.ToString(identity.Type) == "Service" && aws.ToString(identity.PrincipalId) == "dynamodb.amazonaws.com"
+	}
+
+	data := record.Dynamodb
+	if data == nil {
+		return change
+	}
+	change.SequenceNumber = aws.ToString(data.SequenceNumber)
+	change.At = aws.ToTime(data.ApproximateCreationDateTime)
+	if id, ok := data.Keys["id"].(*types.AttributeValueMemberS); ok {
+		change.ID = id.Value
+	}
+
+	oldItem := image(change, data.OldImage)
+	newItem := image(change, data.NewImage)
+	change.Old = decode(change, oldItem)
+	change.New = decode(change, newItem)
+	change.External = writeID(newItem) == "" || writeID(newItem) == writeID(oldItem)
+	return change
+}
+
+// image converts a stream image to a table item, or nil when there is none
+func image(change Change, from map[string]types.AttributeValue) map[string]ddbtypes.AttributeValue {
+	if len(from) == 0 {
+		return nil
+	}
+	item, err := attributevalue.FromDynamoDBStreamsMap(from)
+	if err != nil {
+		slog.Warn("Failed to convert stream image", "id", change.ID, "sequenceNumber", change.SequenceNumber, "error", err)
+		return nil
+	}
+	return item
+}
+
+// decode maps an image to a ticket, or nil when there is none or it cannot be decoded
+func decode(change Change, item map[string]ddbtypes.AttributeValue) *models.IncidentTicket {
+	if item == nil {
+		return nil
+	}
+	ticket, err := ticketcodec.Decode(item)
+	if err != nil {
+		slog.Warn("Failed to decode stream image", "id", change.ID, "sequenceNumber", change.SequenceNumber, "error", err)
+		return nil
+	}
+	return &ticket
+}
+
+// writeID returns the write ID of an item, or "" when it has none
+func writeID(item map[string]ddbtypes.AttributeValue) string {
+	if v, ok := item[ticketcodec.WriteIDAttribute].(*ddbtypes.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
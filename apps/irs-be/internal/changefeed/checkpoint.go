@@ -0,0 +1,133 @@
+package changefeed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// Checkpoint is the position of the consumer in a shard
+type Checkpoint struct {
+	// SequenceNumber is the last record handed to the subscribers
+	SequenceNumber string `json:"sequenceNumber,omitempty"`
+	// Done is set once a closed shard has been read to its end
+	Done      bool   `json:"done,omitempty"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// Checkpoints stores the position of the consumer in every shard of a stream
+type Checkpoints interface {
+	// Load returns the checkpoints of a stream by shard ID, or none when the
+	// checkpoints saved belong to another stream
+	Load(streamARN string) (map[string]Checkpoint, error)
+	// Save replaces the checkpoints of the stream
+	Save(streamARN string, shards map[string]Checkpoint) error
+}
+
+// MemoryCheckpoints keeps checkpoints for the lifetime of the process
+type MemoryCheckpoints struct {
+	mu        sync.Mutex
+	streamARN string
+	shards    map[string]Checkpoint
+}
+
+// NewMemoryCheckpoints creates an empty in-memory checkpoint store
+func NewMemoryCheckpoints() *MemoryCheckpoints {
+	return &MemoryCheckpoints{}
+}
+
+// Load implements Checkpoints
+func (m *MemoryCheckpoints) Load(streamARN string) (map[string]Checkpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	shards := map[string]Checkpoint{}
+	if m.streamARN == streamARN {
+		for id, checkpoint := range m.shards {
+			shards[id] = checkpoint
+		}
+	}
+	return shards, nil
+}
+
+// Save implements Checkpoints
+func (m *MemoryCheckpoints) Save(streamARN string, shards map[string]Checkpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.streamARN = streamARN
+	m.shards = make(map[string]Checkpoint, len(shards))
+	for id, checkpoint := range shards {
+		m.shards[id] = checkpoint
+	}
+	return nil
+}
+
+// checkpointFile is the content of a checkpoint file
+type checkpointFile struct {
+	StreamARN string                `json:"streamArn"`
+	Shards    map[string]Checkpoint `json:"shards"`
+}
+
+// FileCheckpoints keeps checkpoints in a JSON file, so a restarted consumer resumes
+// where it stopped
+type FileCheckpoints struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileCheckpoints creates a checkpoint store backed by a file
+func NewFileCheckpoints(path string) *FileCheckpoints {
+	return &FileCheckpoints{path: path}
+}
+
+// Load implements Checkpoints
+func (f *FileCheckpoints) Load(streamARN string) (map[string]Checkpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]Checkpoint{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream checkpoints: %v", err)
+	}
+
+	var file checkpointFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to read stream checkpoints %s: %v", f.path, err)
+	}
+	if file.StreamARN != streamARN {
+		// The stream was replaced, e.g. by recreating the table; its positions mean nothing
+		slog.Warn("Stream checkpoints belong to another stream, starting over", "file", f.path, "stream", file.StreamARN)
+		return map[string]Checkpoint{}, nil
+	}
+	if file.Shards == nil {
+		file.Shards = map[string]Checkpoint{}
+	}
+	return file.Shards, nil
+}
+
+// Save implements Checkpoints. It writes through a temporary file so a crash never
+// leaves a partial one behind.
+func (f *FileCheckpoints) Save(streamARN string, shards map[string]Checkpoint) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := json.MarshalIndent(checkpointFile{StreamARN: streamARN, Shards: shards}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode stream checkpoints: %v", err)
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write stream checkpoints: %v", err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("failed to write stream checkpoints: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,458 @@
+package changefeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"irs-be/internal/config"
+	"irs-be/internal/metrics"
+	"irs-be/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+const (
+	// shardRefreshInterval is how often the stream is described to find the shards
+	// created by resharding
+	shardRefreshInterval = 30 * time.Second
+	// recordsLimit is the most records read per call
+	recordsLimit = 1000
+	// maxBackoff bounds the wait after a failed stream call
+	maxBackoff = 30 * time.Second
+)
+
+// Client is the subset of the DynamoDB Streams API the consumer uses
+type Client interface {
+	DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error)
+	GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error)
+	GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error)
+}
+
+// TableClient is the subset of the DynamoDB API used to find a table's stream
+type TableClient interface {
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+}
+
+// LatestStreamARN returns the ARN of the enabled stream of a table
+func LatestStreamARN(ctx context.Context, client TableClient, table string) (string, error) {
+	output, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe table %s: %v", table, err)
+	}
+	spec := output.Table.StreamSpecification
+	if arn := aws.ToString(output.Table.LatestStreamArn); arn != "" && spec != nil && aws.ToBool(spec.StreamEnabled) {
+		return arn, nil
+	}
+	return "", fmt.Errorf("table %s has no stream, run bootstrap or enable a NEW_AND_OLD_IMAGES stream", table)
+}
+
+// ParseStartPosition parses STREAM_START_POSITION: latest or trim_horizon
+func ParseStartPosition(position string) (types.ShardIteratorType, error) {
+	switch position {
+	case "latest":
+		return types.ShardIteratorTypeLatest, nil
+	case "trim_horizon":
+		return types.ShardIteratorTypeTrimHorizon, nil
+	}
+	return "", fmt.Errorf("unknown stream start position %q, use latest or trim_horizon", position)
+}
+
+// Consumer reads every shard of a stream and hands the changes to its subscribers.
+// A shard is read once its parent has been read to the end, so the changes to a
+// ticket arrive in order across resharding. The position in every shard is
+// checkpointed after each batch; delivery is at least once.
+type Consumer struct {
+	client       Client
+	streamARN    string
+	start        types.ShardIteratorType
+	pollInterval time.Duration
+	checkpoints  Checkpoints
+
+	mu        sync.Mutex
+	handlers  []Handler
+	positions map[string]Checkpoint
+	reading   map[string]bool
+	lag       map[string]time.Duration
+
+	// finished wakes the shard refresh when a shard was read to its end, so its
+	// children start without waiting for the next refresh
+	finished chan struct{}
+	cancel   context.CancelFunc
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewConsumer creates a consumer of a stream. Shards without a checkpoint are read
+// from cfg.StartPosition on the first start and from their beginning later on, so
+// the shards created while irs-be was down are read in full.
+func NewConsumer(client Client, streamARN string, checkpoints Checkpoints, cfg config.StreamConfig) (*Consumer, error) {
+	start, err := ParseStartPosition(cfg.StartPosition)
+	if err != nil {
+		return nil, err
+	}
+	pollInterval := cfg.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	return &Consumer{
+		client:       client,
+		streamARN:    streamARN,
+		start:        start,
+		pollInterval: pollInterval,
+		checkpoints:  checkpoints,
+		reading:      map[string]bool{},
+		lag:          map[string]time.Duration{},
+		finished:     make(chan struct{}, 1),
+	}, nil
+}
+
+// Subscribe registers a handler for every change. Register handlers before Start.
+func (c *Consumer) Subscribe(handler Handler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers = append(c.handlers, handler)
+}
+
+// Start reads the stream in the background until Stop
+func (c *Consumer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.run(ctx)
+	}()
+}
+
+// Stop stops reading and waits for the batches being handled
+func (c *Consumer) Stop() {
+	c.stopOnce.Do(func() {
+		if c.cancel != nil {
+			c.cancel()
+		}
+	})
+	c.wg.Wait()
+}
+
+// run refreshes the shards of the stream and starts a reader for every shard that
+// is ready, until ctx is done
+func (c *Consumer) run(ctx context.Context) {
+	positions, err := c.checkpoints.Load(c.streamARN)
+	if err != nil {
+		slog.Error("Failed to load stream checkpoints, reading from the start position", "error", err)
+		positions = map[string]Checkpoint{}
+	}
+	c.mu.Lock()
+	c.positions = positions
+	c.mu.Unlock()
+	slog.Info("Reading ticket table stream", "stream", c.streamARN, "checkpoints", len(positions))
+
+	// Without any checkpoint this is the first start, where shards begin at the
+	// start position
+	initial := len(positions) == 0
+	ticker := time.NewTicker(shardRefreshInterval)
+	defer ticker.Stop()
+	for {
+		if shards, err := c.describe(ctx); err != nil {
+			if ctx.Err() == nil {
+				slog.Error("Failed to describe ticket table stream", "stream", c.streamARN, "error", err)
+			}
+		} else {
+			c.schedule(ctx, shards, initial)
+			initial = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-c.finished:
+		}
+	}
+}
+
+// describe lists every shard of the stream
+func (c *Consumer) describe(ctx context.Context) ([]types.Shard, error) {
+	var shards []types.Shard
+	input := &dynamodbstreams.DescribeStreamInput{StreamArn: aws.String(c.streamARN)}
+	for {
+		output, err := c.client.DescribeStream(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		description := output.StreamDescription
+		if view := description.StreamViewType; view != types.StreamViewTypeNewAndOldImages && len(shards) == 0 {
+			// Without both images changes carry no tickets and no events can be derived
+			slog.Warn("Ticket table stream does not carry old and new images, only the cache is kept up to date", "viewType", view)
+		}
+		shards = append(shards, description.Shards...)
+		if description.LastEvaluatedShardId == nil {
+			return shards, nil
+		}
+		input.ExclusiveStartShardId = description.LastEvaluatedShardId
+	}
+}
+
+// schedule starts a reader for every shard that is neither done nor being read and
+// whose parent, if still in the stream, is done. Checkpoints of shards that aged
+// out of the stream are dropped.
+func (c *Consumer) schedule(ctx context.Context, shards []types.Shard, initial bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	inStream := make(map[string]bool, len(shards))
+	for _, shard := range shards {
+		inStream[aws.ToString(shard.ShardId)] = true
+	}
+
+	changed := false
+	for id := range c.positions {
+		if !inStream[id] {
+			delete(c.positions, id)
+			changed = true
+		}
+	}
+	if initial && c.start == types.ShardIteratorTypeLatest {
+		// Starting at the latest record, the history in closed shards is skipped
+		for _, shard := range shards {
+			if shard.SequenceNumberRange != nil && shard.SequenceNumberRange.EndingSequenceNumber != nil {
+				c.positions[aws.ToString(shard.ShardId)] = Checkpoint{Done: true, UpdatedAt: models.FormatTimestamp(time.Now())}
+				changed = true
+			}
+		}
+	}
+	if changed {
+		c.save()
+	}
+
+	for _, shard := range shards {
+		id := aws.ToString(shard.ShardId)
+		position, checkpointed := c.positions[id]
+		if position.Done || c.reading[id] {
+			continue
+		}
+		if parent := aws.ToString(shard.ParentShardId); parent != "" && inStream[parent] && !c.positions[parent].Done {
+			continue
+		}
+
+		iteratorType, after := types.ShardIteratorTypeTrimHorizon, ""
+		switch {
+		case checkpointed && position.SequenceNumber != "":
+			iteratorType, after = types.ShardIteratorTypeAfterSequenceNumber, position.SequenceNumber
+		case initial:
+			iteratorType = c.start
+		}
+
+		c.reading[id] = true
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.read(ctx, id, iteratorType, after)
+		}()
+	}
+	metrics.SetStreamShards(len(c.reading))
+}
+
+// read reads a shard until it is closed and read to its end, or ctx is done
+func (c *Consumer) read(ctx context.Context, shard string, iteratorType types.ShardIteratorType, after string) {
+	defer func() {
+		c.mu.Lock()
+		delete(c.reading, shard)
+		delete(c.lag, shard)
+		c.publishLag()
+		metrics.SetStreamShards(len(c.reading))
+		c.mu.Unlock()
+	}()
+
+	backoff := c.pollInterval
+	retry := func(operation string, err error) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		slog.Warn("Ticket table stream call failed, retrying", "operation", operation, "shard", shard, "backoff", backoff.String(), "error", err)
+		ok := sleep(ctx, backoff)
+		backoff = min(2*backoff, maxBackoff)
+		return ok
+	}
+
+	var iterator *string
+	for ctx.Err() == nil {
+		if iterator == nil {
+			input := &dynamodbstreams.GetShardIteratorInput{
+				StreamArn:         aws.String(c.streamARN),
+				ShardId:           aws.String(shard),
+				ShardIteratorType: iteratorType,
+			}
+			if after != "" {
+				input.SequenceNumber = aws.String(after)
+			}
+			output, err := c.client.GetShardIterator(ctx, input)
+			var trimmed *types.TrimmedDataAccessException
+			var notFound *types.ResourceNotFoundException
+			switch {
+			case errors.As(err, &trimmed):
+				c.reset(shard)
+				iteratorType, after = types.ShardIteratorTypeTrimHorizon, ""
+				continue
+			case errors.As(err, &notFound):
+				// The shard aged out of the stream
+				c.finish(shard)
+				return
+			case err != nil:
+				if !retry("GetShardIterator", err) {
+					return
+				}
+				continue
+			}
+			iterator = output.ShardIterator
+			if iterator == nil {
+				c.finish(shard)
+				return
+			}
+		}
+
+		output, err := c.client.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{
+			ShardIterator: iterator,
+			Limit:         aws.Int32(recordsLimit),
+		})
+		var expired *types.ExpiredIteratorException
+		var trimmed *types.TrimmedDataAccessException
+		var notFound *types.ResourceNotFoundException
+		switch {
+		case errors.As(err, &expired):
+			// Iterators expire after 15 minutes; get a new one at the checkpoint
+			iterator = nil
+			continue
+		case errors.As(err, &trimmed):
+			c.reset(shard)
+			iterator, iteratorType, after = nil, types.ShardIteratorTypeTrimHorizon, ""
+			continue
+		case errors.As(err, &notFound):
+			c.finish(shard)
+			return
+		case err != nil:
+			if !retry("GetRecords", err) {
+				return
+			}
+			continue
+		}
+		backoff = c.pollInterval
+
+		if len(output.Records) > 0 {
+			after = c.deliver(shard, output.Records)
+			iteratorType = types.ShardIteratorTypeAfterSequenceNumber
+		}
+		if output.NextShardIterator == nil {
+			c.finish(shard)
+			return
+		}
+		iterator = output.NextShardIterator
+		if len(output.Records) == 0 {
+			c.setLag(shard, 0)
+			if !sleep(ctx, c.pollInterval) {
+				return
+			}
+		}
+	}
+}
+
+// deliver hands a batch of records to the subscribers and checkpoints the last one,
+// whose sequence number it returns
+func (c *Consumer) deliver(shard string, records []types.Record) string {
+	c.mu.Lock()
+	handlers := make([]Handler, len(c.handlers))
+	copy(handlers, c.handlers)
+	c.mu.Unlock()
+
+	var last Change
+	for _, record := range records {
+		last = newChange(shard, record)
+		metrics.IncStreamRecord(string(last.Type))
+		for _, handler := range handlers {
+			handler(last)
+		}
+	}
+
+	c.setLag(shard, time.Since(last.At))
+	c.mu.Lock()
+	c.positions[shard] = Checkpoint{SequenceNumber: last.SequenceNumber, UpdatedAt: models.FormatTimestamp(time.Now())}
+	c.save()
+	c.mu.Unlock()
+	return last.SequenceNumber
+}
+
+// finish checkpoints a shard read to its end and wakes the shard refresh to start
+// its children
+func (c *Consumer) finish(shard string) {
+	c.mu.Lock()
+	position := c.positions[shard]
+	position.Done = true
+	position.UpdatedAt = models.FormatTimestamp(time.Now())
+	c.positions[shard] = position
+	c.save()
+	c.mu.Unlock()
+
+	select {
+	case c.finished <- struct{}{}:
+	default:
+	}
+}
+
+// reset reports that records of a shard were trimmed before they were read
+func (c *Consumer) reset(shard string) {
+	slog.Warn("Ticket table stream records were trimmed before they were read, changes were missed", "shard", shard)
+	metrics.IncStreamReset()
+
+	c.mu.Lock()
+	handlers := make([]Handler, len(c.handlers))
+	copy(handlers, c.handlers)
+	c.mu.Unlock()
+
+	change := Change{Type: ChangeReset, Shard: shard, At: time.Now()}
+	for _, handler := range handlers {
+		handler(change)
+	}
+}
+
+// save writes the checkpoints. The caller holds c.mu, so saves never overtake each other.
+func (c *Consumer) save() {
+	if err := c.checkpoints.Save(c.streamARN, c.positions); err != nil {
+		slog.Error("Failed to save stream checkpoints", "error", err)
+	}
+}
+
+// setLag records how far behind a shard's reader is
+func (c *Consumer) setLag(shard string, lag time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lag[shard] = max(lag, 0)
+	c.publishLag()
+}
+
+// publishLag publishes the lag of the reader furthest behind. The caller holds c.mu.
+func (c *Consumer) publishLag() {
+	var lag time.Duration
+	for _, shardLag := range c.lag {
+		lag = max(lag, shardLag)
+	}
+	metrics.SetStreamLag(lag)
+}
+
+// sleep waits for d and reports false when ctx is done first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
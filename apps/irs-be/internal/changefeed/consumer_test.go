@@ -0,0 +1,312 @@
+package changefeed
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"irs-be/internal/config"
+	"irs-be/internal/models"
+	"irs-be/internal/ticketcodec"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const testStreamARN = "arn:aws:dynamodb:us-east-1:000000000000:table/insident/stream/test"
+
+// recorder collects the changes handed to a subscriber
+type recorder struct {
+	mu      sync.Mutex
+	changes []Change
+}
+
+func (r *recorder) handle(change Change) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, change)
+}
+
+func (r *recorder) snapshot() []Change {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Change{}, r.changes...)
+}
+
+// waitFor polls until cond holds and fails the test after five seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// startConsumer starts a consumer of the stream delivering to a new recorder
+func startConsumer(t *testing.T, stream *FakeStream, checkpoints Checkpoints, start string) *recorder {
+	t.Helper()
+	consumer, err := NewConsumer(stream, testStreamARN, checkpoints, config.StreamConfig{
+		StartPosition: start,
+		PollInterval:  5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewConsumer: %v", err)
+	}
+	r := &recorder{}
+	consumer.Subscribe(r.handle)
+	consumer.Start()
+	t.Cleanup(consumer.Stop)
+	return r
+}
+
+// lambdaItem is a ticket item as the lambdas write it, without a write ID
+func lambdaItem(id string, status models.Status) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"id":     &types.AttributeValueMemberS{Value: id},
+		"title":  &types.AttributeValueMemberS{Value: "CPU high on " + id},
+		"status": &types.AttributeValueMemberS{Value: string(status)},
+	}
+}
+
+// irsItem is a ticket item as irs-be writes it, with a fresh write ID
+func irsItem(t *testing.T, id string, status models.Status) map[string]types.AttributeValue {
+	t.Helper()
+	item, err := ticketcodec.Encode(models.IncidentTicket{ID: id, Title: "CPU high on " + id, Status: status})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return item
+}
+
+func ids(changes []Change) []string {
+	out := make([]string, len(changes))
+	for i, change := range changes {
+		out[i] = change.ID
+	}
+	return out
+}
+
+func TestConsumerReadsEveryShard(t *testing.T) {
+	stream := NewFakeStream(testStreamARN)
+	stream.Insert(lambdaItem("INC-1", models.StatusOpen))
+	children, err := stream.Split(stream.Shards()[0])
+	if err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+	for _, id := range []string{"INC-2", "INC-3", "INC-4", "INC-5"} {
+		stream.Insert(lambdaItem(id, models.StatusOpen))
+	}
+	if _, err := stream.Split(children[0]); err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+	stream.Insert(lambdaItem("INC-6", models.StatusOpen))
+
+	r := startConsumer(t, stream, NewMemoryCheckpoints(), "trim_horizon")
+	waitFor(t, "six changes", func() bool { return len(r.snapshot()) == 6 })
+
+	seen := map[string]bool{}
+	for _, change := range r.snapshot() {
+		if change.Type != ChangeInsert || change.New == nil || change.Old != nil {
+			t.Errorf("change %s = %+v, want an insert with a new image", change.ID, change)
+		}
+		seen[change.ID] = true
+	}
+	if len(seen) != 6 {
+		t.Errorf("changes = %v, want INC-1 to INC-6 once each", ids(r.snapshot()))
+	}
+}
+
+func TestConsumerReadsParentsBeforeChildren(t *testing.T) {
+	stream := NewFakeStream(testStreamARN)
+	statuses := []models.Status{models.StatusOpen, models.StatusProcessing, models.StatusPending, models.StatusResolved}
+	tickets := []string{"INC-1", "INC-2", "INC-3"}
+
+	// Every ticket changes in the parent, then in the children and grandchildren
+	for _, id := range tickets {
+		stream.Insert(lambdaItem(id, statuses[0]))
+	}
+	shards := stream.Shards()
+	for step := 1; step < len(statuses); step++ {
+		var next []string
+		for _, shard := range shards {
+			children, err := stream.Split(shard)
+			if err != nil {
+				t.Fatalf("Split: %v", err)
+			}
+			next = append(next, children...)
+		}
+		shards = next
+		for _, id := range tickets {
+			stream.Modify(lambdaItem(id, statuses[step-1]), lambdaItem(id, statuses[step]))
+		}
+	}
+
+	r := startConsumer(t, stream, NewMemoryCheckpoints(), "trim_horizon")
+	want := len(tickets) * len(statuses)
+	waitFor(t, "every change", func() bool { return len(r.snapshot()) == want })
+
+	byTicket := map[string][]models.Status{}
+	for _, change := range r.snapshot() {
+		byTicket[change.ID] = append(byTicket[change.ID], change.New.Status)
+	}
+	for _, id := range tickets {
+		got := byTicket[id]
+		if len(got) != len(statuses) {
+			t.Fatalf("%s changes = %v, want %v", id, got, statuses)
+		}
+		for i := range statuses {
+			if got[i] != statuses[i] {
+				t.Errorf("%s changes = %v, want %v", id, got, statuses)
+				break
+			}
+		}
+	}
+}
+
+func TestConsumerResumesFromCheckpoints(t *testing.T) {
+	stream := NewFakeStream(testStreamARN)
+	checkpoints := NewMemoryCheckpoints()
+	stream.Insert(lambdaItem("INC-1", models.StatusOpen))
+	stream.Insert(lambdaItem("INC-2", models.StatusOpen))
+
+	consumer, err := NewConsumer(stream, testStreamARN, checkpoints, config.StreamConfig{
+		StartPosition: "trim_horizon",
+		PollInterval:  5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewConsumer: %v", err)
+	}
+	first := &recorder{}
+	consumer.Subscribe(first.handle)
+	consumer.Start()
+	waitFor(t, "the first two changes", func() bool { return len(first.snapshot()) == 2 })
+	consumer.Stop()
+
+	saved, err := checkpoints.Load(testStreamARN)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if position := saved[stream.Shards()[0]]; position.SequenceNumber != first.snapshot()[1].SequenceNumber {
+		t.Errorf("checkpoint = %+v, want the last change %s", position, first.snapshot()[1].SequenceNumber)
+	}
+
+	// Changes made while stopped, including in a new shard, are read after a restart
+	stream.Insert(lambdaItem("INC-3", models.StatusOpen))
+	if _, err := stream.Split(stream.Shards()[0]); err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+	stream.Insert(lambdaItem("INC-4", models.StatusOpen))
+
+	second := startConsumer(t, stream, checkpoints, "latest")
+	waitFor(t, "the later changes", func() bool { return len(second.snapshot()) == 2 })
+	time.Sleep(50 * time.Millisecond)
+	if got := ids(second.snapshot()); len(got) != 2 || got[0] != "INC-3" || got[1] != "INC-4" {
+		t.Errorf("changes after restart = %v, want [INC-3 INC-4]", got)
+	}
+}
+
+func TestConsumerStartsAtLatest(t *testing.T) {
+	stream := NewFakeStream(testStreamARN)
+	stream.Insert(lambdaItem("INC-1", models.StatusOpen))
+	if _, err := stream.Split(stream.Shards()[0]); err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+
+	r := startConsumer(t, stream, NewMemoryCheckpoints(), "latest")
+	// Let the readers take their iterators before writing
+	time.Sleep(50 * time.Millisecond)
+	stream.Insert(lambdaItem("INC-2", models.StatusOpen))
+
+	waitFor(t, "the new change", func() bool { return len(r.snapshot()) == 1 })
+	time.Sleep(50 * time.Millisecond)
+	if got := ids(r.snapshot()); len(got) != 1 || got[0] != "INC-2" {
+		t.Errorf("changes = %v, want [INC-2]", got)
+	}
+}
+
+func TestConsumerRecoversFromExpiredIterators(t *testing.T) {
+	stream := NewFakeStream(testStreamARN)
+	r := startConsumer(t, stream, NewMemoryCheckpoints(), "trim_horizon")
+
+	stream.Insert(lambdaItem("INC-1", models.StatusOpen))
+	waitFor(t, "the first change", func() bool { return len(r.snapshot()) == 1 })
+	stream.ExpireIterators()
+	stream.Insert(lambdaItem("INC-2", models.StatusOpen))
+
+	waitFor(t, "the change after expiry", func() bool { return len(r.snapshot()) == 2 })
+	time.Sleep(50 * time.Millisecond)
+	if got := ids(r.snapshot()); len(got) != 2 || got[1] != "INC-2" {
+		t.Errorf("changes = %v, want [INC-1 INC-2] without duplicates", got)
+	}
+}
+
+func TestConsumerResetsAfterTrimmedRecords(t *testing.T) {
+	stream := NewFakeStream(testStreamARN)
+	checkpoints := NewMemoryCheckpoints()
+	shard := stream.Shards()[0]
+	for _, id := range []string{"INC-1", "INC-2", "INC-3"} {
+		stream.Insert(lambdaItem(id, models.StatusOpen))
+	}
+	// The checkpoint points at INC-1, whose successor has been trimmed since
+	checkpoints.Save(testStreamARN, map[string]Checkpoint{shard: {SequenceNumber: firstSequence(t, stream, shard)}})
+	if err := stream.Trim(shard, 2); err != nil {
+		t.Fatalf("Trim: %v", err)
+	}
+
+	r := startConsumer(t, stream, checkpoints, "trim_horizon")
+	waitFor(t, "a reset and the remaining change", func() bool { return len(r.snapshot()) == 2 })
+	got := r.snapshot()
+	if got[0].Type != ChangeReset || got[1].ID != "INC-3" {
+		t.Errorf("changes = %+v, want a reset then INC-3", got)
+	}
+}
+
+func TestChangesTellExternalWrites(t *testing.T) {
+	stream := NewFakeStream(testStreamARN)
+	r := startConsumer(t, stream, NewMemoryCheckpoints(), "trim_horizon")
+
+	created := irsItem(t, "INC-1", models.StatusOpen)
+	stream.Insert(created)
+	// A lambda update keeps the write ID irs-be set
+	resolved := irsItem(t, "INC-1", models.StatusResolved)
+	resolved[ticketcodec.WriteIDAttribute] = created[ticketcodec.WriteIDAttribute]
+	stream.Modify(created, resolved)
+	// An irs-be update sets a new one
+	stream.Modify(resolved, irsItem(t, "INC-1", models.StatusClosed))
+	stream.Insert(lambdaItem("INC-2", models.StatusOpen))
+	stream.Remove(lambdaItem("INC-2", models.StatusOpen))
+
+	waitFor(t, "five changes", func() bool { return len(r.snapshot()) == 5 })
+	want := []struct {
+		changeType ChangeType
+		external   bool
+	}{
+		{ChangeInsert, false},
+		{ChangeModify, true},
+		{ChangeModify, false},
+		{ChangeInsert, true},
+		{ChangeRemove, true},
+	}
+	for i, change := range r.snapshot() {
+		if change.Type != want[i].changeType || change.External != want[i].external {
+			t.Errorf("change %d = %s external=%v, want %s external=%v", i, change.Type, change.External, want[i].changeType, want[i].external)
+		}
+	}
+	if modify := r.snapshot()[1]; modify.Old == nil || modify.Old.Status != models.StatusOpen || modify.New == nil || modify.New.Status != models.StatusResolved {
+		t.Errorf("modify = %+v, want both images", modify)
+	}
+}
+
+// firstSequence returns the sequence number of the first record of a shard
+func firstSequence(t *testing.T, stream *FakeStream, shard string) string {
+	t.Helper()
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	s := stream.shard(shard)
+	if s == nil || len(s.records) == 0 {
+		t.Fatalf("shard %s has no records", shard)
+	}
+	return *s.records[0].Dynamodb.SequenceNumber
+}
@@ -0,0 +1,361 @@
+package changefeed
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// FakeStream is an in-memory DynamoDB Stream implementing Client, for tests and for
+// running the consumer without AWS. Items are recorded with Insert, Modify and
+// Remove; Split, Trim and ExpireIterators reproduce resharding, the stream's
+// retention and iterator expiry.
+type FakeStream struct {
+	mu       sync.Mutex
+	arn      string
+	shards   []*fakeShard
+	sequence int64
+	// generation is part of every iterator; iterators of an older one are expired
+	generation int
+	// created numbers shards; trimmed shards leave the list but keep their IDs
+	created int
+	// owners holds the shard each item was last recorded in
+	owners map[string]*fakeShard
+}
+
+type fakeShard struct {
+	id     string
+	parent string
+	// up is the parent shard, kept after it leaves the stream
+	up      *fakeShard
+	records []types.Record
+	// trimmed is the number of records dropped from the start of the shard
+	trimmed int
+	closed  bool
+	start   string
+	end     string
+}
+
+// NewFakeStream creates a stream with a single open shard
+func NewFakeStream(arn string) *FakeStream {
+	f := &FakeStream{arn: arn, owners: map[string]*fakeShard{}}
+	f.addShard("")
+	return f
+}
+
+// Insert records the creation of an item
+func (f *FakeStream) Insert(item map[string]ddbtypes.AttributeValue) {
+	f.record(types.OperationTypeInsert, nil, item)
+}
+
+// Modify records a change to an item
+func (f *FakeStream) Modify(old, new map[string]ddbtypes.AttributeValue) {
+	f.record(types.OperationTypeModify, old, new)
+}
+
+// Remove records the deletion of an item
+func (f *FakeStream) Remove(old map[string]ddbtypes.AttributeValue) {
+	f.record(types.OperationTypeRemove, old, nil)
+}
+
+// Split closes an open shard and opens two children in its place, as DynamoDB does
+// when a partition splits. It returns the IDs of the children.
+func (f *FakeStream) Split(shardID string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	shard := f.shard(shardID)
+	if shard == nil || shard.closed {
+		return nil, fmt.Errorf("no open shard %s", shardID)
+	}
+	shard.closed = true
+	shard.end = f.nextSequence()
+	return []string{f.addShard(shardID).id, f.addShard(shardID).id}, nil
+}
+
+// Trim drops the oldest n records of a shard, as the stream's retention does after
+// 24 hours. A closed shard trimmed to its end leaves the stream.
+func (f *FakeStream) Trim(shardID string, n int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	shard := f.shard(shardID)
+	if shard == nil {
+		return fmt.Errorf("no shard %s", shardID)
+	}
+	shard.trimmed = min(shard.trimmed+n, len(shard.records))
+	if shard.closed && shard.trimmed == len(shard.records) {
+		for i, s := range f.shards {
+			if s == shard {
+				f.shards = append(f.shards[:i], f.shards[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// ExpireIterators expires every iterator handed out so far
+func (f *FakeStream) ExpireIterators() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.generation++
+}
+
+// Shards lists the IDs of the shards in the stream, oldest first
+func (f *FakeStream) Shards() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ids := make([]string, len(f.shards))
+	for i, shard := range f.shards {
+		ids[i] = shard.id
+	}
+	return ids
+}
+
+// DescribeStream implements Client
+func (f *FakeStream) DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if aws.ToString(params.StreamArn) != f.arn {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("stream not found")}
+	}
+	description := &types.StreamDescription{
+		StreamArn:      aws.String(f.arn),
+		StreamStatus:   types.StreamStatusEnabled,
+		StreamViewType: types.StreamViewTypeNewAndOldImages,
+	}
+	after := aws.ToString(params.ExclusiveStartShardId)
+	for _, shard := range f.shards {
+		if after != "" {
+			if shard.id == after {
+				after = ""
+			}
+			continue
+		}
+		s := types.Shard{
+			ShardId:             aws.String(shard.id),
+			SequenceNumberRange: &types.SequenceNumberRange{StartingSequenceNumber: aws.String(shard.start)},
+		}
+		if shard.parent != "" {
+			s.ParentShardId = aws.String(shard.parent)
+		}
+		if shard.closed {
+			s.SequenceNumberRange.EndingSequenceNumber = aws.String(shard.end)
+		}
+		description.Shards = append(description.Shards, s)
+	}
+	return &dynamodbstreams.DescribeStreamOutput{StreamDescription: description}, nil
+}
+
+// GetShardIterator implements Client
+func (f *FakeStream) GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	shard := f.shard(aws.ToString(params.ShardId))
+	if shard == nil {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("shard not found")}
+	}
+
+	var position int
+	switch params.ShardIteratorType {
+	case types.ShardIteratorTypeTrimHorizon:
+		position = shard.trimmed
+	case types.ShardIteratorTypeLatest:
+		position = len(shard.records)
+	case types.ShardIteratorTypeAtSequenceNumber, types.ShardIteratorTypeAfterSequenceNumber:
+		sequence := aws.ToString(params.SequenceNumber)
+		position = -1
+		for i, record := range shard.records {
+			if aws.ToString(record.Dynamodb.SequenceNumber) == sequence {
+				position = i
+				break
+			}
+		}
+		if position < 0 {
+			return nil, &types.TrimmedDataAccessException{Message: aws.String("sequence number not in shard")}
+		}
+		if params.ShardIteratorType == types.ShardIteratorTypeAfterSequenceNumber {
+			position++
+		}
+		if position < shard.trimmed {
+			return nil, &types.TrimmedDataAccessException{Message: aws.String("sequence number trimmed")}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported shard iterator type %q", params.ShardIteratorType)
+	}
+	return &dynamodbstreams.GetShardIteratorOutput{ShardIterator: aws.String(f.iterator(shard, position))}, nil
+}
+
+// GetRecords implements Client
+func (f *FakeStream) GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(aws.ToString(params.ShardIterator), "/")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed shard iterator")
+	}
+	generation, _ := strconv.Atoi(parts[0])
+	position, _ := strconv.Atoi(parts[2])
+	if generation != f.generation {
+		return nil, &types.ExpiredIteratorException{Message: aws.String("iterator expired")}
+	}
+	shard := f.shard(parts[1])
+	if shard == nil {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("shard not found")}
+	}
+	if position < shard.trimmed {
+		return nil, &types.TrimmedDataAccessException{Message: aws.String("records trimmed")}
+	}
+
+	end := len(shard.records)
+	if limit := int(aws.ToInt32(params.Limit)); limit > 0 {
+		end = min(end, position+limit)
+	}
+	output := &dynamodbstreams.GetRecordsOutput{
+		Records: append([]types.Record{}, shard.records[position:end]...),
+	}
+	if !shard.closed || end < len(shard.records) {
+		output.NextShardIterator = aws.String(f.iterator(shard, end))
+	}
+	return output, nil
+}
+
+// record appends a record to the open shard owning the item's ID
+func (f *FakeStream) record(operation types.OperationType, old, new map[string]ddbtypes.AttributeValue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item := new
+	if item == nil {
+		item = old
+	}
+	data := &types.StreamRecord{
+		ApproximateCreationDateTime: aws.Time(time.Now().Truncate(time.Second)),
+		Keys:                        map[string]types.AttributeValue{"id": streamValue(item["id"])},
+		OldImage:                    streamItem(old),
+		NewImage:                    streamItem(new),
+		SequenceNumber:              aws.String(f.nextSequence()),
+		StreamViewType:              types.StreamViewTypeNewAndOldImages,
+	}
+
+	// As in DynamoDB, an item's records stay in the shard it was last recorded in or,
+	// once that is closed, in one of its descendants
+	id := ""
+	if v, ok := item["id"].(*ddbtypes.AttributeValueMemberS); ok {
+		id = v.Value
+	}
+	var open []*fakeShard
+	for _, shard := range f.shards {
+		if !shard.closed && f.descends(shard, f.owners[id]) {
+			open = append(open, shard)
+		}
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(id))
+	shard := open[int(hash.Sum32())%len(open)]
+	f.owners[id] = shard
+	shard.records = append(shard.records, types.Record{
+		EventID:   aws.String(aws.ToString(data.SequenceNumber)),
+		EventName: operation,
+		Dynamodb:  data,
+	})
+}
+
+func (f *FakeStream) addShard(parent string) *fakeShard {
+	f.created++
+	shard := &fakeShard{
+		id:     fmt.Sprintf("shardId-%08d", f.created),
+		parent: parent,
+		up:     f.shard(parent),
+		start:  f.nextSequence(),
+	}
+	f.shards = append(f.shards, shard)
+	return shard
+}
+
+// descends reports whether a shard is ancestor or one of its descendants; every
+// shard descends from a nil ancestor
+func (f *FakeStream) descends(shard, ancestor *fakeShard) bool {
+	for ancestor != nil && shard != nil {
+		if shard == ancestor {
+			return true
+		}
+		shard = shard.up
+	}
+	return ancestor == nil
+}
+
+func (f *FakeStream) shard(id string) *fakeShard {
+	for _, shard := range f.shards {
+		if shard.id == id {
+			return shard
+		}
+	}
+	return nil
+}
+
+func (f *FakeStream) iterator(shard *fakeShard, position int) string {
+	return fmt.Sprintf("%d/%s/%d", f.generation, shard.id, position)
+}
+
+// nextSequence returns a new sequence number; they are zero-padded so they sort as strings
+func (f *FakeStream) nextSequence() string {
+	f.sequence++
+	return fmt.Sprintf("%021d", f.sequence)
+}
+
+// streamItem converts a table item to a stream image
+func streamItem(item map[string]ddbtypes.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	image := make(map[string]types.AttributeValue, len(item))
+	for name, value := range item {
+		image[name] = streamValue(value)
+	}
+	return image
+}
+
+func streamValue(value ddbtypes.AttributeValue) types.AttributeValue {
+	switch v := value.(type) {
+	case *ddbtypes.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: v.Value}
+	case *ddbtypes.AttributeValueMemberN:
+		return &types.AttributeValueMemberN{Value: v.Value}
+	case *ddbtypes.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: v.Value}
+	case *ddbtypes.AttributeValueMemberBOOL:
+		return &types.AttributeValueMemberBOOL{Value: v.Value}
+	case *ddbtypes.AttributeValueMemberNULL:
+		return &types.AttributeValueMemberNULL{Value: v.Value}
+	case *ddbtypes.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: v.Value}
+	case *ddbtypes.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: v.Value}
+	case *ddbtypes.AttributeValueMemberBS:
+		return &types.AttributeValueMemberBS{Value: v.Value}
+	case *ddbtypes.AttributeValueMemberL:
+		list := make([]types.AttributeValue, len(v.Value))
+		for i, element := range v.Value {
+			list[i] = streamValue(element)
+		}
+		return &types.AttributeValueMemberL{Value: list}
+	case *ddbtypes.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: streamItem(v.Value)}
+	}
+	return &types.AttributeValueMemberNULL{Value: true}
+}
@@ -0,0 +1,67 @@
+package conditional
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware tags successful GET responses with an ETag derived from the body and
+// answers a matching If-None-Match with 304 Not Modified and no body. Responses
+// carry Cache-Control: no-cache so browsers revalidate on every poll instead of
+// reusing a copy. Streamed bodies are left alone. A tag already set, by a Store
+// answering from memory, is kept.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		resp := c.Response()
+		if resp.StatusCode() != fiber.StatusOK || resp.IsBodyStream() {
+			return nil
+		}
+
+		tag := string(resp.Header.Peek(fiber.HeaderETag))
+		if tag == "" {
+			tag = ETag(resp.Body())
+			c.Set(fiber.HeaderETag, tag)
+		}
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+
+		if Matches(c.Get(fiber.HeaderIfNoneMatch), tag) {
+			c.Context().ResetBody()
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+		return nil
+	}
+}
+
+// ETag returns a strong entity tag for a response body. The JSON encoding of a
+// response is deterministic, so every replica computes the same tag for the same data.
+func ETag(body []byte) string {
+	h := fnv.New64a()
+	h.Write(body)
+	return fmt.Sprintf(`"%x-%x"`, len(body), h.Sum64())
+}
+
+// Matches reports whether an If-None-Match header value matches tag, using the weak
+// comparison that RFC 9110 prescribes for If-None-Match
+func Matches(ifNoneMatch, tag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package conditional
+
+import (
+	"time"
+
+	"irs-be/internal/cache"
+	"irs-be/internal/metrics"
+	"irs-be/internal/resilience"
+	"irs-be/internal/tenant"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// storeCacheName labels the response store in metrics
+const storeCacheName = "responses"
+
+// Response is an encoded response body with its ETag and the headers to replay
+type Response struct {
+	Body   []byte
+	ETag   string
+	Header map[string]string
+}
+
+// Store keeps encoded responses in process for the same TTL as the ticket cache,
+// so a poll for unchanged data skips both the read and the JSON encoding. Tags are
+// per process: replicas encode the same data to the same body, so a tag from one
+// replica still matches on another once it has stored the response too. Purge the
+// store whenever the data behind the responses changes.
+type Store struct {
+	responses *cache.TTL[Response]
+}
+
+// NewStore creates a store holding at most maxEntries responses. A zero TTL
+// disables it.
+func NewStore(ttl time.Duration, maxEntries int) *Store {
+	return &Store{responses: cache.NewTTL[Response](ttl, maxEntries)}
+}
+
+// Purge drops every stored response
+func (s *Store) Purge() {
+	s.responses.Purge()
+	metrics.IncCacheInvalidation(storeCacheName)
+}
+
+// Cache answers GET requests from the store, by URL and tenant scope, without
+// calling the handler. Otherwise it stores the handler's successful response, with
+// its ETag and the named headers, unless it was served stale. Use it only on routes
+// whose responses are built from data that purges the store when it changes.
+func (s *Store) Cache(headers ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet || !s.responses.Enabled() {
+			return c.Next()
+		}
+
+		key := c.OriginalURL() + "@" + tenant.FromContext(c.UserContext()).Key()
+		stored, generation, ok := s.responses.Peek(key)
+		if ok {
+			metrics.IncCacheRequest(storeCacheName, string(cache.Hit))
+			for name, value := range stored.Header {
+				c.Set(name, value)
+			}
+			c.Set(fiber.HeaderETag, stored.ETag)
+			return c.Status(fiber.StatusOK).Send(stored.Body)
+		}
+		metrics.IncCacheRequest(storeCacheName, string(cache.Miss))
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		resp := c.Response()
+		if resp.StatusCode() != fiber.StatusOK || resp.IsBodyStream() || resilience.IsStale(c.UserContext()) {
+			return nil
+		}
+
+		stored = Response{
+			Body:   append([]byte(nil), resp.Body()...),
+			ETag:   ETag(resp.Body()),
+			Header: map[string]string{fiber.HeaderContentType: string(resp.Header.ContentType())},
+		}
+		for _, name := range headers {
+			if value := resp.Header.Peek(name); len(value) > 0 {
+				stored.Header[name] = string(value)
+			}
+		}
+		c.Set(fiber.HeaderETag, stored.ETag)
+		s.responses.Put(key, stored, generation)
+		return nil
+	}
+}
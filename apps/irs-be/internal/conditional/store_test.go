@@ -0,0 +1,131 @@
+package conditional
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"irs-be/internal/resilience"
+	"irs-be/internal/tenant"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const planHeader = "X-IRS-Query-Plan"
+
+// storeApp serves /tickets through a store; the handler answers with body and
+// counts its calls. A team query parameter scopes the request to that team.
+func storeApp(store *Store, body *string, status *int, stale *bool) (*fiber.App, *int) {
+	calls := 0
+	app := fiber.New()
+	app.Use(resilience.Middleware())
+	app.Use(func(c *fiber.Ctx) error {
+		if team := c.Query("team"); team != "" {
+			c.SetUserContext(tenant.WithScope(c.UserContext(), tenant.Team(team)))
+		}
+		return c.Next()
+	})
+	app.Use(Middleware())
+	app.Get("/tickets", store.Cache(planHeader), func(c *fiber.Ctx) error {
+		calls++
+		if *stale {
+			resilience.MarkStale(c.UserContext(), time.Minute)
+		}
+		c.Set(planHeader, "scan")
+		return c.Status(*status).JSON(fiber.Map{"data": *body})
+	})
+	return app, &calls
+}
+
+func get(t *testing.T, app *fiber.App, url, ifNoneMatch string) (int, string, string, string) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, url, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderETag), resp.Header.Get(planHeader), string(body)
+}
+
+func TestStoreAnswersUnchangedPollsWithoutTheHandler(t *testing.T) {
+	store := NewStore(time.Minute, 10)
+	body, status, stale := "v1", fiber.StatusOK, false
+	app, calls := storeApp(store, &body, &status, &stale)
+
+	code, tag, plan, first := get(t, app, "/tickets?q=open", "")
+	if code != fiber.StatusOK || tag == "" || plan != "scan" {
+		t.Fatalf("first GET = %d tag=%q plan=%q, want 200 with a tag and the plan", code, tag, plan)
+	}
+
+	code, _, _, got := get(t, app, "/tickets?q=open", tag)
+	if code != fiber.StatusNotModified || got != "" {
+		t.Errorf("revalidation = %d %q, want 304 without a body", code, got)
+	}
+	code, again, plan, got := get(t, app, "/tickets?q=open", "")
+	if code != fiber.StatusOK || again != tag || plan != "scan" || got != first {
+		t.Errorf("stored GET = %d tag=%q plan=%q body=%q, want the first response replayed", code, again, plan, got)
+	}
+	if *calls != 1 {
+		t.Fatalf("handler calls = %d, want 1 while stored", *calls)
+	}
+
+	// Another query, or another team, is a different response
+	get(t, app, "/tickets?q=closed", "")
+	get(t, app, "/tickets?q=open&team=payments", "")
+	if *calls != 3 {
+		t.Errorf("handler calls = %d, want other queries and teams answered by the handler", *calls)
+	}
+
+	// Once purged the handler runs again; unchanged data keeps its tag
+	store.Purge()
+	if code, _, _, _ := get(t, app, "/tickets?q=open", tag); code != fiber.StatusNotModified || *calls != 4 {
+		t.Errorf("after purge = %d with %d calls, want 304 from a fresh read", code, *calls)
+	}
+	body = "v2"
+	store.Purge()
+	if code, changed, _, _ := get(t, app, "/tickets?q=open", tag); code != fiber.StatusOK || changed == tag {
+		t.Errorf("changed data = %d tag=%q, want 200 with a new tag", code, changed)
+	}
+}
+
+func TestStoreSkipsFailedAndStaleResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		stale  bool
+	}{
+		{"failed", fiber.StatusInternalServerError, false},
+		{"stale", fiber.StatusOK, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, status, stale := "v1", tt.status, tt.stale
+			app, calls := storeApp(NewStore(time.Minute, 10), &body, &status, &stale)
+
+			get(t, app, "/tickets", "")
+			get(t, app, "/tickets", "")
+			if *calls != 2 {
+				t.Errorf("handler calls = %d, want the response not stored", *calls)
+			}
+		})
+	}
+}
+
+func TestStoreDisabledWithoutTTL(t *testing.T) {
+	body, status, stale := "v1", fiber.StatusOK, false
+	app, calls := storeApp(NewStore(0, 10), &body, &status, &stale)
+
+	_, tag, _, _ := get(t, app, "/tickets", "")
+	if code, _, _, _ := get(t, app, "/tickets", tag); code != fiber.StatusNotModified {
+		t.Errorf("revalidation = %d, want 304 from the body hash", code)
+	}
+	if *calls != 2 {
+		t.Errorf("handler calls = %d, want every request handled", *calls)
+	}
+}
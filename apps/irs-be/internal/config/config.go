@@ -1,33 +1,195 @@
 package config
 
 import (
-	"fmt"
+	"log/slog"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type AWSConfig struct {
 	Region          string
-	AccessKeyID     string
-	SecretAccessKey string
-	SessionToken    string
+	AccessKeyID     Secret
+	SecretAccessKey Secret
+	SessionToken    Secret
 }
 
 type DynamoDBConfig struct {
 	TableName string
+	// Endpoint overrides the DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB Local
+	Endpoint string
+}
+
+type WebhookConfig struct {
+	SubscriptionTable string
+	DeliveryTable     string
+	Workers           int
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	RequestTimeout    time.Duration
+}
+
+type SlackConfig struct {
+	SigningSecret Secret
+	WebhookURL    Secret
+	DashboardURL  string
+}
+
+type OllamaConfig struct {
+	Endpoint      string
+	Model         string
+	Timeout       time.Duration
+	MaxRetries    int
+	PromptVersion string
+}
+
+type PostmortemConfig struct {
+	TableName   string
+	TemplateDir string
+}
+
+type AdminConfig struct {
+	APIToken            Secret
+	BatchMaxAttempts    int
+	BatchInitialBackoff time.Duration
+	BatchMaxBackoff     time.Duration
+}
+
+type MetricsConfig struct {
+	RefreshInterval time.Duration
+	// SLA is the time to resolve a ticket, by severity
+	SLA map[string]time.Duration
+}
+
+type TracingConfig struct {
+	// Endpoint is the full OTLP/HTTP traces URL; tracing is disabled when empty
+	Endpoint    string
+	ServiceName string
+	SampleRatio float64
+}
+
+type ResilienceConfig struct {
+	MaxAttempts      int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	// StaleMaxAge is how old data served while the breaker is open may be; 0 disables stale reads
+	StaleMaxAge time.Duration
+}
+
+type ScanConfig struct {
+	// TotalSegments splits full-table scans into this many parallel segments
+	TotalSegments int
+	// Workers is how many segments are scanned at once; defaults to TotalSegments
+	Workers int
+	// MaxReadCapacity caps the read capacity units per second all scans consume together; 0 is unlimited
+	MaxReadCapacity float64
+}
+
+type CacheConfig struct {
+	// TTL is how long a ticket read is served from memory; 0 disables the cache
+	TTL        time.Duration
+	MaxEntries int
+}
+
+type RetentionConfig struct {
+	// Rules is a comma-separated list of status:age pairs, e.g. "closed:180d,deleted:30d";
+	// no tickets are archived when empty
+	Rules string
+	// ArchiveURL is file:///path or s3://bucket/prefix
+	ArchiveURL string
+	// S3Endpoint overrides the S3 endpoint, for S3-compatible stores
+	S3Endpoint string
+	// TTLDelay is how long archived items stay in the table before DynamoDB TTL removes them
+	TTLDelay time.Duration
+	// BatchSize is the number of tickets per archive object
+	BatchSize int
+	// Interval is how often the rules are applied in the background; 0 leaves it to the admin API and CLI
+	Interval time.Duration
+}
+
+type StreamConfig struct {
+	// Enabled reads the ticket table's DynamoDB Stream, so changes made outside this
+	// process, such as by the lambdas or other replicas, reach the cache and webhooks
+	Enabled bool
+	// ARN is the stream to read; empty uses the table's latest stream
+	ARN string
+	// StartPosition is where shards without a checkpoint are read from on the first
+	// start: latest or trim_horizon
+	StartPosition string
+	// CheckpointFile keeps the position in every shard across restarts; empty keeps it in memory
+	CheckpointFile string
+	// PollInterval is how long a caught-up shard waits before reading again
+	PollInterval time.Duration
+	// PublishEvents publishes lifecycle events for changes made outside irs-be.
+	// Every replica that sets it publishes them, so set it on one replica only.
+	PublishEvents bool
+}
+
+type AuthConfig struct {
+	// JWTSecret verifies HS256 bearer tokens; without it requests act as members of the default team
+	JWTSecret Secret
+	Issuer    string
+	Audience  string
+	// TeamClaim is the token claim naming the principal's team
+	TeamClaim string
+	// PlatformAdminRole is the role, in the roles claim, that grants the cross-team view
+	PlatformAdminRole string
+}
+
+type TenancyConfig struct {
+	// DefaultTeam owns the tickets written without a team, such as those of the lambdas
+	DefaultTeam string
+	// File is a JSON file with per-team SLAs and notification settings
+	File string
+}
+
+type LogConfig struct {
+	Level string
 }
 
 type ServerConfig struct {
 	Host       string
 	Port       string
 	CORSOrigin string
+	// RequestTimeout bounds every request unless a route overrides it
+	RequestTimeout time.Duration
+	// ReportTimeout bounds report regeneration, which waits for the LLM
+	ReportTimeout time.Duration
+	// StreamTimeout bounds exports, backups and restores
+	StreamTimeout time.Duration
+	// ShutdownTimeout is how long in-flight requests may drain after SIGTERM
+	ShutdownTimeout time.Duration
+	// LegacyFieldNames keeps writing renamed JSON fields, such as insident_type,
+	// under their old names as well
+	LegacyFieldNames bool
 }
 
 type Config struct {
-	AWS      AWSConfig
-	DynamoDB DynamoDBConfig
-	Server   ServerConfig
+	AWS        AWSConfig
+	DynamoDB   DynamoDBConfig
+	Webhook    WebhookConfig
+	Slack      SlackConfig
+	Ollama     OllamaConfig
+	Postmortem PostmortemConfig
+	Admin      AdminConfig
+	Metrics    MetricsConfig
+	Tracing    TracingConfig
+	Resilience ResilienceConfig
+	Scan       ScanConfig
+	Cache      CacheConfig
+	Retention  RetentionConfig
+	Stream     StreamConfig
+	Auth       AuthConfig
+	Tenancy    TenancyConfig
+	Log        LogConfig
+	Server     ServerConfig
+
+	envFileLoaded bool
 }
 
 func getEnv(key, fallback string) string {
@@ -37,50 +199,264 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
-func LoadConfig() Config {
-	// Load .env file if present
-	if err := godotenv.Load(); err != nil {
-		fmt.Printf("No .env file found, using environment variables\n")
+func getEnvInt(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		slog.Warn("Invalid integer in environment, using default", "key", key, "value", value, "default", fallback)
 	}
+	return fallback
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+		slog.Warn("Invalid number in environment, using default", "key", key, "value", value, "default", fallback)
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+		slog.Warn("Invalid boolean in environment, using default", "key", key, "value", value, "default", fallback)
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+		slog.Warn("Invalid duration in environment, using default", "key", key, "value", value, "default", fallback.String())
+	}
+	return fallback
+}
+
+// getEnvPositiveDuration is getEnvDuration for durations that drive a ticker or
+// a timeout, where zero or a negative value would panic or expire at once
+func getEnvPositiveDuration(key string, fallback time.Duration) time.Duration {
+	parsed := getEnvDuration(key, fallback)
+	if parsed <= 0 {
+		slog.Warn("Non-positive duration in environment, using default", "key", key, "value", parsed.String(), "default", fallback.String())
+		return fallback
+	}
+	return parsed
+}
+
+func LoadConfig() Config {
+	// Load .env file if present, otherwise use environment variables
+	envFileLoaded := godotenv.Load() == nil
 
 	cfg := Config{
+		envFileLoaded: envFileLoaded,
 		AWS: AWSConfig{
 			Region:          getEnv("AWS_REGION", "us-east-1"),
-			AccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
-			SecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
-			SessionToken:    getEnv("AWS_SESSION_TOKEN", ""),
+			AccessKeyID:     Secret(getEnv("AWS_ACCESS_KEY_ID", "")),
+			SecretAccessKey: Secret(getEnv("AWS_SECRET_ACCESS_KEY", "")),
+			SessionToken:    Secret(getEnv("AWS_SESSION_TOKEN", "")),
 		},
 		DynamoDB: DynamoDBConfig{
 			TableName: getEnv("DYNAMODB_TABLE_NAME", "insident"),
+			Endpoint:  getEnv("DYNAMODB_ENDPOINT", ""),
+		},
+		Webhook: WebhookConfig{
+			SubscriptionTable: getEnv("WEBHOOK_SUBSCRIPTION_TABLE_NAME", "insident-webhooks"),
+			DeliveryTable:     getEnv("WEBHOOK_DELIVERY_TABLE_NAME", "insident-webhook-deliveries"),
+			Workers:           getEnvInt("WEBHOOK_WORKERS", 4),
+			MaxAttempts:       getEnvInt("WEBHOOK_MAX_ATTEMPTS", 6),
+			InitialBackoff:    getEnvDuration("WEBHOOK_INITIAL_BACKOFF", 2*time.Second),
+			MaxBackoff:        getEnvDuration("WEBHOOK_MAX_BACKOFF", 5*time.Minute),
+			RequestTimeout:    getEnvDuration("WEBHOOK_REQUEST_TIMEOUT", 10*time.Second),
+		},
+		Slack: SlackConfig{
+			SigningSecret: Secret(getEnv("SLACK_SIGNING_SECRET", "")),
+			WebhookURL:    Secret(getEnv("SLACK_WEBHOOK_URL", "")),
+			DashboardURL:  getEnv("DASHBOARD_URL", ""),
+		},
+		Ollama: OllamaConfig{
+			Endpoint:      getEnv("OLLAMA_ENDPOINT", ""),
+			Model:         getEnv("OLLAMA_MODEL", "phi4-mini"),
+			Timeout:       getEnvDuration("OLLAMA_TIMEOUT", 120*time.Second),
+			MaxRetries:    getEnvInt("OLLAMA_MAX_RETRIES", 2),
+			PromptVersion: getEnv("REPORT_PROMPT_VERSION", "v1"),
+		},
+		Postmortem: PostmortemConfig{
+			TableName:   getEnv("POSTMORTEM_TABLE_NAME", "insident-postmortems"),
+			TemplateDir: getEnv("POSTMORTEM_TEMPLATE_DIR", ""),
+		},
+		Admin: AdminConfig{
+			APIToken:            Secret(getEnv("ADMIN_API_TOKEN", "")),
+			BatchMaxAttempts:    getEnvInt("ADMIN_BATCH_MAX_ATTEMPTS", 8),
+			BatchInitialBackoff: getEnvDuration("ADMIN_BATCH_INITIAL_BACKOFF", 100*time.Millisecond),
+			BatchMaxBackoff:     getEnvDuration("ADMIN_BATCH_MAX_BACKOFF", 10*time.Second),
+		},
+		Metrics: MetricsConfig{
+			RefreshInterval: getEnvPositiveDuration("METRICS_REFRESH_INTERVAL", time.Minute),
+			SLA: map[string]time.Duration{
+				"critical": getEnvDuration("SLA_CRITICAL", 4*time.Hour),
+				"high":     getEnvDuration("SLA_HIGH", 24*time.Hour),
+				"medium":   getEnvDuration("SLA_MEDIUM", 72*time.Hour),
+				"low":      getEnvDuration("SLA_LOW", 7*24*time.Hour),
+			},
+		},
+		Tracing: TracingConfig{
+			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", ""),
+			ServiceName: getEnv("OTEL_SERVICE_NAME", "irs-be"),
+			SampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+		},
+		Resilience: ResilienceConfig{
+			MaxAttempts:      getEnvInt("DYNAMODB_RETRY_MAX_ATTEMPTS", 3),
+			BaseDelay:        getEnvDuration("DYNAMODB_RETRY_BASE_DELAY", 50*time.Millisecond),
+			MaxDelay:         getEnvDuration("DYNAMODB_RETRY_MAX_DELAY", time.Second),
+			FailureThreshold: getEnvInt("BREAKER_FAILURE_THRESHOLD", 5),
+			OpenTimeout:      getEnvDuration("BREAKER_OPEN_TIMEOUT", 30*time.Second),
+			StaleMaxAge:      getEnvDuration("STALE_READ_MAX_AGE", 10*time.Minute),
+		},
+		Scan: ScanConfig{
+			TotalSegments:   getEnvInt("SCAN_TOTAL_SEGMENTS", 4),
+			Workers:         getEnvInt("SCAN_WORKERS", 0),
+			MaxReadCapacity: getEnvFloat("SCAN_MAX_READ_CAPACITY", 0),
+		},
+		Cache: CacheConfig{
+			TTL:        getEnvDuration("TICKET_CACHE_TTL", 5*time.Second),
+			MaxEntries: getEnvInt("TICKET_CACHE_MAX_ENTRIES", 1000),
+		},
+		Retention: RetentionConfig{
+			Rules:      getEnv("RETENTION_RULES", ""),
+			ArchiveURL: getEnv("ARCHIVE_URL", ""),
+			S3Endpoint: getEnv("ARCHIVE_S3_ENDPOINT", ""),
+			TTLDelay:   getEnvDuration("RETENTION_TTL_DELAY", 7*24*time.Hour),
+			BatchSize:  getEnvInt("ARCHIVE_BATCH_SIZE", 500),
+			Interval:   getEnvDuration("RETENTION_INTERVAL", 0),
+		},
+		Stream: StreamConfig{
+			Enabled:        getEnvBool("STREAM_ENABLED", false),
+			ARN:            getEnv("STREAM_ARN", ""),
+			StartPosition:  getEnv("STREAM_START_POSITION", "latest"),
+			CheckpointFile: getEnv("STREAM_CHECKPOINT_FILE", ""),
+			PollInterval:   getEnvDuration("STREAM_POLL_INTERVAL", time.Second),
+			PublishEvents:  getEnvBool("STREAM_PUBLISH_EVENTS", false),
+		},
+		Auth: AuthConfig{
+			JWTSecret:         Secret(getEnv("AUTH_JWT_SECRET", "")),
+			Issuer:            getEnv("AUTH_ISSUER", ""),
+			Audience:          getEnv("AUTH_AUDIENCE", ""),
+			TeamClaim:         getEnv("AUTH_TEAM_CLAIM", "team"),
+			PlatformAdminRole: getEnv("AUTH_PLATFORM_ADMIN_ROLE", "platform-admin"),
+		},
+		Tenancy: TenancyConfig{
+			DefaultTeam: getEnv("DEFAULT_TEAM", "default"),
+			File:        getEnv("TENANTS_FILE", ""),
+		},
+		Log: LogConfig{
+			Level: getEnv("LOG_LEVEL", "info"),
 		},
 		Server: ServerConfig{
 			Host:       getEnv("HOST", "0.0.0.0"),
 			Port:       getEnv("PORT", "8080"),
 			CORSOrigin: getEnv("CORS_ORIGIN", "*"),
+
+			RequestTimeout:  getEnvDuration("REQUEST_TIMEOUT", 10*time.Second),
+			ReportTimeout:   getEnvDuration("REPORT_REQUEST_TIMEOUT", 5*time.Minute),
+			StreamTimeout:   getEnvDuration("STREAM_TIMEOUT", 10*time.Minute),
+			ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 25*time.Second),
+
+			LegacyFieldNames: getEnvBool("API_LEGACY_FIELD_NAMES", true),
 		},
 	}
 
-	// Log configuration (without sensitive data)
-	fmt.Printf("Configuration loaded:\n")
-	fmt.Printf("  AWS Region: %s\n", cfg.AWS.Region)
-	fmt.Printf("  AWS Access Key ID: %s\n", maskString(cfg.AWS.AccessKeyID))
-	fmt.Printf("  AWS Secret Access Key: %s\n", maskString(cfg.AWS.SecretAccessKey))
-	fmt.Printf("  AWS Session Token: %s\n", maskString(cfg.AWS.SessionToken))
-	fmt.Printf("  DynamoDB Table: %s\n", cfg.DynamoDB.TableName)
-	fmt.Printf("  Server Host: %s\n", cfg.Server.Host)
-	fmt.Printf("  Server Port: %s\n", cfg.Server.Port)
-	fmt.Printf("  CORS Origin: %s\n", cfg.Server.CORSOrigin)
-
 	return cfg
 }
 
-// maskString masks sensitive strings for logging
-func maskString(s string) string {
-	if s == "" {
-		return "<not set>"
-	}
-	if len(s) <= 4 {
-		return "****"
-	}
-	return s[:4] + "****"
+// LogValue lists the configuration for the startup log. Secret fields are of type
+// Secret and log themselves redacted.
+func (c Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Bool("envFileLoaded", c.envFileLoaded),
+		slog.Group("aws",
+			"region", c.AWS.Region,
+			"accessKeyId", c.AWS.AccessKeyID,
+			"secretAccessKey", c.AWS.SecretAccessKey,
+			"sessionToken", c.AWS.SessionToken,
+		),
+		slog.Group("dynamodb", "table", c.DynamoDB.TableName, "endpoint", c.DynamoDB.Endpoint),
+		slog.Group("webhook",
+			"subscriptionTable", c.Webhook.SubscriptionTable,
+			"deliveryTable", c.Webhook.DeliveryTable,
+		),
+		slog.Group("slack",
+			"signingSecret", c.Slack.SigningSecret,
+			"webhookUrl", c.Slack.WebhookURL,
+		),
+		slog.Group("ollama",
+			"endpoint", c.Ollama.Endpoint,
+			"model", c.Ollama.Model,
+			"promptVersion", c.Ollama.PromptVersion,
+		),
+		slog.Group("postmortem", "table", c.Postmortem.TableName),
+		slog.Group("admin", "apiToken", c.Admin.APIToken),
+		slog.Group("sla",
+			"critical", c.Metrics.SLA["critical"].String(),
+			"high", c.Metrics.SLA["high"].String(),
+			"medium", c.Metrics.SLA["medium"].String(),
+			"low", c.Metrics.SLA["low"].String(),
+		),
+		slog.Group("tracing", "endpoint", c.Tracing.Endpoint),
+		slog.Group("resilience",
+			"maxAttempts", c.Resilience.MaxAttempts,
+			"failureThreshold", c.Resilience.FailureThreshold,
+			"openTimeout", c.Resilience.OpenTimeout.String(),
+			"staleMaxAge", c.Resilience.StaleMaxAge.String(),
+		),
+		slog.Group("scan",
+			"totalSegments", c.Scan.TotalSegments,
+			"workers", c.Scan.Workers,
+			"maxReadCapacity", c.Scan.MaxReadCapacity,
+		),
+		slog.Group("cache",
+			"ttl", c.Cache.TTL.String(),
+			"maxEntries", c.Cache.MaxEntries,
+		),
+		slog.Group("retention",
+			"rules", c.Retention.Rules,
+			"archiveUrl", c.Retention.ArchiveURL,
+			"s3Endpoint", c.Retention.S3Endpoint,
+			"ttlDelay", c.Retention.TTLDelay.String(),
+			"interval", c.Retention.Interval.String(),
+		),
+		slog.Group("stream",
+			"enabled", c.Stream.Enabled,
+			"arn", c.Stream.ARN,
+			"startPosition", c.Stream.StartPosition,
+			"checkpointFile", c.Stream.CheckpointFile,
+			"pollInterval", c.Stream.PollInterval.String(),
+			"publishEvents", c.Stream.PublishEvents,
+		),
+		slog.Group("auth",
+			"jwtSecret", c.Auth.JWTSecret,
+			"issuer", c.Auth.Issuer,
+			"audience", c.Auth.Audience,
+			"teamClaim", c.Auth.TeamClaim,
+			"platformAdminRole", c.Auth.PlatformAdminRole,
+		),
+		slog.Group("tenancy", "defaultTeam", c.Tenancy.DefaultTeam, "file", c.Tenancy.File),
+		slog.Group("log", "level", c.Log.Level),
+		slog.Group("server",
+			"host", c.Server.Host,
+			"port", c.Server.Port,
+			"corsOrigin", c.Server.CORSOrigin,
+			"requestTimeout", c.Server.RequestTimeout.String(),
+			"reportTimeout", c.Server.ReportTimeout.String(),
+			"streamTimeout", c.Server.StreamTimeout.String(),
+			"shutdownTimeout", c.Server.ShutdownTimeout.String(),
+			"legacyFieldNames", c.Server.LegacyFieldNames,
+		),
+	)
 }
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"irs-be/internal/logging"
+)
+
+// Secret is a configuration value that must never appear in logs or API output.
+// Printing, logging or JSON-encoding it yields the redacted form; Reveal returns
+// the actual value for the code that needs it.
+type Secret string
+
+// Reveal returns the secret value
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// IsSet reports whether the secret has a value
+func (s Secret) IsSet() bool {
+	return s != ""
+}
+
+func (s Secret) String() string {
+	return logging.Redact(string(s))
+}
+
+// LogValue implements slog.LogValuer
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// MarshalJSON implements json.Marshaler
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
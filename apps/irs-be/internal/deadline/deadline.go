@@ -0,0 +1,66 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware gives every request a context that expires after d, so a hung
+// DynamoDB or HTTP call cannot hold a request forever. Handlers and services pick
+// it up through c.UserContext(). A zero duration disables the deadline.
+func Middleware(d time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if d <= 0 {
+			return c.Next()
+		}
+		ctx, cancel := context.WithTimeout(c.UserContext(), d)
+		defer cancel()
+		return run(c, ctx)
+	}
+}
+
+// Override replaces the request deadline for a single route, for handlers that
+// legitimately take longer than the default, such as LLM calls. Request-scoped
+// values like the trace span and request ID are kept.
+func Override(d time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := detached(c, d)
+		defer cancel()
+		return run(c, ctx)
+	}
+}
+
+// Stream returns a context for handlers that write the response body after they
+// return, which the request deadline would otherwise cut off. The caller must
+// cancel it once the body is written.
+func Stream(c *fiber.Ctx, d time.Duration) (context.Context, context.CancelFunc) {
+	return detached(c, d)
+}
+
+func detached(c *fiber.Ctx, d time.Duration) (context.Context, context.CancelFunc) {
+	ctx := context.WithoutCancel(c.UserContext())
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// run calls the next handler with ctx and reports server errors caused by the
+// deadline as 504 Gateway Timeout
+func run(c *fiber.Ctx, ctx context.Context) error {
+	c.SetUserContext(ctx)
+	err := c.Next()
+
+	if Exceeded(ctx) && c.Response().StatusCode() >= fiber.StatusInternalServerError {
+		c.Status(fiber.StatusGatewayTimeout)
+	}
+	return err
+}
+
+// Exceeded reports whether the context ended because its deadline passed
+func Exceeded(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
@@ -0,0 +1,32 @@
+package dto
+
+type UpsertPostmortemRequest struct {
+	Summary        string `json:"summary"`
+	RootCause      string `json:"rootCause"`
+	LessonsLearned string `json:"lessonsLearned"`
+	Author         string `json:"author"`
+}
+
+type CreateActionItemRequest struct {
+	Description string `json:"description" validate:"required"`
+	Owner       string `json:"owner" validate:"required"`
+	DueDate     string `json:"dueDate"`
+}
+
+type UpdateActionItemRequest struct {
+	Description *string `json:"description,omitempty"`
+	Owner       *string `json:"owner,omitempty"`
+	DueDate     *string `json:"dueDate,omitempty"`
+	Status      *string `json:"status,omitempty"`
+}
+
+// ActionItemResponse is an action item together with the postmortem it belongs to
+type ActionItemResponse struct {
+	TicketID    string `json:"ticketId"`
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Owner       string `json:"owner"`
+	DueDate     string `json:"dueDate,omitempty"`
+	Status      string `json:"status"`
+	Overdue     bool   `json:"overdue"`
+}
@@ -1,15 +1,22 @@
 package dto
 
+import "irs-be/internal/models"
+
 type CreateTicketRequest struct {
-	Title            string   `json:"title" validate:"required"`
-	Description      string   `json:"description" validate:"required"`
-	Severity         string   `json:"severity" validate:"required"`
-	Category         string   `json:"category" validate:"required"`
-	IncidentType     string   `json:"insident_type" validate:"required"`
-	Environment      string   `json:"environment" validate:"required"`
-	ActionStatus     string   `json:"actionStatus"`
-	Status           string   `json:"status"`
-	Reporter         string   `json:"reporter"`
+	Title        string              `json:"title" validate:"required"`
+	Description  string              `json:"description" validate:"required"`
+	Severity     models.Severity     `json:"severity" validate:"required"`
+	Category     models.Category     `json:"category" validate:"required"`
+	IncidentType models.IncidentType `json:"incident_type" validate:"required"`
+	// LegacyIncidentType is accepted from clients that still send the old name
+	LegacyIncidentType models.IncidentType `json:"insident_type,omitempty"`
+	Environment        models.Environment  `json:"environment" validate:"required"`
+	ActionStatus       models.ActionStatus `json:"actionStatus"`
+	Status             models.Status       `json:"status"`
+	Reporter           string              `json:"reporter"`
+	// Team is only honored for platform admins and unscoped callers; others create
+	// tickets for their own team
+	Team             string   `json:"team,omitempty"`
 	Suggestions      []string `json:"suggestions,omitempty"`
 	AffectedServices []string `json:"affectedServices,omitempty"`
 	Tags             []string `json:"tags,omitempty"`
@@ -21,7 +28,7 @@ type TicketResponse struct {
 	Description      string   `json:"description"`
 	Severity         string   `json:"severity"`
 	Category         string   `json:"category"`
-	IncidentType     string   `json:"insident_type"`
+	IncidentType     string   `json:"incident_type"`
 	Environment      string   `json:"environment"`
 	ActionStatus     string   `json:"actionStatus"`
 	Status           string   `json:"status"`
@@ -35,3 +42,27 @@ type TicketResponse struct {
 	AffectedServices []string `json:"affectedServices,omitempty"`
 	Tags             []string `json:"tags,omitempty"`
 }
+
+type UpdateTicketRequest struct {
+	Status       *models.Status       `json:"status,omitempty"`
+	Severity     *models.Severity     `json:"severity,omitempty"`
+	ActionStatus *models.ActionStatus `json:"actionStatus,omitempty"`
+	ActionTaken  *string              `json:"actionTaken,omitempty"`
+	// Cascade resolves the ticket's children as well when the update resolves it
+	Cascade bool `json:"cascade,omitempty"`
+}
+
+type ResolveTicketRequest struct {
+	Note    string `json:"note"`
+	Cascade bool   `json:"cascade"`
+}
+
+type CreateLinkRequest struct {
+	Type     models.LinkType `json:"type" validate:"required"`
+	TicketID string          `json:"ticketId" validate:"required"`
+}
+
+type RegenerateReportRequest struct {
+	PromptVersion string `json:"promptVersion"`
+	DryRun        bool   `json:"dryRun"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required"`
+	Description string   `json:"description"`
+	Events      []string `json:"events" validate:"required"`
+	Secret      string   `json:"secret,omitempty"`
+	// Team is only honored for platform admins and unscoped callers
+	Team string `json:"team,omitempty"`
+}
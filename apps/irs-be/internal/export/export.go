@@ -0,0 +1,273 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"irs-be/internal/models"
+	"irs-be/internal/tenant"
+)
+
+// Supported export formats
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatJSON   = "json"
+)
+
+// field extracts one exported column from a ticket
+type field struct {
+	name  string
+	value func(t models.IncidentTicket) interface{}
+}
+
+func optional(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+// links renders links as type:ticketId
+func links(links []models.TicketLink) []string {
+	out := make([]string, len(links))
+	for i, link := range links {
+		out[i] = string(link.Type) + ":" + link.TicketID
+	}
+	return out
+}
+
+// fields lists every exportable field, in default column order, by its JSON name
+var fields = []field{
+	{"id", func(t models.IncidentTicket) interface{} { return t.ID }},
+	{"title", func(t models.IncidentTicket) interface{} { return t.Title }},
+	{"description", func(t models.IncidentTicket) interface{} { return t.Description }},
+	{"severity", func(t models.IncidentTicket) interface{} { return t.Severity }},
+	{"category", func(t models.IncidentTicket) interface{} { return t.Category }},
+	{"incident_type", func(t models.IncidentTicket) interface{} { return t.IncidentType }},
+	{"environment", func(t models.IncidentTicket) interface{} { return t.Environment }},
+	{"actionStatus", func(t models.IncidentTicket) interface{} { return t.ActionStatus }},
+	{"status", func(t models.IncidentTicket) interface{} { return t.Status }},
+	{"reporter", func(t models.IncidentTicket) interface{} { return t.Reporter }},
+	{"team", func(t models.IncidentTicket) interface{} { return tenant.Resolve(t.Team) }},
+	{"createdAt", func(t models.IncidentTicket) interface{} { return t.CreatedAt }},
+	{"resolutionTime", func(t models.IncidentTicket) interface{} { return optional(t.ResolutionTime) }},
+	{"emailSent", func(t models.IncidentTicket) interface{} { return t.EmailSent }},
+	{"emailSentAt", func(t models.IncidentTicket) interface{} { return optional(t.EmailSentAt) }},
+	{"actionTaken", func(t models.IncidentTicket) interface{} { return optional(t.ActionTaken) }},
+	{"affectedServices", func(t models.IncidentTicket) interface{} { return t.AffectedServices }},
+	{"tags", func(t models.IncidentTicket) interface{} { return t.Tags }},
+	{"links", func(t models.IncidentTicket) interface{} { return links(t.Links) }},
+	{"suggestions", func(t models.IncidentTicket) interface{} { return t.Suggestions }},
+	{"report", func(t models.IncidentTicket) interface{} { return t.Report }},
+}
+
+// legacyFields maps legacy field names to the fields they select; the column keeps
+// the requested name
+var legacyFields = map[string]string{
+	models.LegacyIncidentTypeField: "incident_type",
+}
+
+// FieldNames lists every exportable field name
+func FieldNames() []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	return names
+}
+
+// selectFields resolves a field selection; an empty selection means every field
+func selectFields(names []string) ([]field, error) {
+	if len(names) == 0 {
+		return fields, nil
+	}
+
+	byName := make(map[string]field, len(fields))
+	for _, f := range fields {
+		byName[f.name] = f
+	}
+
+	selected := make([]field, 0, len(names))
+	for _, name := range names {
+		f, ok := byName[name]
+		if current, legacy := legacyFields[name]; legacy {
+			f, ok = byName[current]
+			f.name = name
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown export field %q, available fields: %s", name, strings.Join(FieldNames(), ", "))
+		}
+		selected = append(selected, f)
+	}
+	return selected, nil
+}
+
+// Writer writes tickets in one export format. Close must be called to finish the document.
+type Writer interface {
+	Write(ticket models.IncidentTicket) error
+	Close() error
+}
+
+// ContentType returns the MIME type of an export format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json"
+	}
+}
+
+// Validate checks a format and field selection before any output is written
+func Validate(format string, fieldNames []string) error {
+	switch format {
+	case FormatCSV, FormatNDJSON, FormatJSON:
+	default:
+		return fmt.Errorf("unsupported export format %q, use csv, ndjson or json", format)
+	}
+	_, err := selectFields(fieldNames)
+	return err
+}
+
+// NewWriter creates a writer for the format, limited to the selected fields
+func NewWriter(w io.Writer, format string, fieldNames []string) (Writer, error) {
+	selected, err := selectFields(fieldNames)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, selected)
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w), fields: selected}, nil
+	case FormatJSON:
+		return &jsonWriter{w: w, fields: selected}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// orderedRecord keeps the selected field order when encoded as a JSON object
+type orderedRecord struct {
+	fields []field
+	ticket models.IncidentTicket
+}
+
+func (r orderedRecord) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, f := range r.fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.name)
+		value, err := json.Marshal(f.value(r.ticket))
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+	fields  []field
+}
+
+func (w *ndjsonWriter) Write(ticket models.IncidentTicket) error {
+	return w.encoder.Encode(orderedRecord{fields: w.fields, ticket: ticket})
+}
+
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
+
+// jsonWriter streams a single JSON array, one element at a time
+type jsonWriter struct {
+	w      io.Writer
+	fields []field
+	count  int
+}
+
+func (w *jsonWriter) Write(ticket models.IncidentTicket) error {
+	prefix := ",\n"
+	if w.count == 0 {
+		prefix = "[\n"
+	}
+	data, err := json.Marshal(orderedRecord{fields: w.fields, ticket: ticket})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w.w, prefix); err != nil {
+		return err
+	}
+	w.count++
+	_, err = w.w.Write(data)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	if w.count == 0 {
+		_, err := io.WriteString(w.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(w.w, "\n]\n")
+	return err
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	fields []field
+	row    []string
+}
+
+func newCSVWriter(w io.Writer, selected []field) (*csvWriter, error) {
+	writer := &csvWriter{w: csv.NewWriter(w), fields: selected, row: make([]string, len(selected))}
+	for i, f := range selected {
+		writer.row[i] = f.name
+	}
+	if err := writer.w.Write(writer.row); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *csvWriter) Write(ticket models.IncidentTicket) error {
+	for i, f := range w.fields {
+		w.row[i] = csvValue(f.value(ticket))
+	}
+	return w.w.Write(w.row)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// csvValue flattens a field value into a single cell; lists are joined with ";"
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return strings.Join(v, ";")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"irs-be/internal/dto"
+	"irs-be/internal/models"
+	"irs-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ChatOpsHandler struct {
+	chatOpsService *services.ChatOpsService
+}
+
+// NewChatOpsHandler creates a new ChatOps handler
+func NewChatOpsHandler(chatOpsService *services.ChatOpsService) *ChatOpsHandler {
+	return &ChatOpsHandler{
+		chatOpsService: chatOpsService,
+	}
+}
+
+// VerifySlackSignature rejects requests that are not signed with the Slack signing secret
+func (h *ChatOpsHandler) VerifySlackSignature(c *fiber.Ctx) error {
+	if !h.chatOpsService.Enabled() {
+		return c.Status(http.StatusServiceUnavailable).JSON(models.APIResponse{
+			Success: false,
+			Error:   "ChatOps is not configured",
+		})
+	}
+
+	err := h.chatOpsService.VerifySignature(
+		c.Get("X-Slack-Request-Timestamp"),
+		c.Get("X-Slack-Signature"),
+		c.Body(),
+	)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	return c.Next()
+}
+
+// SlashCommand handles POST /api/chatops/slack/commands
+func (h *ChatOpsHandler) SlashCommand(c *fiber.Ctx) error {
+	var cmd dto.SlackSlashCommand
+	if err := c.BodyParser(&cmd); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid slash command payload: " + err.Error(),
+		})
+	}
+
+	return c.JSON(h.chatOpsService.HandleSlashCommand(c.UserContext(), cmd))
+}
+
+// Interaction handles POST /api/chatops/slack/interactions
+func (h *ChatOpsHandler) Interaction(c *fiber.Ctx) error {
+	var interaction dto.SlackInteraction
+	if err := json.Unmarshal([]byte(c.FormValue("payload")), &interaction); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid interaction payload: " + err.Error(),
+		})
+	}
+
+	return c.JSON(h.chatOpsService.HandleInteraction(c.UserContext(), interaction))
+}
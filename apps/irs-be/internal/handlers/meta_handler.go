@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"irs-be/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MetaHandler struct{}
+
+// NewMetaHandler creates a handler for API metadata
+func NewMetaHandler() *MetaHandler {
+	return &MetaHandler{}
+}
+
+// GetEnums handles GET /api/meta/enums
+// It lists the allowed values of every enumerated ticket field, keyed by the JSON
+// field name, so clients do not have to hard-code them.
+func (h *MetaHandler) GetEnums(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    models.Enums(),
+	})
+}
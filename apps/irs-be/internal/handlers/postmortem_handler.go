@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"irs-be/internal/dto"
+	"irs-be/internal/models"
+	"irs-be/internal/postmortem"
+	"irs-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PostmortemHandler struct {
+	postmortemService *services.PostmortemService
+}
+
+// NewPostmortemHandler creates a new postmortem handler
+func NewPostmortemHandler(postmortemService *services.PostmortemService) *PostmortemHandler {
+	return &PostmortemHandler{
+		postmortemService: postmortemService,
+	}
+}
+
+// GetPostmortemDocument handles GET /api/tickets/:id/postmortem?format=md|html
+func (h *PostmortemHandler) GetPostmortemDocument(c *fiber.Ctx) error {
+	format := c.Query("format", postmortem.FormatMarkdown)
+
+	document, err := h.postmortemService.RenderDocument(c.UserContext(), c.Params("id"), format)
+	if err != nil {
+		return h.errorResponse(c, "Failed to render postmortem", err)
+	}
+
+	if format == postmortem.FormatHTML {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	} else {
+		c.Set(fiber.HeaderContentType, "text/markdown; charset=utf-8")
+	}
+	return c.SendString(document)
+}
+
+// GetPostmortems handles GET /api/postmortems
+func (h *PostmortemHandler) GetPostmortems(c *fiber.Ctx) error {
+	postmortems, err := h.postmortemService.ListPostmortems(c.UserContext())
+	if err != nil {
+		return h.errorResponse(c, "Failed to fetch postmortems", err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    postmortems,
+	})
+}
+
+// GetPostmortem handles GET /api/postmortems/:ticketId
+func (h *PostmortemHandler) GetPostmortem(c *fiber.Ctx) error {
+	pm, err := h.postmortemService.GetPostmortem(c.UserContext(), c.Params("ticketId"))
+	if err != nil {
+		return h.errorResponse(c, "Failed to fetch postmortem", err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    pm,
+	})
+}
+
+// UpsertPostmortem handles PUT /api/postmortems/:ticketId
+func (h *PostmortemHandler) UpsertPostmortem(c *fiber.Ctx) error {
+	var req dto.UpsertPostmortemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid request body: " + err.Error(),
+		})
+	}
+
+	pm, err := h.postmortemService.UpsertPostmortem(c.UserContext(), c.Params("ticketId"), req)
+	if err != nil {
+		return h.errorResponse(c, "Failed to save postmortem", err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    pm,
+	})
+}
+
+// GetActionItems handles GET /api/postmortems/action-items?status=open&owner=alice&overdue=true
+func (h *PostmortemHandler) GetActionItems(c *fiber.Ctx) error {
+	status := c.Query("status")
+	if status != "" && !models.ActionItemStatus(status).IsValid() {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Unknown action item status: " + status,
+		})
+	}
+
+	items, err := h.postmortemService.ListActionItems(c.UserContext(), status, c.Query("owner"), c.QueryBool("overdue"))
+	if err != nil {
+		return h.errorResponse(c, "Failed to fetch action items", err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    items,
+	})
+}
+
+// CreateActionItem handles POST /api/postmortems/:ticketId/action-items
+func (h *PostmortemHandler) CreateActionItem(c *fiber.Ctx) error {
+	var req dto.CreateActionItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid request body: " + err.Error(),
+		})
+	}
+
+	if req.Description == "" || req.Owner == "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "description and owner are required",
+		})
+	}
+	if req.DueDate != "" && !services.ValidDueDate(req.DueDate) {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "dueDate must use the YYYY-MM-DD format",
+		})
+	}
+
+	pm, err := h.postmortemService.AddActionItem(c.UserContext(), c.Params("ticketId"), req)
+	if err != nil {
+		return h.errorResponse(c, "Failed to add action item", err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(models.APIResponse{
+		Success: true,
+		Data:    pm,
+	})
+}
+
+// UpdateActionItem handles PATCH /api/postmortems/:ticketId/action-items/:itemId
+func (h *PostmortemHandler) UpdateActionItem(c *fiber.Ctx) error {
+	var req dto.UpdateActionItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid request body: " + err.Error(),
+		})
+	}
+
+	if req.Status != nil && !models.ActionItemStatus(*req.Status).IsValid() {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Unknown action item status: " + *req.Status,
+		})
+	}
+	if req.DueDate != nil && *req.DueDate != "" && !services.ValidDueDate(*req.DueDate) {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "dueDate must use the YYYY-MM-DD format",
+		})
+	}
+
+	pm, err := h.postmortemService.UpdateActionItem(c.UserContext(), c.Params("ticketId"), c.Params("itemId"), req)
+	if err != nil {
+		return h.errorResponse(c, "Failed to update action item", err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    pm,
+	})
+}
+
+// DeleteActionItem handles DELETE /api/postmortems/:ticketId/action-items/:itemId
+func (h *PostmortemHandler) DeleteActionItem(c *fiber.Ctx) error {
+	pm, err := h.postmortemService.DeleteActionItem(c.UserContext(), c.Params("ticketId"), c.Params("itemId"))
+	if err != nil {
+		return h.errorResponse(c, "Failed to delete action item", err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    pm,
+	})
+}
+
+// errorResponse maps postmortem service errors to HTTP status codes
+func (h *PostmortemHandler) errorResponse(c *fiber.Ctx, message string, err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrUnsupportedPostmortemFormat):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrTicketNotFound),
+		errors.Is(err, services.ErrPostmortemNotFound),
+		errors.Is(err, services.ErrActionItemNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrPostmortemConflict):
+		status = http.StatusConflict
+	}
+
+	return c.Status(status).JSON(models.APIResponse{
+		Success: false,
+		Error:   message + ": " + err.Error(),
+	})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"irs-be/internal/dto"
+	"irs-be/internal/llm"
+	"irs-be/internal/models"
+	"irs-be/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReportHandler struct {
+	reportService *services.ReportService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// RegenerateReport handles POST /api/tickets/:id/report:regenerate
+func (h *ReportHandler) RegenerateReport(c *fiber.Ctx) error {
+	var req dto.RegenerateReportRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Invalid request body: " + err.Error(),
+			})
+		}
+	}
+
+	result, err := h.reportService.RegenerateReport(c.UserContext(), c.Params("id"), req.PromptVersion, req.DryRun)
+	switch {
+	case errors.Is(err, llm.ErrUnknownPromptVersion):
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	case errors.Is(err, services.ErrReportGenerationDisabled):
+		return c.Status(http.StatusServiceUnavailable).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	case errors.Is(err, context.DeadlineExceeded):
+		return c.Status(http.StatusGatewayTimeout).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Report generation timed out: " + err.Error(),
+		})
+	case err != nil:
+		return c.Status(http.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to regenerate report: " + err.Error(),
+		})
+	}
+
+	if result == nil {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Ticket not found",
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// GetPromptVersions handles GET /api/reports/prompts
+func (h *ReportHandler) GetPromptVersions(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    h.reportService.PromptVersions(),
+	})
+}
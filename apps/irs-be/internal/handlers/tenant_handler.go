@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"irs-be/internal/auth"
+	"irs-be/internal/models"
+	"irs-be/internal/tenant"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TenantHandler struct {
+	teams *tenant.Directory
+}
+
+// NewTenantHandler creates a handler for the caller's identity and the team settings
+func NewTenantHandler(teams *tenant.Directory) *TenantHandler {
+	return &TenantHandler{
+		teams: teams,
+	}
+}
+
+// GetMe handles GET /api/me
+// It returns the caller and the effective settings of the caller's team.
+func (h *TenantHandler) GetMe(c *fiber.Ctx) error {
+	principal, _ := auth.FromContext(c.UserContext())
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data: fiber.Map{
+			"principal": principal,
+			"team":      h.teams.Settings(principal.Team),
+		},
+	})
+}
+
+// GetTeams handles GET /api/platform/teams
+// It lists the effective settings of every configured team, for platform admins.
+func (h *TenantHandler) GetTeams(c *fiber.Ctx) error {
+	teams := []tenant.Settings{}
+	for _, team := range h.teams.Teams() {
+		teams = append(teams, h.teams.Settings(team))
+	}
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    teams,
+	})
+}
@@ -1,29 +1,49 @@
 package handlers
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	"irs-be/internal/deadline"
+	"irs-be/internal/dto"
+	"irs-be/internal/export"
+	"irs-be/internal/logging"
 	"irs-be/internal/models"
+	"irs-be/internal/resilience"
 	"irs-be/internal/services"
+	"irs-be/internal/tenant"
+	"irs-be/internal/ticketquery"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// exportPageSize is the number of tickets scanned per page while exporting
+const exportPageSize = 100
+
+// QueryPlanHeader reports how a filtered list was read from DynamoDB
+const QueryPlanHeader = "X-IRS-Query-Plan"
+
 type TicketHandler struct {
 	ticketService *services.TicketService
+	streamTimeout time.Duration
 }
 
-// NewTicketHandler creates a new ticket handler
-func NewTicketHandler(ticketService *services.TicketService) *TicketHandler {
+// NewTicketHandler creates a new ticket handler. streamTimeout bounds exports,
+// which outlive the request deadline.
+func NewTicketHandler(ticketService *services.TicketService, streamTimeout time.Duration) *TicketHandler {
 	return &TicketHandler{
 		ticketService: ticketService,
+		streamTimeout: streamTimeout,
 	}
 }
 
 // GetAllTickets handles GET /api/tickets
 func (h *TicketHandler) GetAllTickets(c *fiber.Ctx) error {
-	tickets, err := h.ticketService.GetAllTickets()
+	tickets, err := h.ticketService.GetAllTickets(c.UserContext())
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
 			Success: false,
@@ -47,7 +67,7 @@ func (h *TicketHandler) GetTicketByID(c *fiber.Ctx) error {
 		})
 	}
 
-	ticket, err := h.ticketService.GetTicketByID(id)
+	ticket, err := h.ticketService.GetTicketByID(c.UserContext(), id)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
 			Success: false,
@@ -68,17 +88,351 @@ func (h *TicketHandler) GetTicketByID(c *fiber.Ctx) error {
 	})
 }
 
+// CreateTicket handles POST /api/tickets
+func (h *TicketHandler) CreateTicket(c *fiber.Ctx) error {
+	var req dto.CreateTicketRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid request body: " + err.Error(),
+		})
+	}
+
+	if req.IncidentType == "" {
+		req.IncidentType = req.LegacyIncidentType
+	}
+	if req.Title == "" || req.Description == "" || req.Severity == "" || req.Category == "" ||
+		req.IncidentType == "" || req.Environment == "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "title, description, severity, category, incident_type and environment are required",
+		})
+	}
+	if err := validateCreateTicket(req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	ticket, err := h.ticketService.CreateTicket(c.UserContext(), req)
+	if errors.Is(err, services.ErrTeamNotAllowed) {
+		return c.Status(http.StatusForbidden).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to create ticket: " + err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(models.APIResponse{
+		Success: true,
+		Data:    ticket,
+	})
+}
+
+// UpdateTicket handles PATCH /api/tickets/:id
+func (h *TicketHandler) UpdateTicket(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Ticket ID is required",
+		})
+	}
+
+	var req dto.UpdateTicketRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid request body: " + err.Error(),
+		})
+	}
+	if err := validateUpdateTicket(req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	ticket, err := h.ticketService.UpdateTicket(c.UserContext(), id, req)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to update ticket: " + err.Error(),
+		})
+	}
+
+	if ticket == nil {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Ticket not found",
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    ticket,
+		Message: h.resolveChildren(c, req.Cascade, ticket),
+	})
+}
+
+// AcknowledgeTicket handles POST /api/tickets/:id/ack
+func (h *TicketHandler) AcknowledgeTicket(c *fiber.Ctx) error {
+	ticket, err := h.ticketService.AcknowledgeTicket(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to acknowledge ticket: " + err.Error(),
+		})
+	}
+
+	if ticket == nil {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Ticket not found",
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    ticket,
+	})
+}
+
+// ResolveTicket handles POST /api/tickets/:id/resolve
+func (h *TicketHandler) ResolveTicket(c *fiber.Ctx) error {
+	var req dto.ResolveTicketRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Invalid request body: " + err.Error(),
+			})
+		}
+	}
+
+	ticket, err := h.ticketService.ResolveTicket(c.UserContext(), c.Params("id"), req.Note)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to resolve ticket: " + err.Error(),
+		})
+	}
+
+	if ticket == nil {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Ticket not found",
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    ticket,
+		Message: h.resolveChildren(c, req.Cascade, ticket),
+	})
+}
+
+// resolveChildren cascades a resolution to the ticket's children when asked to and
+// describes the outcome for the response message
+func (h *TicketHandler) resolveChildren(c *fiber.Ctx, cascade bool, ticket *models.IncidentTicket) string {
+	if !cascade || !models.IsResolvedStatus(ticket.Status) {
+		return ""
+	}
+
+	resolved, err := h.ticketService.ResolveChildren(c.UserContext(), *ticket)
+	message := fmt.Sprintf("Resolved %d child tickets", len(resolved))
+	if err != nil {
+		logging.FromContext(c.UserContext()).Error("Failed to resolve child tickets", "id", ticket.ID, "error", err)
+		message += "; failed to resolve " + strings.ReplaceAll(err.Error(), "\n", ", ")
+	}
+	return message
+}
+
+// GetTicketLinks handles GET /api/tickets/:id/links
+// It returns the link graph around the ticket, up to depth links away (default 2).
+func (h *TicketHandler) GetTicketLinks(c *fiber.Ctx) error {
+	depth := c.QueryInt("depth", 2)
+	if depth < 1 || depth > services.MaxLinkDepth {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("depth must be between 1 and %d", services.MaxLinkDepth),
+		})
+	}
+
+	graph, err := h.ticketService.LinkGraph(c.UserContext(), c.Params("id"), depth)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch ticket links: " + err.Error(),
+		})
+	}
+
+	if graph == nil {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Ticket not found",
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    graph,
+	})
+}
+
+// LinkTicket handles POST /api/tickets/:id/links
+func (h *TicketHandler) LinkTicket(c *fiber.Ctx) error {
+	var req dto.CreateLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid request body: " + err.Error(),
+		})
+	}
+	if req.TicketID == "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "ticketId is required",
+		})
+	}
+	if _, err := models.ParseLinkType(string(req.Type)); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	ticket, err := h.ticketService.LinkTickets(c.UserContext(), c.Params("id"), req)
+	if err != nil {
+		return h.linkErrorResponse(c, "Failed to link tickets", err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    ticket,
+	})
+}
+
+// UnlinkTicket handles DELETE /api/tickets/:id/links/:linkedId
+func (h *TicketHandler) UnlinkTicket(c *fiber.Ctx) error {
+	ticket, err := h.ticketService.UnlinkTickets(c.UserContext(), c.Params("id"), c.Params("linkedId"))
+	if err != nil {
+		return h.linkErrorResponse(c, "Failed to unlink tickets", err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    ticket,
+	})
+}
+
+// linkErrorResponse maps ticket link errors to HTTP status codes
+func (h *TicketHandler) linkErrorResponse(c *fiber.Ctx, message string, err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalidLink):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrTicketNotFound),
+		errors.Is(err, services.ErrLinkNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrLinkExists),
+		errors.Is(err, services.ErrLinkConflict):
+		status = http.StatusConflict
+	}
+
+	return c.Status(status).JSON(models.APIResponse{
+		Success: false,
+		Error:   message + ": " + err.Error(),
+	})
+}
+
+// DeleteTicket handles DELETE /api/tickets/:id
+// The ticket is soft deleted and can be undeleted until a retention rule archives it.
+func (h *TicketHandler) DeleteTicket(c *fiber.Ctx) error {
+	ticket, err := h.ticketService.DeleteTicket(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to delete ticket: " + err.Error(),
+		})
+	}
+
+	if ticket == nil {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Ticket not found",
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    ticket,
+		Message: "Ticket deleted",
+	})
+}
+
+// UndeleteTicket handles POST /api/tickets/:id/undelete
+func (h *TicketHandler) UndeleteTicket(c *fiber.Ctx) error {
+	ticket, err := h.ticketService.UndeleteTicket(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to undelete ticket: " + err.Error(),
+		})
+	}
+
+	if ticket == nil {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Deleted ticket not found",
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    ticket,
+	})
+}
+
+// GetDeletedTickets handles GET /api/tickets/deleted
+func (h *TicketHandler) GetDeletedTickets(c *fiber.Ctx) error {
+	tickets, err := h.ticketService.GetDeletedTickets(c.UserContext())
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch deleted tickets: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    tickets,
+	})
+}
+
 // GetTicketsByStatus handles GET /api/tickets/status/:status
 func (h *TicketHandler) GetTicketsByStatus(c *fiber.Ctx) error {
-	status := c.Params("status")
-	if status == "" {
+	if c.Params("status") == "" {
 		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
 			Success: false,
 			Error:   "Status is required",
 		})
 	}
+	status, err := models.ParseStatus(c.Params("status"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
 
-	tickets, err := h.ticketService.GetTicketsByStatus(status)
+	tickets, err := h.ticketService.GetTicketsByStatus(c.UserContext(), status)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
 			Success: false,
@@ -94,15 +448,21 @@ func (h *TicketHandler) GetTicketsByStatus(c *fiber.Ctx) error {
 
 // GetTicketsBySeverity handles GET /api/tickets/severity/:severity
 func (h *TicketHandler) GetTicketsBySeverity(c *fiber.Ctx) error {
-	severity := c.Params("severity")
-	if severity == "" {
+	if c.Params("severity") == "" {
 		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
 			Success: false,
 			Error:   "Severity is required",
 		})
 	}
+	severity, err := models.ParseSeverity(c.Params("severity"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
 
-	tickets, err := h.ticketService.GetTicketsBySeverity(severity)
+	tickets, err := h.ticketService.GetTicketsBySeverity(c.UserContext(), severity)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
 			Success: false,
@@ -118,15 +478,21 @@ func (h *TicketHandler) GetTicketsBySeverity(c *fiber.Ctx) error {
 
 // GetTicketsByIncidentType handles GET /api/tickets/incident-type/:incidentType
 func (h *TicketHandler) GetTicketsByIncidentType(c *fiber.Ctx) error {
-	incidentType := c.Params("incidentType")
-	if incidentType == "" {
+	if c.Params("incidentType") == "" {
 		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
 			Success: false,
 			Error:   "Incident type is required",
 		})
 	}
+	incidentType, err := models.ParseIncidentType(c.Params("incidentType"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
 
-	tickets, err := h.ticketService.GetTicketsByIncidentType(incidentType)
+	tickets, err := h.ticketService.GetTicketsByIncidentType(c.UserContext(), incidentType)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
 			Success: false,
@@ -150,7 +516,7 @@ func (h *TicketHandler) SearchTickets(c *fiber.Ctx) error {
 		})
 	}
 
-	tickets, err := h.ticketService.SearchTickets(query)
+	tickets, err := h.ticketService.SearchTickets(c.UserContext(), query)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
 			Success: false,
@@ -165,9 +531,26 @@ func (h *TicketHandler) SearchTickets(c *fiber.Ctx) error {
 }
 
 // GetTicketsWithFilters handles GET /api/tickets/filter
+// The response leaves out ticket reports; the chosen query plan is returned in the
+// X-IRS-Query-Plan header.
 func (h *TicketHandler) GetTicketsWithFilters(c *fiber.Ctx) error {
-	// Get all tickets first
-	tickets, err := h.ticketService.GetAllTickets()
+	query, err := ticketQuery(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+	order, err := ticketquery.ParseSort(c.Query("sort"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	tickets, plan, err := h.ticketService.GetTicketsWithFilters(c.UserContext(), query)
+	c.Set(QueryPlanHeader, plan.String())
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
 			Success: false,
@@ -175,105 +558,179 @@ func (h *TicketHandler) GetTicketsWithFilters(c *fiber.Ctx) error {
 		})
 	}
 
-	// Apply filters
-	filteredTickets := h.applyFilters(tickets, c)
-
+	ticketquery.Sort(tickets, order)
 	return c.JSON(models.APIResponse{
 		Success: true,
-		Data:    filteredTickets,
+		Data:    tickets,
 	})
 }
 
-// applyFilters applies query parameters as filters
-func (h *TicketHandler) applyFilters(tickets []models.IncidentTicket, c *fiber.Ctx) []models.IncidentTicket {
-	filtered := tickets
+// ExportTickets handles GET /api/tickets/export
+func (h *TicketHandler) ExportTickets(c *fiber.Ctx) error {
+	format := c.Query("format", export.FormatJSON)
 
-	// Filter by severity
-	if severity := c.Query("severity"); severity != "" {
-		var filteredBySeverity []models.IncidentTicket
-		for _, ticket := range filtered {
-			if ticket.Severity == severity {
-				filteredBySeverity = append(filteredBySeverity, ticket)
-			}
+	var fields []string
+	for _, name := range strings.Split(c.Query("fields"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			fields = append(fields, name)
 		}
-		filtered = filteredBySeverity
 	}
 
-	// Filter by category
-	if category := c.Query("category"); category != "" {
-		var filteredByCategory []models.IncidentTicket
-		for _, ticket := range filtered {
-			if ticket.Category == category {
-				filteredByCategory = append(filteredByCategory, ticket)
-			}
-		}
-		filtered = filteredByCategory
+	if err := export.Validate(format, fields); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
 	}
 
-	// Filter by environment
-	if environment := c.Query("environment"); environment != "" {
-		var filteredByEnvironment []models.IncidentTicket
-		for _, ticket := range filtered {
-			if ticket.Environment == environment {
-				filteredByEnvironment = append(filteredByEnvironment, ticket)
-			}
-		}
-		filtered = filteredByEnvironment
+	query, err := ticketQuery(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
 	}
 
-	// Filter by status
-	if status := c.Query("status"); status != "" {
-		var filteredByStatus []models.IncidentTicket
-		for _, ticket := range filtered {
-			if ticket.Status == status {
-				filteredByStatus = append(filteredByStatus, ticket)
-			}
+	ctx, cancel := deadline.Stream(c, h.streamTimeout)
+	filename := fmt.Sprintf("tickets-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+
+	c.Set(fiber.HeaderContentType, export.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	// The body is written after the handler returns, so errors past this point can
+	// only be logged; the client sees a truncated file
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		writer, err := export.NewWriter(w, format, fields)
+		if err != nil {
+			logging.FromContext(ctx).Error("Failed to start ticket export", "error", err)
+			return
 		}
-		filtered = filteredByStatus
-	}
 
-	// Filter by action status
-	if actionStatus := c.Query("actionStatus"); actionStatus != "" {
-		var filteredByActionStatus []models.IncidentTicket
-		for _, ticket := range filtered {
-			if ticket.ActionStatus == actionStatus {
-				filteredByActionStatus = append(filteredByActionStatus, ticket)
+		now := time.Now()
+		err = h.ticketService.StreamTickets(ctx, exportPageSize, func(ticket models.IncidentTicket) error {
+			if ticket.Removed() || !query.Match(ticket, now) {
+				return nil
 			}
+			return writer.Write(ticket)
+		})
+		if err != nil {
+			logging.FromContext(ctx).Error("Failed to export tickets", "error", err)
+			w.Flush()
+			return
 		}
-		filtered = filteredByActionStatus
+
+		if err := writer.Close(); err != nil {
+			logging.FromContext(ctx).Error("Failed to finish ticket export", "error", err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+// ticketQuery reads the filter parameters shared by /filter and /export: the
+// single-value filters and a q parameter in the ticket query language. Unknown
+// enum values are rejected rather than matching nothing.
+func ticketQuery(c *fiber.Ctx) (ticketquery.Node, error) {
+	filters := filtersFromQuery(c)
+	if err := models.ValidateFilters(filters); err != nil {
+		return nil, err
+	}
+	query := ticketquery.FromFilters(filters)
+	if q := c.Query("q"); q != "" {
+		parsed, err := ticketquery.Parse(q)
+		if err != nil {
+			return nil, err
+		}
+		query = append(query, parsed)
 	}
+	return query, nil
+}
 
-	// Filter by incident type
-	if incidentType := c.Query("incidentType"); incidentType != "" {
-		var filteredByIncidentType []models.IncidentTicket
-		for _, ticket := range filtered {
-			if ticket.IncidentType == incidentType {
-				filteredByIncidentType = append(filteredByIncidentType, ticket)
-			}
+// filtersFromQuery reads the single-value filter query parameters
+func filtersFromQuery(c *fiber.Ctx) models.TicketFilters {
+	return models.TicketFilters{
+		Severity:     c.Query("severity"),
+		Category:     c.Query("category"),
+		Environment:  c.Query("environment"),
+		Status:       c.Query("status"),
+		ActionStatus: c.Query("actionStatus"),
+		IncidentType: c.Query("incidentType"),
+		Team:         c.Query("team"),
+		Search:       c.Query("search"),
+	}
+}
+
+// validateCreateTicket rejects unknown enum values in a new ticket; the optional
+// actionStatus and status may be left empty for their defaults
+func validateCreateTicket(req dto.CreateTicketRequest) error {
+	if _, err := models.ParseSeverity(string(req.Severity)); err != nil {
+		return err
+	}
+	if _, err := models.ParseCategory(string(req.Category)); err != nil {
+		return err
+	}
+	if _, err := models.ParseIncidentType(string(req.IncidentType)); err != nil {
+		return err
+	}
+	if _, err := models.ParseEnvironment(string(req.Environment)); err != nil {
+		return err
+	}
+	if req.ActionStatus != "" {
+		if _, err := models.ParseActionStatus(string(req.ActionStatus)); err != nil {
+			return err
 		}
-		filtered = filteredByIncidentType
-	}
-
-	// Search filter
-	if search := c.Query("search"); search != "" {
-		var filteredBySearch []models.IncidentTicket
-		searchLower := strings.ToLower(search)
-		for _, ticket := range filtered {
-			if strings.Contains(strings.ToLower(ticket.Title), searchLower) ||
-				strings.Contains(strings.ToLower(ticket.Description), searchLower) ||
-				strings.Contains(strings.ToLower(ticket.Report), searchLower) {
-				filteredBySearch = append(filteredBySearch, ticket)
-			}
+	}
+	if req.Status != "" {
+		if _, err := models.ParseStatus(string(req.Status)); err != nil {
+			return err
 		}
-		filtered = filteredBySearch
 	}
+	if req.Team != "" && !tenant.ValidTeam(req.Team) {
+		return fmt.Errorf("invalid team %q", req.Team)
+	}
+	return nil
+}
 
-	return filtered
+// validateUpdateTicket rejects unknown enum values in a partial update
+func validateUpdateTicket(req dto.UpdateTicketRequest) error {
+	if req.Status != nil {
+		if _, err := models.ParseStatus(string(*req.Status)); err != nil {
+			return err
+		}
+	}
+	if req.Severity != nil {
+		if _, err := models.ParseSeverity(string(*req.Severity)); err != nil {
+			return err
+		}
+	}
+	if req.ActionStatus != nil {
+		if _, err := models.ParseActionStatus(string(*req.ActionStatus)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // HealthCheck handles GET /health
+// While the DynamoDB circuit breaker is open the service reports itself degraded
+// but alive: restarting the pod would not bring DynamoDB back, and reads may still
+// be answered from stale data.
 func (h *TicketHandler) HealthCheck(c *fiber.Ctx) error {
-	err := h.ticketService.HealthCheck()
+	if state := h.ticketService.BreakerState(); state != resilience.StateClosed {
+		return c.JSON(models.APIResponse{
+			Success: true,
+			Message: "Service is degraded",
+			Data: fiber.Map{
+				"status":         "degraded",
+				"circuitBreaker": state.String(),
+			},
+		})
+	}
+
+	err := h.ticketService.HealthCheck(c.UserContext())
 	if err != nil {
 		return c.Status(http.StatusServiceUnavailable).JSON(models.APIResponse{
 			Success: false,
@@ -284,5 +741,9 @@ func (h *TicketHandler) HealthCheck(c *fiber.Ctx) error {
 	return c.JSON(models.APIResponse{
 		Success: true,
 		Message: "Service is healthy",
+		Data: fiber.Map{
+			"status":         "healthy",
+			"circuitBreaker": resilience.StateClosed.String(),
+		},
 	})
 }
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"irs-be/internal/dto"
+	"irs-be/internal/models"
+	"irs-be/internal/services"
+	"irs-be/internal/tenant"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhook handles POST /api/webhooks
+func (h *WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	var req dto.CreateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid request body: " + err.Error(),
+		})
+	}
+
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "A valid http(s) url is required",
+		})
+	}
+
+	if len(req.Events) == 0 {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "At least one event type is required",
+		})
+	}
+	for _, e := range req.Events {
+		if !models.TicketEventType(e).IsValid() {
+			return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Error:   "Unknown event type: " + e,
+			})
+		}
+	}
+
+	if req.Team != "" && !tenant.ValidTeam(req.Team) {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Invalid team: " + req.Team,
+		})
+	}
+
+	subscription, err := h.webhookService.CreateSubscription(c.UserContext(), req)
+	if errors.Is(err, services.ErrTeamNotAllowed) {
+		return c.Status(http.StatusForbidden).JSON(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to create webhook: " + err.Error(),
+		})
+	}
+
+	// The secret is only returned once, when the subscription is created
+	return c.Status(http.StatusCreated).JSON(models.APIResponse{
+		Success: true,
+		Data:    subscription,
+	})
+}
+
+// GetWebhooks handles GET /api/webhooks
+func (h *WebhookHandler) GetWebhooks(c *fiber.Ctx) error {
+	subscriptions, err := h.webhookService.ListSubscriptions(c.UserContext())
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch webhooks: " + err.Error(),
+		})
+	}
+
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    subscriptions,
+	})
+}
+
+// GetWebhookByID handles GET /api/webhooks/:id
+func (h *WebhookHandler) GetWebhookByID(c *fiber.Ctx) error {
+	subscription, err := h.webhookService.GetSubscription(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch webhook: " + err.Error(),
+		})
+	}
+
+	if subscription == nil {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Webhook not found",
+		})
+	}
+
+	subscription.Secret = ""
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    subscription,
+	})
+}
+
+// DeleteWebhook handles DELETE /api/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	found, err := h.webhookService.DeleteSubscription(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to delete webhook: " + err.Error(),
+		})
+	}
+
+	if !found {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Webhook not found",
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Webhook deleted",
+	})
+}
+
+// GetWebhookDeliveries handles GET /api/webhooks/:id/deliveries
+func (h *WebhookHandler) GetWebhookDeliveries(c *fiber.Ctx) error {
+	status := c.Query("status")
+	switch models.WebhookDeliveryStatus(status) {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliveryRetrying,
+		models.WebhookDeliverySucceeded, models.WebhookDeliveryDeadLettered:
+	default:
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Unknown delivery status: " + status,
+		})
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 500 {
+		return c.Status(http.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Error:   "limit must be between 1 and 500",
+		})
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.UserContext(), c.Params("id"), status, limit)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to fetch webhook deliveries: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    deliveries,
+	})
+}
+
+// RedeliverWebhook handles POST /api/webhooks/:id/deliveries/:deliveryId/redeliver
+func (h *WebhookHandler) RedeliverWebhook(c *fiber.Ctx) error {
+	delivery, err := h.webhookService.Redeliver(c.UserContext(), c.Params("id"), c.Params("deliveryId"))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Failed to redeliver webhook: " + err.Error(),
+		})
+	}
+
+	if delivery == nil {
+		return c.Status(http.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Error:   "Webhook delivery not found",
+		})
+	}
+
+	return c.Status(http.StatusAccepted).JSON(models.APIResponse{
+		Success: true,
+		Data:    delivery,
+	})
+}
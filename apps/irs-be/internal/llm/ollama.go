@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GenerateOptions are the sampling options forwarded to the model
+type GenerateOptions struct {
+	Temperature float64 `json:"temperature"`
+	TopP        float64 `json:"top_p"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+
+// DefaultGenerateOptions mirrors the options used by lks-incident-report.py
+var DefaultGenerateOptions = GenerateOptions{
+	Temperature: 0.3,
+	TopP:        0.9,
+	NumPredict:  800,
+}
+
+type generateRequest struct {
+	Model   string          `json:"model"`
+	Prompt  string          `json:"prompt"`
+	Stream  bool            `json:"stream"`
+	Options GenerateOptions `json:"options"`
+}
+
+// generateChunk is one line of the streamed /api/generate response
+type generateChunk struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+}
+
+// retryableError marks failures worth another attempt (network errors, 429 and 5xx)
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string { return e.err.Error() }
+func (e retryableError) Unwrap() error { return e.err }
+
+// OllamaClient calls any Ollama-compatible /api/generate endpoint
+type OllamaClient struct {
+	baseURL    string
+	model      string
+	timeout    time.Duration
+	maxRetries int
+	// retryDelay is the wait before the first retry; it doubles with every attempt
+	retryDelay time.Duration
+	httpClient *http.Client
+}
+
+// NewOllamaClient creates a client for the given endpoint and model.
+// Each attempt is bounded by timeout; failed attempts are retried up to maxRetries times.
+func NewOllamaClient(baseURL, model string, timeout time.Duration, maxRetries int) *OllamaClient {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	return &OllamaClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		model:      model,
+		timeout:    timeout,
+		maxRetries: maxRetries,
+		retryDelay: time.Second,
+		httpClient: &http.Client{},
+	}
+}
+
+// Model returns the model name sent with every request
+func (c *OllamaClient) Model() string {
+	return c.model
+}
+
+// Generate streams a completion for the prompt and returns the concatenated response
+func (c *OllamaClient) Generate(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := time.Duration(1<<uint(attempt-1)) * c.retryDelay
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+
+		text, err := c.generateOnce(ctx, prompt, opts)
+		if err == nil {
+			return text, nil
+		}
+		lastErr = err
+
+		var retryable retryableError
+		if !errors.As(err, &retryable) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return "", fmt.Errorf("ollama generate failed: %v", lastErr)
+}
+
+func (c *OllamaClient) generateOnce(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	body, err := json.Marshal(generateRequest{
+		Model:   c.model,
+		Prompt:  prompt,
+		Stream:  true,
+		Options: opts,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", retryableError{fmt.Errorf("request failed: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		err := fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return "", retryableError{err}
+		}
+		return "", err
+	}
+
+	var out strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk generateChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return "", fmt.Errorf("invalid stream chunk: %v", err)
+		}
+		if chunk.Error != "" {
+			return "", fmt.Errorf("model error: %s", chunk.Error)
+		}
+		out.WriteString(chunk.Response)
+		if chunk.Done {
+			return strings.TrimSpace(out.String()), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", retryableError{fmt.Errorf("stream interrupted: %v", err)}
+	}
+
+	return "", retryableError{errors.New("stream ended before completion")}
+}
@@ -0,0 +1,315 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient points a client at a stub server and shortens the retry delay
+func newTestClient(t *testing.T, handler http.HandlerFunc, timeout time.Duration, maxRetries int) (*OllamaClient, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewOllamaClient(server.URL+"/", "test-model", timeout, maxRetries)
+	client.retryDelay = time.Millisecond
+	return client, &calls
+}
+
+// writeChunks streams one JSON chunk per line, flushing after each
+func writeChunks(w http.ResponseWriter, chunks ...generateChunk) {
+	for _, chunk := range chunks {
+		line, _ := json.Marshal(chunk)
+		fmt.Fprintf(w, "%s\n", line)
+		w.(http.Flusher).Flush()
+	}
+}
+
+func TestGenerateAssemblesStreamedChunks(t *testing.T) {
+	client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/generate" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req generateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		if req.Model != "test-model" || req.Prompt != "prompt" || !req.Stream || req.Options != DefaultGenerateOptions {
+			t.Errorf("unexpected request %+v", req)
+		}
+
+		writeChunks(w,
+			generateChunk{Response: "  ## Summary\n"},
+			generateChunk{Response: "CPU "},
+			generateChunk{Response: "spiked. "},
+			generateChunk{Done: true},
+			generateChunk{Response: "after done"},
+		)
+	}, time.Second, 0)
+
+	text, err := client.Generate(context.Background(), "prompt", DefaultGenerateOptions)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if want := "## Summary\nCPU spiked."; text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+	if *calls != 1 {
+		t.Errorf("calls = %d, want 1", *calls)
+	}
+}
+
+func TestGenerateRetriesTransientFailures(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var attempts int32
+			client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) < 3 {
+					http.Error(w, "busy", status)
+					return
+				}
+				writeChunks(w, generateChunk{Response: "ok", Done: true})
+			}, time.Second, 2)
+
+			text, err := client.Generate(context.Background(), "prompt", DefaultGenerateOptions)
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			if text != "ok" {
+				t.Errorf("text = %q, want ok", text)
+			}
+			if *calls != 3 {
+				t.Errorf("calls = %d, want 3", *calls)
+			}
+		})
+	}
+}
+
+func TestGenerateRetriesInterruptedStream(t *testing.T) {
+	var attempts int32
+	client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			writeChunks(w, generateChunk{Response: "partial"})
+			return
+		}
+		writeChunks(w, generateChunk{Response: "complete", Done: true})
+	}, time.Second, 1)
+
+	text, err := client.Generate(context.Background(), "prompt", DefaultGenerateOptions)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if text != "complete" {
+		t.Errorf("text = %q, want complete", text)
+	}
+	if *calls != 2 {
+		t.Errorf("calls = %d, want 2", *calls)
+	}
+}
+
+func TestGenerateGivesUpAfterRetryBudget(t *testing.T) {
+	client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusBadGateway)
+	}, time.Second, 2)
+
+	_, err := client.Generate(context.Background(), "prompt", DefaultGenerateOptions)
+	if err == nil || !strings.Contains(err.Error(), "unexpected status code 502: overloaded") {
+		t.Fatalf("err = %v, want the last status", err)
+	}
+	if *calls != 3 {
+		t.Errorf("calls = %d, want 3", *calls)
+	}
+}
+
+func TestGenerateDoesNotRetryPermanentFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{
+			name:    "client error",
+			handler: func(w http.ResponseWriter, r *http.Request) { http.Error(w, "model not found", http.StatusNotFound) },
+			want:    "unexpected status code 404: model not found",
+		},
+		{
+			name:    "model error",
+			handler: func(w http.ResponseWriter, r *http.Request) { writeChunks(w, generateChunk{Error: "out of memory"}) },
+			want:    "model error: out of memory",
+		},
+		{
+			name: "malformed chunk",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"response": "ok"`)
+			},
+			want: "invalid stream chunk",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, calls := newTestClient(t, tt.handler, time.Second, 3)
+
+			_, err := client.Generate(context.Background(), "prompt", DefaultGenerateOptions)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+			if *calls != 1 {
+				t.Errorf("calls = %d, want 1", *calls)
+			}
+		})
+	}
+}
+
+func TestGenerateTimesOutEachAttempt(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeChunks(w, generateChunk{Response: "slow"})
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}, 50*time.Millisecond, 1)
+
+	start := time.Now()
+	_, err := client.Generate(context.Background(), "prompt", DefaultGenerateOptions)
+	if err == nil {
+		t.Fatal("Generate succeeded, want a timeout")
+	}
+	if *calls != 2 {
+		t.Errorf("calls = %d, want 2", *calls)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Generate took %v, want each attempt bounded by the timeout", elapsed)
+	}
+}
+
+func TestGenerateStopsWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}, time.Second, 5)
+	client.retryDelay = time.Hour
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Generate(ctx, "prompt", DefaultGenerateOptions)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("Generate succeeded, want an error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Generate kept retrying after the context was canceled")
+	}
+	if *calls != 1 {
+		t.Errorf("calls = %d, want 1", *calls)
+	}
+
+	_, err := client.Generate(ctx, "prompt", DefaultGenerateOptions)
+	if !errors.Is(err, context.Canceled) && (err == nil || !strings.Contains(err.Error(), context.Canceled.Error())) {
+		t.Errorf("err = %v, want context canceled", err)
+	}
+}
+
+func TestSplitCombined(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		report      string
+		suggestions string
+	}{
+		{
+			name:        "heading",
+			text:        "## Summary\nDisk full\n\n### Suggestions:\n1. Rotate logs regularly\n",
+			report:      "## Summary\nDisk full",
+			suggestions: "1. Rotate logs regularly",
+		},
+		{
+			name:   "no heading",
+			text:   "  Disk full, suggestions follow inline  ",
+			report: "Disk full, suggestions follow inline",
+		},
+		{
+			name:   "heading mid-line is not a heading",
+			text:   "See ## Suggestions below",
+			report: "See ## Suggestions below",
+		},
+		{
+			name:        "empty report",
+			text:        "## SUGGESTIONS\n- Restart the service now",
+			suggestions: "- Restart the service now",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, suggestions := SplitCombined(tt.text)
+			if report != tt.report || suggestions != tt.suggestions {
+				t.Errorf("SplitCombined = (%q, %q), want (%q, %q)", report, suggestions, tt.report, tt.suggestions)
+			}
+		})
+	}
+}
+
+func TestParseSuggestions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "numbered and bulleted",
+			text: "1. Increase the instance size\n2) Add a memory alarm\n- Tune the JVM heap settings\n• Review recent deploys\n* Enable swap monitoring",
+			want: []string{"Increase the instance size", "Add a memory alarm", "Tune the JVM heap settings", "Review recent deploys", "Enable swap monitoring"},
+		},
+		{
+			name: "short items are dropped",
+			text: "1. Reboot\n2. Check the load balancer health checks",
+			want: []string{"Check the load balancer health checks"},
+		},
+		{
+			name: "prose falls back to sentences",
+			text: "Restart the pod. Then check the logs.",
+			want: []string{"Restart the pod", "Then check the logs"},
+		},
+		{
+			name: "only short items fall back to sentences",
+			text: "1. Reboot",
+			want: []string{"1", "Reboot"},
+		},
+		{
+			name: "empty output falls back to defaults",
+			text: "  \n ",
+			want: fallbackSuggestions,
+		},
+		{
+			name: "at most five",
+			text: "- First suggestion here\n- Second suggestion here\n- Third suggestion here\n- Fourth suggestion here\n- Fifth suggestion here\n- Sixth suggestion here",
+			want: []string{"First suggestion here", "Second suggestion here", "Third suggestion here", "Fourth suggestion here", "Fifth suggestion here"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseSuggestions(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSuggestions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxSuggestions matches the limit applied by lks-incident-report.py
+const maxSuggestions = 5
+
+// fallbackSuggestions are used when the model output contains no usable suggestions
+var fallbackSuggestions = []string{
+	"Investigate system logs for error patterns",
+	"Check resource utilization and system health",
+	"Verify service dependencies and connectivity",
+	"Consider restarting affected services",
+	"Monitor system after resolution attempts",
+}
+
+var suggestionsHeading = regexp.MustCompile(`(?im)^#{1,6}\s*suggestions\s*:?\s*$`)
+
+// SplitCombined splits the output of a combined prompt into the report and the
+// raw suggestions section. Without a suggestions heading everything is report.
+func SplitCombined(text string) (report, suggestions string) {
+	loc := suggestionsHeading.FindStringIndex(text)
+	if loc == nil {
+		return strings.TrimSpace(text), ""
+	}
+	return strings.TrimSpace(text[:loc[0]]), strings.TrimSpace(text[loc[1]:])
+}
+
+// ParseSuggestions turns a numbered or bulleted list into individual suggestions,
+// following parse_suggestions in lks-incident-report.py
+func ParseSuggestions(text string) []string {
+	var suggestions []string
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		first := line[0]
+		if (first >= '0' && first <= '9') || strings.HasPrefix(line, "-") ||
+			strings.HasPrefix(line, "•") || strings.HasPrefix(line, "*") {
+			suggestion := strings.TrimSpace(strings.TrimLeft(line, "0123456789.)-•* "))
+			if len(suggestion) > 10 { // Avoid very short suggestions
+				suggestions = append(suggestions, suggestion)
+			}
+		}
+	}
+
+	// Fall back to sentences when the model ignored the list format
+	if len(suggestions) == 0 && strings.TrimSpace(text) != "" {
+		for _, sentence := range strings.Split(text, ".") {
+			if sentence = strings.TrimSpace(sentence); sentence != "" {
+				suggestions = append(suggestions, sentence)
+			}
+		}
+	}
+
+	if len(suggestions) == 0 {
+		suggestions = append(suggestions, fallbackSuggestions...)
+	}
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions
+}
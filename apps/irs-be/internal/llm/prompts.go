@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed prompts
+var promptFS embed.FS
+
+var ErrUnknownPromptVersion = errors.New("unknown prompt version")
+
+// DefaultPromptVersion is used when no version is configured or requested
+const DefaultPromptVersion = "v1"
+
+// PromptData is the ticket information available to prompt templates
+type PromptData struct {
+	ID               string
+	Title            string
+	IncidentType     string
+	Severity         string
+	Environment      string
+	Status           string
+	Description      string
+	AffectedServices []string
+	CreatedAt        string
+	ActionTaken      string
+	Context          string
+}
+
+// PromptTemplate is one versioned set of prompts. Combined templates ask for the
+// report and the suggestions in a single completion.
+type PromptTemplate struct {
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Combined    bool   `json:"combined"`
+
+	report      *template.Template
+	suggestions *template.Template
+}
+
+var promptTemplates = map[string]*PromptTemplate{
+	"v1": {
+		Version:     "v1",
+		Description: "Port of lks-incident-report.py: separate report and suggestions prompts",
+		report:      mustParsePrompt("prompts/v1/report.tmpl"),
+		suggestions: mustParsePrompt("prompts/v1/suggestions.tmpl"),
+	},
+	"v2": {
+		Version:     "v2",
+		Description: "Single prompt with fixed markdown sections, suggestions in the last section",
+		Combined:    true,
+		report:      mustParsePrompt("prompts/v2/combined.tmpl"),
+	},
+}
+
+func mustParsePrompt(name string) *template.Template {
+	funcs := template.FuncMap{"join": strings.Join}
+	return template.Must(template.New(name[strings.LastIndex(name, "/")+1:]).Funcs(funcs).ParseFS(promptFS, name))
+}
+
+// GetPromptTemplate looks up a prompt template by version
+func GetPromptTemplate(version string) (*PromptTemplate, error) {
+	if version == "" {
+		version = DefaultPromptVersion
+	}
+	tmpl, ok := promptTemplates[version]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownPromptVersion, version)
+	}
+	return tmpl, nil
+}
+
+// PromptTemplates lists every registered prompt template, oldest version first
+func PromptTemplates() []*PromptTemplate {
+	templates := make([]*PromptTemplate, 0, len(promptTemplates))
+	for _, tmpl := range promptTemplates {
+		templates = append(templates, tmpl)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Version < templates[j].Version
+	})
+	return templates
+}
+
+// RenderReport renders the report prompt, or the combined prompt for combined templates
+func (t *PromptTemplate) RenderReport(data PromptData) (string, error) {
+	return render(t.report, data)
+}
+
+// RenderSuggestions renders the suggestions prompt. Combined templates have none.
+func (t *PromptTemplate) RenderSuggestions(data PromptData) (string, error) {
+	if t.suggestions == nil {
+		return "", fmt.Errorf("prompt version %s has no separate suggestions prompt", t.Version)
+	}
+	return render(t.suggestions, data)
+}
+
+func render(tmpl *template.Template, data PromptData) (string, error) {
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %v", tmpl.Name(), err)
+	}
+	return out.String(), nil
+}
+
+// IncidentContext returns the type, environment and severity specific guidance
+// that lks-incident-report.py adds to its prompts
+func IncidentContext(incidentType, environment, severity string) string {
+	var parts []string
+
+	switch incidentType {
+	case "CPU_HIGH":
+		parts = append(parts, `**CPU High Utilization Context:**
+- Monitor for sustained vs spike patterns
+- Check for runaway processes or inefficient code
+- Consider CPU-intensive operations or increased load
+- Review auto-scaling policies and thresholds`)
+	case "MEM_HIGH":
+		parts = append(parts, `**Memory High Utilization Context:**
+- Check for memory leaks in applications
+- Monitor garbage collection performance
+- Review memory allocation patterns
+- Consider container memory limits`)
+	case "APP_CRASH":
+		parts = append(parts, `**Application Crash Context:**
+- Check application logs for crash signals
+- Review recent deployments or changes
+- Look for resource exhaustion (OOM killer)
+- Analyze crash dumps if available`)
+	case "APP_SHUTDOWN":
+		parts = append(parts, `**Application Shutdown Context:**
+- Determine if shutdown was graceful or forced
+- Check for system-level shutdown signals
+- Review process health checks and dependencies
+- Look for resource constraints causing shutdown`)
+	case "APP_ERROR":
+		parts = append(parts, `**Application Error Context:**
+- Review error logs and stack traces
+- Check external dependencies (DB, APIs, services)
+- Analyze error patterns and frequency
+- Consider configuration or deployment issues`)
+	}
+
+	if environment == "production" {
+		parts = append(parts, "**Production Environment**: Prioritize fast resolution to minimize user impact.")
+	}
+	if severity == "critical" || severity == "high" {
+		parts = append(parts, "**High Priority**: This incident requires immediate attention and escalation.")
+	}
+
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDLocal is the fiber local holding the request ID
+const requestIDLocal = "requestId"
+
+// maxRequestIDLength bounds client-supplied request IDs before they reach the logs
+const maxRequestIDLength = 128
+
+// RequestID returns the request ID of a fiber request
+func RequestID(c *fiber.Ctx) string {
+	requestID, _ := c.Locals(requestIDLocal).(string)
+	return requestID
+}
+
+// RequestIDMiddleware reuses the caller's X-Request-ID or generates one, echoes it in
+// the response, stores it in the user context for service logs and adds it to JSON
+// error bodies
+func RequestIDMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := strings.TrimSpace(c.Get(RequestIDHeader))
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+
+		c.Locals(requestIDLocal, requestID)
+		c.Set(RequestIDHeader, requestID)
+		c.SetUserContext(WithRequestID(c.UserContext(), requestID))
+
+		err := c.Next()
+		if err == nil && c.Response().StatusCode() >= fiber.StatusBadRequest {
+			addRequestIDToBody(c, requestID)
+		}
+		return err
+	}
+}
+
+// addRequestIDToBody adds a requestId field to JSON object error responses
+func addRequestIDToBody(c *fiber.Ctx, requestID string) {
+	if !strings.HasPrefix(string(c.Response().Header.ContentType()), fiber.MIMEApplicationJSON) {
+		return
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(c.Response().Body(), &body); err != nil {
+		return
+	}
+	body["requestId"], _ = json.Marshal(requestID)
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+	c.Response().SetBodyRaw(data)
+}
+
+// AccessLogMiddleware logs one line per request; server errors are logged at
+// error level and client errors at warn level
+func AccessLogMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []any{
+			"method", c.Method(),
+			"path", c.Path(),
+			"route", c.Route().Path,
+			"status", status,
+			"latencyMs", time.Since(start).Milliseconds(),
+			"ip", c.IP(),
+		}
+		if err != nil {
+			attrs = append(attrs, "error", err.Error())
+		}
+		FromContext(c.UserContext()).Log(c.UserContext(), level, "request", attrs...)
+		return err
+	}
+}
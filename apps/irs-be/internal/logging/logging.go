@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sensitiveKeys are attribute keys whose values are always redacted, wherever
+// they appear in a log line
+var sensitiveKeys = []string{"secret", "token", "password", "authorization", "signature", "accesskey", "credential"}
+
+const (
+	redactedSuffix = "****"
+	notSet         = "<not set>"
+)
+
+// Redact masks a secret, keeping a short prefix so operators can tell values apart.
+// Redacting an already redacted value returns it unchanged.
+func Redact(s string) string {
+	switch {
+	case s == "":
+		return notSet
+	case s == notSet || strings.HasSuffix(s, redactedSuffix):
+		return s
+	case len(s) <= 4:
+		return redactedSuffix
+	}
+	return s[:4] + redactedSuffix
+}
+
+// IsSensitiveKey reports whether a log attribute or field name holds a secret
+func IsSensitiveKey(key string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(normalized, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLevel maps LOG_LEVEL values to slog levels, defaulting to info
+func ParseLevel(value string) slog.Level {
+	switch strings.ToLower(value) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// NewLogger creates a JSON logger that redacts sensitive attributes
+func NewLogger(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Value.Kind() == slog.KindString && IsSensitiveKey(a.Key) {
+				return slog.String(a.Key, Redact(a.Value.String()))
+			}
+			return a
+		},
+	}))
+}
+
+// Setup installs the JSON logger on stdout as the default slog logger, which
+// also routes the standard log package through it
+func Setup(level string) *slog.Logger {
+	logger := NewLogger(os.Stdout, ParseLevel(level))
+	slog.SetDefault(logger)
+	return logger
+}
+
+type requestIDKey struct{}
+
+// WithRequestID stores the request ID in a context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in a context, if any
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// FromContext returns the default logger annotated with the request ID and trace ID
+// carried by the context, so service logs can be correlated with requests
+func FromContext(ctx context.Context) *slog.Logger {
+	logger := slog.Default()
+	if ctx == nil {
+		return logger
+	}
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		logger = logger.With("requestId", requestID)
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		logger = logger.With("traceId", spanContext.TraceID().String())
+	}
+	return logger
+}
@@ -0,0 +1,11 @@
+package metrics
+
+// IncCacheRequest counts a cache lookup by its result
+func IncCacheRequest(cache, result string) {
+	cacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// IncCacheInvalidation counts an invalidation of a cache
+func IncCacheInvalidation(cache string) {
+	cacheInvalidations.WithLabelValues(cache).Inc()
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// AddDynamoDBMiddleware instruments every call made by an AWS SDK client. Use it as
+// an entry of the client's APIOptions.
+func AddDynamoDBMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("IRSMetrics", func(
+		ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+	) (middleware.InitializeOutput, middleware.Metadata, error) {
+		start := time.Now()
+		out, metadata, err := next.HandleInitialize(ctx, in)
+
+		operation := awsmiddleware.GetOperationName(ctx)
+		dynamoDBDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+		if err != nil {
+			dynamoDBErrors.WithLabelValues(operation, errorCode(err)).Inc()
+		}
+		return out, metadata, err
+	}), middleware.Before)
+}
+
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	if errors.Is(err, context.Canceled) {
+		return "Canceled"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "DeadlineExceeded"
+	}
+	return "Unknown"
+}
+
+// IncDynamoDBRetry counts a retry made by the resilience layer
+func IncDynamoDBRetry(operation string) {
+	dynamoDBRetries.WithLabelValues(operation).Inc()
+}
+
+// SetCircuitState publishes the state of a circuit breaker: 0 closed, 1 half-open, 2 open
+func SetCircuitState(name string, state int) {
+	circuitState.WithLabelValues(name).Set(float64(state))
+}
+
+// IncStaleResponse counts a read answered with stale data while a dependency was unavailable
+func IncStaleResponse(operation string) {
+	staleResponses.WithLabelValues(operation).Inc()
+}
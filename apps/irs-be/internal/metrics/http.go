@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// unmatchedRoute labels requests that did not match any route, so scanners
+// probing random paths cannot blow up the label cardinality
+const unmatchedRoute = "unmatched"
+
+// Middleware records the count and latency of every request by route template
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		// Errors are turned into responses by the app error handler after the
+		// middleware chain returns, so derive the status the client will see
+		status := c.Response().StatusCode()
+		route := c.Route().Path
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+			// The router reports a missing route as a 404 error; handlers answer
+			// unknown resources with a JSON body instead
+			if status == fiber.StatusNotFound {
+				route = unmatchedRoute
+			}
+		}
+
+		labels := []string{c.Method(), route, strconv.Itoa(status)}
+		httpRequests.WithLabelValues(labels...).Inc()
+		httpDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
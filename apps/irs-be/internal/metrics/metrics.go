@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "irs"
+
+// Registry holds every irs-be metric plus the Go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dynamoDBDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "dynamodb_request_duration_seconds",
+		Help:      "DynamoDB call latency including SDK retries, by operation.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"operation"})
+
+	dynamoDBErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dynamodb_errors_total",
+		Help:      "Failed DynamoDB calls, by operation and error code.",
+	}, []string{"operation", "code"})
+
+	dynamoDBRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dynamodb_retries_total",
+		Help:      "DynamoDB calls retried after throttling or a transient error, by operation.",
+	}, []string{"operation"})
+
+	circuitState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_state",
+		Help:      "Circuit breaker state: 0 closed, 1 half-open, 2 open.",
+	}, []string{"breaker"})
+
+	staleResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stale_responses_total",
+		Help:      "Reads answered with the last known good result while DynamoDB was unavailable, by operation.",
+	}, []string{"operation"})
+
+	cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups, by cache and result: hit, miss or shared (joined an in-flight load).",
+	}, []string{"cache", "result"})
+
+	cacheInvalidations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_invalidations_total",
+		Help:      "Cache invalidations after a write, by cache.",
+	}, []string{"cache"})
+
+	openTickets = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "open_tickets",
+		Help:      "Unresolved tickets, by team and severity.",
+	}, []string{"team", "severity"})
+
+	slaBreachedTickets = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sla_breached_tickets",
+		Help:      "Unresolved tickets older than their team's SLA for their severity, by team and severity.",
+	}, []string{"team", "severity"})
+
+	ticketStatsRefreshed = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ticket_stats_last_refresh_timestamp_seconds",
+		Help:      "Unix time of the last successful refresh of the ticket gauges.",
+	})
+
+	ticketDecodeFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ticket_decode_failures_total",
+		Help:      "Ticket items read from DynamoDB that could not be decoded and were left out.",
+	})
+
+	streamRecords = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stream_records_total",
+		Help:      "Records read from the ticket table stream, by change type: insert, modify or remove.",
+	}, []string{"type"})
+
+	streamLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "stream_lag_seconds",
+		Help:      "Age of the oldest unprocessed change among the ticket table stream shards being read, 0 when caught up.",
+	})
+
+	streamShards = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "stream_shards",
+		Help:      "Ticket table stream shards being read.",
+	})
+
+	streamResets = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stream_resets_total",
+		Help:      "Times the stream consumer fell behind the stream's 24 hour retention and missed changes.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		dynamoDBDuration,
+		dynamoDBErrors,
+		dynamoDBRetries,
+		circuitState,
+		staleResponses,
+		cacheRequests,
+		cacheInvalidations,
+		openTickets,
+		slaBreachedTickets,
+		ticketStatsRefreshed,
+		ticketDecodeFailures,
+		streamRecords,
+		streamLag,
+		streamShards,
+		streamResets,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import "time"
+
+// IncStreamRecord counts a record read from the ticket table stream
+func IncStreamRecord(changeType string) {
+	streamRecords.WithLabelValues(changeType).Inc()
+}
+
+// SetStreamLag publishes how far the stream consumer is behind
+func SetStreamLag(lag time.Duration) {
+	streamLag.Set(lag.Seconds())
+}
+
+// SetStreamShards publishes the number of stream shards being read
+func SetStreamShards(n int) {
+	streamShards.Set(float64(n))
+}
+
+// IncStreamReset counts a gap in the changes read from the stream
+func IncStreamReset() {
+	streamResets.Inc()
+}
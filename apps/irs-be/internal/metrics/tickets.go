@@ -0,0 +1,34 @@
+package metrics
+
+import "time"
+
+// TicketStatsKey groups the ticket gauges
+type TicketStatsKey struct {
+	Team     string
+	Severity string
+}
+
+// TicketStats is a point-in-time summary of the ticket table
+type TicketStats struct {
+	Open        map[TicketStatsKey]int
+	SLABreached map[TicketStatsKey]int
+}
+
+// SetTicketStats replaces the ticket gauges with a fresh summary. Teams and
+// severities missing from the summary are reset so resolved backlogs drop to zero.
+func SetTicketStats(stats TicketStats) {
+	openTickets.Reset()
+	slaBreachedTickets.Reset()
+	for key, count := range stats.Open {
+		openTickets.WithLabelValues(key.Team, key.Severity).Set(float64(count))
+	}
+	for key, count := range stats.SLABreached {
+		slaBreachedTickets.WithLabelValues(key.Team, key.Severity).Set(float64(count))
+	}
+	ticketStatsRefreshed.Set(float64(time.Now().Unix()))
+}
+
+// IncTicketDecodeFailure counts a ticket item that could not be decoded
+func IncTicketDecodeFailure() {
+	ticketDecodeFailures.Inc()
+}
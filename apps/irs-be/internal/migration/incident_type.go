@@ -0,0 +1,44 @@
+package migration
+
+import (
+	"irs-be/internal/ticketcodec"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func init() {
+	Register(Migration{
+		ID:          "001-incident-type-attribute",
+		Description: "Copy insident_type to incident_type on every ticket",
+		Filter:      "attribute_exists(#old) AND (attribute_not_exists(#new) OR #new <> #old)",
+		Names: map[string]string{
+			"#old": ticketcodec.LegacyIncidentTypeAttribute,
+			"#new": ticketcodec.IncidentTypeAttribute,
+		},
+		Plan: planIncidentType,
+	})
+}
+
+// planIncidentType copies the legacy incident type attribute. insident_type is kept:
+// the lambdas still write it and IncidentTypeIndex is keyed on it. They update only
+// insident_type, e.g. lks-handle-failed falling back to OTHER, so the copy drifts
+// afterwards; ticketcodec.Decode reads insident_type when both are present.
+func planIncidentType(item map[string]types.AttributeValue) *Update {
+	old, ok := item[ticketcodec.LegacyIncidentTypeAttribute].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil
+	}
+	if current, ok := item[ticketcodec.IncidentTypeAttribute].(*types.AttributeValueMemberS); ok && current.Value == old.Value {
+		return nil
+	}
+	return &Update{
+		Key:        map[string]types.AttributeValue{"id": item["id"]},
+		Expression: "SET #new = :value",
+		Condition:  "#old = :value",
+		Names: map[string]string{
+			"#old": ticketcodec.LegacyIncidentTypeAttribute,
+			"#new": ticketcodec.IncidentTypeAttribute,
+		},
+		Values: map[string]types.AttributeValue{":value": old},
+	}
+}
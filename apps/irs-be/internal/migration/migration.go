@@ -0,0 +1,60 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Migration rewrites the items of a table one at a time, while the table stays in
+// use. Readers must understand both the old and the new shape for as long as a
+// migration runs; for ticket items that is the job of the ticketcodec upgraders.
+type Migration struct {
+	ID          string
+	Description string
+	// Filter is an optional scan FilterExpression that selects the items that may
+	// still need the migration, with its placeholders in Names and Values
+	Filter string
+	Names  map[string]string
+	Values map[string]types.AttributeValue
+	// Plan returns the update for one item, or nil when the item needs none
+	Plan func(item map[string]types.AttributeValue) *Update
+}
+
+// Update is a conditional UpdateItem on one item. The condition should fail when
+// the item changed since it was scanned, so concurrent writes are never overwritten.
+type Update struct {
+	Key        map[string]types.AttributeValue
+	Expression string
+	Condition  string
+	Names      map[string]string
+	Values     map[string]types.AttributeValue
+}
+
+var registry = map[string]Migration{}
+
+// Register adds a migration. It is meant to be called from init functions and
+// panics on a duplicate ID.
+func Register(m Migration) {
+	if _, ok := registry[m.ID]; ok {
+		panic(fmt.Sprintf("migration: %s registered twice", m.ID))
+	}
+	registry[m.ID] = m
+}
+
+// Lookup finds a registered migration by ID
+func Lookup(id string) (Migration, bool) {
+	m, ok := registry[id]
+	return m, ok
+}
+
+// All lists the registered migrations ordered by ID
+func All() []Migration {
+	all := make([]Migration, 0, len(registry))
+	for _, m := range registry {
+		all = append(all, m)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return all
+}
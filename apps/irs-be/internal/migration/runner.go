@@ -0,0 +1,240 @@
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"irs-be/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxErrors bounds the item errors kept in the progress
+const maxErrors = 20
+
+// Client is the subset of the DynamoDB API a backfill uses
+type Client interface {
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+}
+
+// Progress is the state of a backfill. It is saved to the checkpoint file after
+// every page, so an interrupted backfill resumes after the last finished page.
+type Progress struct {
+	Migration string `json:"migration"`
+	Table     string `json:"table"`
+	DryRun    bool   `json:"dryRun"`
+	StartedAt string `json:"startedAt"`
+	UpdatedAt string `json:"updatedAt"`
+	// LastKey is the key of the last scanned item; the next page starts after it
+	LastKey map[string]string `json:"lastKey,omitempty"`
+	// EstimatedItems is the item count DynamoDB reports, refreshed about every six hours
+	EstimatedItems int64 `json:"estimatedItems"`
+	Scanned        int64 `json:"scanned"`
+	Updated        int64 `json:"updated"`
+	// Conflicts counts items that changed between the scan and the update; run
+	// the migration again with -restart to revisit them
+	Conflicts int64    `json:"conflicts"`
+	Failed    int64    `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+	Done      bool     `json:"done"`
+}
+
+// Percent estimates how much of the table has been scanned
+func (p *Progress) Percent() float64 {
+	if p.Done {
+		return 100
+	}
+	if p.EstimatedItems <= 0 {
+		return 0
+	}
+	return min(99, float64(p.Scanned)*100/float64(p.EstimatedItems))
+}
+
+// Runner backfills a migration over a table
+type Runner struct {
+	Client Client
+	Table  string
+	// PageSize limits the items read per scan page, 0 for the DynamoDB default
+	PageSize int32
+	// Checkpoint is the file the progress is saved to and resumed from
+	Checkpoint string
+	// DryRun scans and plans without writing
+	DryRun bool
+	// Report is called with the progress after every page
+	Report func(Progress)
+}
+
+// Run scans the table and applies the migration to every item that needs it. It
+// resumes from the checkpoint when one exists for the same migration and table and
+// returns a finished checkpoint as is. Item errors are counted and do not stop the
+// backfill; scan errors and cancellation do, after saving the checkpoint.
+func (r *Runner) Run(ctx context.Context, m Migration) (*Progress, error) {
+	progress, err := r.load(m)
+	if err != nil {
+		return nil, err
+	}
+	if progress.Done {
+		return progress, nil
+	}
+
+	if table, err := r.Client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(r.Table)}); err == nil {
+		progress.EstimatedItems = aws.ToInt64(table.Table.ItemCount)
+	}
+
+	for {
+		input := &dynamodb.ScanInput{TableName: aws.String(r.Table)}
+		if r.PageSize > 0 {
+			input.Limit = aws.Int32(r.PageSize)
+		}
+		if m.Filter != "" {
+			input.FilterExpression = aws.String(m.Filter)
+			input.ExpressionAttributeNames = m.Names
+			input.ExpressionAttributeValues = m.Values
+		}
+		if len(progress.LastKey) > 0 {
+			input.ExclusiveStartKey = make(map[string]types.AttributeValue, len(progress.LastKey))
+			for name, value := range progress.LastKey {
+				input.ExclusiveStartKey[name] = &types.AttributeValueMemberS{Value: value}
+			}
+		}
+
+		page, err := r.Client.Scan(ctx, input)
+		if err != nil {
+			r.save(progress)
+			return progress, fmt.Errorf("failed to scan %s: %v", r.Table, err)
+		}
+
+		for _, item := range page.Items {
+			update := m.Plan(item)
+			if update == nil {
+				continue
+			}
+			if r.DryRun {
+				progress.Updated++
+				continue
+			}
+			r.apply(ctx, update, progress)
+		}
+
+		progress.Scanned += int64(page.ScannedCount)
+		progress.LastKey, err = stringKey(page.LastEvaluatedKey)
+		if err != nil {
+			return progress, err
+		}
+		progress.Done = len(page.LastEvaluatedKey) == 0
+		if err := r.save(progress); err != nil {
+			return progress, err
+		}
+		if r.Report != nil {
+			r.Report(*progress)
+		}
+		if progress.Done {
+			return progress, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+	}
+}
+
+// apply runs one update and counts its outcome
+func (r *Runner) apply(ctx context.Context, update *Update, progress *Progress) {
+	input := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.Table),
+		Key:                       update.Key,
+		UpdateExpression:          aws.String(update.Expression),
+		ExpressionAttributeNames:  update.Names,
+		ExpressionAttributeValues: update.Values,
+	}
+	if update.Condition != "" {
+		input.ConditionExpression = aws.String(update.Condition)
+	}
+
+	_, err := r.Client.UpdateItem(ctx, input)
+	var conditionErr *types.ConditionalCheckFailedException
+	switch {
+	case err == nil:
+		progress.Updated++
+	case errors.As(err, &conditionErr):
+		progress.Conflicts++
+	default:
+		progress.Failed++
+		if len(progress.Errors) < maxErrors {
+			key, _ := stringKey(update.Key)
+			progress.Errors = append(progress.Errors, fmt.Sprintf("%v: %v", key, err))
+		}
+	}
+}
+
+// load reads the checkpoint, or starts a new backfill when there is none
+func (r *Runner) load(m Migration) (*Progress, error) {
+	now := models.FormatTimestamp(time.Now())
+	fresh := &Progress{Migration: m.ID, Table: r.Table, DryRun: r.DryRun, StartedAt: now, UpdatedAt: now}
+	if r.Checkpoint == "" {
+		return fresh, nil
+	}
+
+	data, err := os.ReadFile(r.Checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+
+	var progress Progress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %v", r.Checkpoint, err)
+	}
+	if progress.Migration != m.ID || progress.Table != r.Table || progress.DryRun != r.DryRun {
+		return nil, fmt.Errorf("checkpoint %s belongs to %s on %s (dry run %t), remove it or choose another file",
+			r.Checkpoint, progress.Migration, progress.Table, progress.DryRun)
+	}
+	return &progress, nil
+}
+
+// save writes the checkpoint through a temporary file so a crash never leaves a
+// partial one behind
+func (r *Runner) save(progress *Progress) error {
+	progress.UpdatedAt = models.FormatTimestamp(time.Now())
+	if r.Checkpoint == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %v", err)
+	}
+	tmp := r.Checkpoint + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp, r.Checkpoint); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	return nil
+}
+
+// stringKey converts a table key to its string form; ticket keys are strings
+func stringKey(key map[string]types.AttributeValue) (map[string]string, error) {
+	if len(key) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(key))
+	for name, value := range key {
+		s, ok := value.(*types.AttributeValueMemberS)
+		if !ok {
+			return nil, fmt.Errorf("key attribute %s is %T, only string keys can be checkpointed", name, value)
+		}
+		out[name] = s.Value
+	}
+	return out, nil
+}
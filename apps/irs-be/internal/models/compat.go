@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// LegacyIncidentTypeField is the misspelled JSON name of IncidentTicket.IncidentType
+// that clients used before the rename to incident_type
+const LegacyIncidentTypeField = "insident_type"
+
+// legacyJSON controls whether tickets are also written with their legacy field names
+var legacyJSON atomic.Bool
+
+func init() {
+	legacyJSON.Store(true)
+}
+
+// SetLegacyJSON turns the compatibility JSON mode on or off. While it is on, tickets
+// carry both incident_type and insident_type so clients that have not been updated
+// keep working during the cutover. Input accepts both names either way.
+func SetLegacyJSON(enabled bool) {
+	legacyJSON.Store(enabled)
+}
+
+// LegacyJSON reports whether the compatibility JSON mode is on
+func LegacyJSON() bool {
+	return legacyJSON.Load()
+}
+
+// ticketJSON has the fields of IncidentTicket without its JSON methods
+type ticketJSON IncidentTicket
+
+// legacyTicketJSON adds the legacy field names to a ticket
+type legacyTicketJSON struct {
+	ticketJSON
+	LegacyIncidentType IncidentType `json:"insident_type,omitempty"`
+}
+
+// MarshalJSON writes the ticket, with its legacy field names in compatibility mode
+func (t IncidentTicket) MarshalJSON() ([]byte, error) {
+	if !LegacyJSON() {
+		return json.Marshal(ticketJSON(t))
+	}
+	return json.Marshal(legacyTicketJSON{ticketJSON: ticketJSON(t), LegacyIncidentType: t.IncidentType})
+}
+
+// UnmarshalJSON reads a ticket written with either the current or the legacy field
+// names, preferring the current ones
+func (t *IncidentTicket) UnmarshalJSON(data []byte) error {
+	var v legacyTicketJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = IncidentTicket(v.ticketJSON)
+	if t.IncidentType == "" {
+		t.IncidentType = v.LegacyIncidentType
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the urgency of an incident
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Severities lists the severities from least to most urgent
+var Severities = []Severity{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// Status is where a ticket is in its lifecycle. The lambdas write open, pending,
+// processing, manual_intervention_required, resolved and failed; the dashboard
+// uses in-progress, solved and closed.
+type Status string
+
+const (
+	StatusOpen                       Status = "open"
+	StatusInProgress                 Status = "in-progress"
+	StatusPending                    Status = "pending"
+	StatusProcessing                 Status = "processing"
+	StatusManualInterventionRequired Status = "manual_intervention_required"
+	StatusSolved                     Status = "solved"
+	StatusResolved                   Status = "resolved"
+	StatusClosed                     Status = "closed"
+	StatusFailed                     Status = "failed"
+)
+
+// Statuses lists every status a ticket can have
+var Statuses = []Status{
+	StatusOpen, StatusInProgress, StatusPending, StatusProcessing, StatusManualInterventionRequired,
+	StatusSolved, StatusResolved, StatusClosed, StatusFailed,
+}
+
+// Category is the part of the platform an incident belongs to
+type Category string
+
+const (
+	CategoryInfrastructure Category = "infrastructure"
+	CategoryKubernetes     Category = "kubernetes"
+	CategoryCICD           Category = "ci-cd"
+	CategoryOther          Category = "other"
+)
+
+// Categories lists every category
+var Categories = []Category{CategoryInfrastructure, CategoryKubernetes, CategoryCICD, CategoryOther}
+
+// IncidentType is what the monitoring lambdas detected
+type IncidentType string
+
+const (
+	IncidentTypeCPUHigh     IncidentType = "CPU_HIGH"
+	IncidentTypeMemHigh     IncidentType = "MEM_HIGH"
+	IncidentTypeAppCrash    IncidentType = "APP_CRASH"
+	IncidentTypeAppShutdown IncidentType = "APP_SHUTDOWN"
+	IncidentTypeAppError    IncidentType = "APP_ERROR"
+	IncidentTypeOther       IncidentType = "OTHER"
+)
+
+// IncidentTypes lists every incident type the lambdas emit
+var IncidentTypes = []IncidentType{
+	IncidentTypeCPUHigh, IncidentTypeMemHigh, IncidentTypeAppCrash,
+	IncidentTypeAppShutdown, IncidentTypeAppError, IncidentTypeOther,
+}
+
+// Environment is the deployment an incident happened in, taken from the
+// lowercased Environment tag of the instance
+type Environment string
+
+const (
+	EnvironmentProduction  Environment = "production"
+	EnvironmentStaging     Environment = "staging"
+	EnvironmentDevelopment Environment = "development"
+)
+
+// Environments lists every environment
+var Environments = []Environment{EnvironmentProduction, EnvironmentStaging, EnvironmentDevelopment}
+
+// ActionStatus records whether an incident was handled automatically or by hand
+type ActionStatus string
+
+const (
+	ActionStatusAuto    ActionStatus = "auto"
+	ActionStatusManual  ActionStatus = "manual"
+	ActionStatusPending ActionStatus = "pending"
+)
+
+// ActionStatuses lists every action status
+var ActionStatuses = []ActionStatus{ActionStatusAuto, ActionStatusManual, ActionStatusPending}
+
+// InvalidValueError reports a value that is not one of the allowed values of an enum
+type InvalidValueError struct {
+	Kind    string
+	Value   string
+	Allowed []string
+}
+
+func (e *InvalidValueError) Error() string {
+	return fmt.Sprintf("invalid %s %q, use one of %s", e.Kind, e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// parseEnum returns the allowed value equal to value
+func parseEnum[T ~string](kind, value string, allowed []T) (T, error) {
+	for _, v := range allowed {
+		if string(v) == value {
+			return v, nil
+		}
+	}
+	return "", &InvalidValueError{Kind: kind, Value: value, Allowed: enumStrings(allowed)}
+}
+
+func enumStrings[T ~string](values []T) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = string(v)
+	}
+	return out
+}
+
+// ParseSeverity parses a severity such as "critical"
+func ParseSeverity(value string) (Severity, error) {
+	return parseEnum("severity", value, Severities)
+}
+
+// ParseStatus parses a ticket status such as "open"
+func ParseStatus(value string) (Status, error) {
+	return parseEnum("status", value, Statuses)
+}
+
+// ParseCategory parses a category such as "infrastructure"
+func ParseCategory(value string) (Category, error) {
+	return parseEnum("category", value, Categories)
+}
+
+// ParseIncidentType parses an incident type such as "CPU_HIGH"
+func ParseIncidentType(value string) (IncidentType, error) {
+	return parseEnum("incident type", value, IncidentTypes)
+}
+
+// ParseEnvironment parses an environment such as "production"
+func ParseEnvironment(value string) (Environment, error) {
+	return parseEnum("environment", value, Environments)
+}
+
+// ParseActionStatus parses an action status such as "auto"
+func ParseActionStatus(value string) (ActionStatus, error) {
+	return parseEnum("action status", value, ActionStatuses)
+}
+
+// IsValid reports whether s is a known severity
+func (s Severity) IsValid() bool {
+	_, err := ParseSeverity(string(s))
+	return err == nil
+}
+
+// IsValid reports whether s is a known ticket status
+func (s Status) IsValid() bool {
+	_, err := ParseStatus(string(s))
+	return err == nil
+}
+
+// IsValid reports whether c is a known category
+func (c Category) IsValid() bool {
+	_, err := ParseCategory(string(c))
+	return err == nil
+}
+
+// IsValid reports whether t is a known incident type
+func (t IncidentType) IsValid() bool {
+	_, err := ParseIncidentType(string(t))
+	return err == nil
+}
+
+// IsValid reports whether e is a known environment
+func (e Environment) IsValid() bool {
+	_, err := ParseEnvironment(string(e))
+	return err == nil
+}
+
+// IsValid reports whether a is a known action status
+func (a ActionStatus) IsValid() bool {
+	_, err := ParseActionStatus(string(a))
+	return err == nil
+}
+
+// enumFields maps the JSON name of every enumerated ticket field to its kind, as
+// used in error messages, and its allowed values
+var enumFields = map[string]struct {
+	kind   string
+	values func() []string
+}{
+	"severity":      {"severity", func() []string { return enumStrings(Severities) }},
+	"status":        {"status", func() []string { return enumStrings(Statuses) }},
+	"category":      {"category", func() []string { return enumStrings(Categories) }},
+	"incident_type": {"incident type", func() []string { return enumStrings(IncidentTypes) }},
+	"environment":   {"environment", func() []string { return enumStrings(Environments) }},
+	"actionStatus":  {"action status", func() []string { return enumStrings(ActionStatuses) }},
+	"linkType":      {"link type", func() []string { return enumStrings(LinkTypes) }},
+}
+
+// Enums lists the allowed values of every enumerated ticket field, keyed by the
+// JSON name of the field, and by its legacy name in compatibility mode
+func Enums() map[string][]string {
+	enums := make(map[string][]string, len(enumFields)+1)
+	for field, enum := range enumFields {
+		enums[field] = enum.values()
+	}
+	if LegacyJSON() {
+		enums[LegacyIncidentTypeField] = enums["incident_type"]
+	}
+	return enums
+}
+
+// ValidateEnum checks a value of the enumerated field with the given JSON name,
+// current or legacy. Fields that are not enumerated accept any value.
+func ValidateEnum(field, value string) error {
+	if field == LegacyIncidentTypeField {
+		field = "incident_type"
+	}
+	enum, ok := enumFields[field]
+	if !ok {
+		return nil
+	}
+	allowed := enum.values()
+	for _, v := range allowed {
+		if v == value {
+			return nil
+		}
+	}
+	return &InvalidValueError{Kind: enum.kind, Value: value, Allowed: allowed}
+}
+
+// ValidateFilters checks the enumerated fields of the filters, ignoring empty ones
+func ValidateFilters(f TicketFilters) error {
+	for _, filter := range []struct{ field, value string }{
+		{"severity", f.Severity},
+		{"status", f.Status},
+		{"category", f.Category},
+		{"incident_type", f.IncidentType},
+		{"environment", f.Environment},
+		{"actionStatus", f.ActionStatus},
+	} {
+		if filter.value == "" {
+			continue
+		}
+		if err := ValidateEnum(filter.field, filter.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
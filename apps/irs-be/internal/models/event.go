@@ -0,0 +1,42 @@
+package models
+
+// TicketEventType identifies a ticket lifecycle event
+type TicketEventType string
+
+const (
+	TicketEventCreated       TicketEventType = "created"
+	TicketEventStatusChanged TicketEventType = "status_changed"
+	TicketEventEscalated     TicketEventType = "escalated"
+	TicketEventResolved      TicketEventType = "resolved"
+	TicketEventDeleted       TicketEventType = "deleted"
+	TicketEventUndeleted     TicketEventType = "undeleted"
+)
+
+// TicketEventTypes lists every lifecycle event a subscriber can filter on
+var TicketEventTypes = []TicketEventType{
+	TicketEventCreated,
+	TicketEventStatusChanged,
+	TicketEventEscalated,
+	TicketEventResolved,
+	TicketEventDeleted,
+	TicketEventUndeleted,
+}
+
+// IsValid reports whether the event type is one of TicketEventTypes
+func (t TicketEventType) IsValid() bool {
+	for _, known := range TicketEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// TicketEvent describes a change to an incident ticket
+type TicketEvent struct {
+	ID         string          `json:"id"`
+	Type       TicketEventType `json:"type"`
+	OccurredAt string          `json:"occurredAt"`
+	Ticket     IncidentTicket  `json:"ticket"`
+	Previous   *IncidentTicket `json:"previous,omitempty"`
+}
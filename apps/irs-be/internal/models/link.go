@@ -0,0 +1,107 @@
+package models
+
+// LinkType is how a ticket relates to a linked ticket. A link is stored on both
+// tickets, with the inverse type on the linked one.
+type LinkType string
+
+const (
+	LinkDuplicateOf  LinkType = "duplicate-of"
+	LinkDuplicatedBy LinkType = "duplicated-by"
+	LinkChildOf      LinkType = "child-of"
+	LinkParentOf     LinkType = "parent-of"
+	LinkCausedBy     LinkType = "caused-by"
+	LinkCauses       LinkType = "causes"
+	LinkRelated      LinkType = "related"
+)
+
+// LinkTypes lists the link types a client creates; the others are their inverses
+var LinkTypes = []LinkType{LinkDuplicateOf, LinkChildOf, LinkCausedBy, LinkRelated}
+
+// ParseLinkType parses a link type a client may create, such as "child-of"
+func ParseLinkType(value string) (LinkType, error) {
+	return parseEnum("link type", value, LinkTypes)
+}
+
+// Inverse returns the type of the same link seen from the linked ticket
+func (t LinkType) Inverse() LinkType {
+	switch t {
+	case LinkDuplicateOf:
+		return LinkDuplicatedBy
+	case LinkDuplicatedBy:
+		return LinkDuplicateOf
+	case LinkChildOf:
+		return LinkParentOf
+	case LinkParentOf:
+		return LinkChildOf
+	case LinkCausedBy:
+		return LinkCauses
+	case LinkCauses:
+		return LinkCausedBy
+	}
+	return t
+}
+
+// IsCreatable reports whether t is one of LinkTypes, as opposed to an inverse
+func (t LinkType) IsCreatable() bool {
+	_, err := ParseLinkType(string(t))
+	return err == nil
+}
+
+// TicketLink links a ticket to another one
+type TicketLink struct {
+	Type      LinkType `json:"type" dynamodbav:"type"`
+	TicketID  string   `json:"ticketId" dynamodbav:"ticketId"`
+	CreatedAt string   `json:"createdAt" dynamodbav:"createdAt"`
+}
+
+// Link returns the ticket's link to another ticket, if any. Two tickets are linked
+// at most once.
+func (t IncidentTicket) Link(ticketID string) (TicketLink, bool) {
+	for _, link := range t.Links {
+		if link.TicketID == ticketID {
+			return link, true
+		}
+	}
+	return TicketLink{}, false
+}
+
+// LinkedIDs lists the IDs of the tickets linked with the given type
+func (t IncidentTicket) LinkedIDs(linkType LinkType) []string {
+	var ids []string
+	for _, link := range t.Links {
+		if link.Type == linkType {
+			ids = append(ids, link.TicketID)
+		}
+	}
+	return ids
+}
+
+// LinkGraph is the part of the link graph reachable from a ticket
+type LinkGraph struct {
+	Root  string     `json:"root"`
+	Nodes []LinkNode `json:"nodes"`
+	Edges []LinkEdge `json:"edges"`
+	// Truncated is set when tickets further than the requested depth were left out
+	Truncated bool `json:"truncated"`
+}
+
+// LinkNode summarizes a ticket of a link graph
+type LinkNode struct {
+	ID           string       `json:"id"`
+	Title        string       `json:"title"`
+	Status       Status       `json:"status"`
+	Severity     Severity     `json:"severity"`
+	IncidentType IncidentType `json:"incident_type"`
+	Team         string       `json:"team,omitempty"`
+	CreatedAt    string       `json:"createdAt"`
+	// Depth is the number of links between the ticket and the root
+	Depth int `json:"depth"`
+}
+
+// LinkEdge is a link of a graph in the direction it was created, e.g. from the
+// child to its parent
+type LinkEdge struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Type LinkType `json:"type"`
+}
@@ -0,0 +1,58 @@
+package models
+
+// ActionItemStatus tracks a postmortem action item to completion
+type ActionItemStatus string
+
+const (
+	ActionItemOpen       ActionItemStatus = "open"
+	ActionItemInProgress ActionItemStatus = "in-progress"
+	ActionItemDone       ActionItemStatus = "done"
+)
+
+// IsValid reports whether the status is a known action item status
+func (s ActionItemStatus) IsValid() bool {
+	switch s {
+	case ActionItemOpen, ActionItemInProgress, ActionItemDone:
+		return true
+	}
+	return false
+}
+
+// ActionItem is a follow-up task coming out of a postmortem
+type ActionItem struct {
+	ID          string           `json:"id" dynamodbav:"id"`
+	Description string           `json:"description" dynamodbav:"description"`
+	Owner       string           `json:"owner" dynamodbav:"owner"`
+	DueDate     string           `json:"dueDate,omitempty" dynamodbav:"dueDate,omitempty"`
+	Status      ActionItemStatus `json:"status" dynamodbav:"status"`
+	CreatedAt   string           `json:"createdAt" dynamodbav:"createdAt"`
+	CompletedAt string           `json:"completedAt,omitempty" dynamodbav:"completedAt,omitempty"`
+}
+
+// Postmortem holds the written analysis of an incident and its action items.
+// There is at most one postmortem per ticket, keyed by the ticket ID.
+type Postmortem struct {
+	TicketID string `json:"ticketId" dynamodbav:"ticketId"`
+	// Team is copied from the ticket; postmortems without one belong to the default team
+	Team           string       `json:"team,omitempty" dynamodbav:"team,omitempty"`
+	Summary        string       `json:"summary" dynamodbav:"summary"`
+	RootCause      string       `json:"rootCause" dynamodbav:"rootCause"`
+	LessonsLearned string       `json:"lessonsLearned" dynamodbav:"lessonsLearned"`
+	Author         string       `json:"author" dynamodbav:"author"`
+	ActionItems    []ActionItem `json:"actionItems" dynamodbav:"actionItems"`
+	Completed      bool         `json:"completed" dynamodbav:"completed"`
+	Version        int          `json:"version" dynamodbav:"version"`
+	CreatedAt      string       `json:"createdAt" dynamodbav:"createdAt"`
+	UpdatedAt      string       `json:"updatedAt" dynamodbav:"updatedAt"`
+}
+
+// OpenActionItems counts the action items that are not done yet
+func (p Postmortem) OpenActionItems() int {
+	open := 0
+	for _, item := range p.ActionItems {
+		if item.Status != ActionItemDone {
+			open++
+		}
+	}
+	return open
+}
@@ -2,25 +2,58 @@ package models
 
 // IncidentTicket represents an incident ticket in the system
 type IncidentTicket struct {
-	ID               string   `json:"id" dynamodbav:"id"`
-	Title            string   `json:"title" dynamodbav:"title"`
-	Description      string   `json:"description" dynamodbav:"description"`
-	Report           string   `json:"report" dynamodbav:"report"`
-	Suggestions      []string `json:"suggestions,omitempty" dynamodbav:"suggestions,omitempty"`
-	Severity         string   `json:"severity" dynamodbav:"severity"`
-	Category         string   `json:"category" dynamodbav:"category"`
-	IncidentType     string   `json:"insident_type" dynamodbav:"insident_type"`
-	Environment      string   `json:"environment" dynamodbav:"environment"`
-	ActionStatus     string   `json:"actionStatus" dynamodbav:"actionStatus"`
-	Status           string   `json:"status" dynamodbav:"status"`
-	Reporter         string   `json:"reporter" dynamodbav:"reporter"`
-	CreatedAt        string   `json:"createdAt" dynamodbav:"createdAt"`
-	ResolutionTime   *string  `json:"resolutionTime,omitempty" dynamodbav:"resolutionTime,omitempty"`
-	EmailSent        bool     `json:"emailSent" dynamodbav:"emailSent"`
-	EmailSentAt      *string  `json:"emailSentAt,omitempty" dynamodbav:"emailSentAt,omitempty"`
-	ActionTaken      *string  `json:"actionTaken,omitempty" dynamodbav:"actionTaken,omitempty"`
-	AffectedServices []string `json:"affectedServices,omitempty" dynamodbav:"affectedServices,omitempty"`
-	Tags             []string `json:"tags,omitempty" dynamodbav:"tags,omitempty"`
+	ID           string       `json:"id" dynamodbav:"id"`
+	Title        string       `json:"title" dynamodbav:"title"`
+	Description  string       `json:"description" dynamodbav:"description"`
+	Report       string       `json:"report" dynamodbav:"report"`
+	Suggestions  []string     `json:"suggestions,omitempty" dynamodbav:"suggestions,omitempty"`
+	Severity     Severity     `json:"severity" dynamodbav:"severity"`
+	Category     Category     `json:"category" dynamodbav:"category"`
+	IncidentType IncidentType `json:"incident_type" dynamodbav:"incident_type"`
+	Environment  Environment  `json:"environment" dynamodbav:"environment"`
+	ActionStatus ActionStatus `json:"actionStatus" dynamodbav:"actionStatus"`
+	Status       Status       `json:"status" dynamodbav:"status"`
+	Reporter     string       `json:"reporter" dynamodbav:"reporter"`
+	// Team owns the ticket; tickets without one belong to the default team
+	Team             string      `json:"team,omitempty" dynamodbav:"team,omitempty"`
+	CreatedAt        string      `json:"createdAt" dynamodbav:"createdAt"`
+	ResolutionTime   *string     `json:"resolutionTime,omitempty" dynamodbav:"resolutionTime,omitempty"`
+	EmailSent        bool        `json:"emailSent" dynamodbav:"emailSent"`
+	EmailSentAt      *string     `json:"emailSentAt,omitempty" dynamodbav:"emailSentAt,omitempty"`
+	ActionTaken      *string     `json:"actionTaken,omitempty" dynamodbav:"actionTaken,omitempty"`
+	AffectedServices []string    `json:"affectedServices,omitempty" dynamodbav:"affectedServices,omitempty"`
+	Tags             []string    `json:"tags,omitempty" dynamodbav:"tags,omitempty"`
+	ReportMeta       *ReportMeta `json:"reportMeta,omitempty" dynamodbav:"reportMeta,omitempty"`
+	// Links relate the ticket to others, e.g. the child tickets of an outage
+	Links []TicketLink `json:"links,omitempty" dynamodbav:"links,omitempty"`
+	// DeletedAt is set while the ticket is soft deleted
+	DeletedAt *string `json:"deletedAt,omitempty" dynamodbav:"deletedAt,omitempty"`
+	// ArchivedAt is set once the ticket has been moved to the archive; the table item
+	// only remains until its TTL expires
+	ArchivedAt *string `json:"archivedAt,omitempty" dynamodbav:"archivedAt,omitempty"`
+}
+
+// Removed reports whether the ticket was soft deleted or archived. Removed tickets
+// are left out of lists.
+func (t IncidentTicket) Removed() bool {
+	return t.DeletedAt != nil || t.ArchivedAt != nil
+}
+
+// ReportMeta records how the current report was generated, so outputs of
+// different prompt versions can be compared
+type ReportMeta struct {
+	PromptVersion string `json:"promptVersion" dynamodbav:"promptVersion"`
+	Model         string `json:"model" dynamodbav:"model"`
+	GeneratedAt   string `json:"generatedAt" dynamodbav:"generatedAt"`
+}
+
+// ReportResult is the outcome of a report regeneration
+type ReportResult struct {
+	TicketID    string     `json:"ticketId"`
+	Report      string     `json:"report"`
+	Suggestions []string   `json:"suggestions"`
+	Meta        ReportMeta `json:"meta"`
+	Saved       bool       `json:"saved"`
 }
 
 // TicketFilters represents filters for querying tickets
@@ -32,6 +65,7 @@ type TicketFilters struct {
 	Search       string `json:"search,omitempty"`
 	Status       string `json:"status,omitempty"`
 	IncidentType string `json:"incidentType,omitempty"`
+	Team         string `json:"team,omitempty"`
 }
 
 // APIResponse represents a standard API response
@@ -50,3 +84,26 @@ type PaginationResponse struct {
 	Limit      int         `json:"limit"`
 	TotalPages int         `json:"totalPages"`
 }
+
+// severityRanks orders severities from least to most urgent
+var severityRanks = map[Severity]int{
+	"low":      1,
+	"medium":   2,
+	"high":     3,
+	"critical": 4,
+}
+
+// SeverityRank returns the relative urgency of a severity, 0 when unknown
+func SeverityRank(severity Severity) int {
+	return severityRanks[severity]
+}
+
+// IsResolvedStatus reports whether a status means the incident is over.
+// The lambdas write "resolved" while the dashboard uses "solved" and "closed".
+func IsResolvedStatus(status Status) bool {
+	switch status {
+	case "resolved", "solved", "closed":
+		return true
+	}
+	return false
+}
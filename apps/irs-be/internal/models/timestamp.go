@@ -0,0 +1,31 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimestampLayout matches the naive UTC ISO-8601 format written by the lambdas
+// (Python's datetime.utcnow().isoformat())
+const TimestampLayout = "2006-01-02T15:04:05.000000"
+
+// FormatTimestamp formats a time the same way the lambdas store timestamps
+func FormatTimestamp(t time.Time) string {
+	return t.UTC().Format(TimestampLayout)
+}
+
+// ParseTimestamp parses timestamps written either by the lambdas or by RFC 3339 clients
+func ParseTimestamp(value string) (time.Time, error) {
+	layouts := []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", value)
+}
@@ -0,0 +1,55 @@
+package models
+
+// WebhookSubscription represents an outbound webhook registered by another team
+type WebhookSubscription struct {
+	ID string `json:"id" dynamodbav:"id"`
+	// Team receives only the events of its own tickets; subscriptions without one
+	// belong to the default team
+	Team        string            `json:"team,omitempty" dynamodbav:"team,omitempty"`
+	URL         string            `json:"url" dynamodbav:"url"`
+	Description string            `json:"description,omitempty" dynamodbav:"description,omitempty"`
+	Events      []TicketEventType `json:"events" dynamodbav:"events"`
+	Secret      string            `json:"secret,omitempty" dynamodbav:"secret"`
+	Active      bool              `json:"active" dynamodbav:"active"`
+	CreatedAt   string            `json:"createdAt" dynamodbav:"createdAt"`
+}
+
+// Matches reports whether the subscription wants the given event type
+func (s WebhookSubscription) Matches(eventType TicketEventType) bool {
+	if !s.Active {
+		return false
+	}
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus is the state of a single webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending      WebhookDeliveryStatus = "pending"
+	WebhookDeliveryRetrying     WebhookDeliveryStatus = "retrying"
+	WebhookDeliverySucceeded    WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryDeadLettered WebhookDeliveryStatus = "dead_lettered"
+)
+
+// WebhookDelivery is an entry in a subscription's delivery log
+type WebhookDelivery struct {
+	SubscriptionID string                `json:"subscriptionId" dynamodbav:"subscriptionId"`
+	ID             string                `json:"id" dynamodbav:"id"`
+	EventID        string                `json:"eventId" dynamodbav:"eventId"`
+	EventType      TicketEventType       `json:"eventType" dynamodbav:"eventType"`
+	TicketID       string                `json:"ticketId" dynamodbav:"ticketId"`
+	Status         WebhookDeliveryStatus `json:"status" dynamodbav:"status"`
+	Attempts       int                   `json:"attempts" dynamodbav:"attempts"`
+	LastStatusCode int                   `json:"lastStatusCode,omitempty" dynamodbav:"lastStatusCode,omitempty"`
+	LastError      string                `json:"lastError,omitempty" dynamodbav:"lastError,omitempty"`
+	NextAttemptAt  string                `json:"nextAttemptAt,omitempty" dynamodbav:"nextAttemptAt,omitempty"`
+	CreatedAt      string                `json:"createdAt" dynamodbav:"createdAt"`
+	UpdatedAt      string                `json:"updatedAt" dynamodbav:"updatedAt"`
+	Payload        string                `json:"-" dynamodbav:"payload"`
+}
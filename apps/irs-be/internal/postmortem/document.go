@@ -0,0 +1,143 @@
+package postmortem
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"irs-be/internal/models"
+)
+
+// TimelineEntry is a single point on the incident timeline
+type TimelineEntry struct {
+	At    string
+	Event string
+}
+
+// Impact summarizes the blast radius and duration of an incident
+type Impact struct {
+	Environment      models.Environment
+	Severity         models.Severity
+	AffectedServices []string
+	ServiceCount     int
+	Resolved         bool
+	Duration         string
+}
+
+// Document is the data passed to postmortem templates
+type Document struct {
+	Ticket      models.IncidentTicket
+	Postmortem  *models.Postmortem
+	Timeline    []TimelineEntry
+	Impact      Impact
+	ActionTaken string
+	GeneratedAt string
+}
+
+// Build assembles a postmortem document from a ticket and, when one has been
+// written, its postmortem record
+func Build(ticket models.IncidentTicket, pm *models.Postmortem, now time.Time) Document {
+	doc := Document{
+		Ticket:      ticket,
+		Postmortem:  pm,
+		Timeline:    buildTimeline(ticket, pm),
+		Impact:      buildImpact(ticket, now),
+		GeneratedAt: models.FormatTimestamp(now),
+	}
+	if ticket.ActionTaken != nil {
+		doc.ActionTaken = *ticket.ActionTaken
+	}
+	return doc
+}
+
+func buildTimeline(ticket models.IncidentTicket, pm *models.Postmortem) []TimelineEntry {
+	entries := []TimelineEntry{
+		{At: ticket.CreatedAt, Event: fmt.Sprintf("Incident detected and ticket opened by %s", ticket.Reporter)},
+	}
+	if ticket.EmailSentAt != nil {
+		entries = append(entries, TimelineEntry{At: *ticket.EmailSentAt, Event: "Notification email sent"})
+	}
+	if ticket.ReportMeta != nil {
+		entries = append(entries, TimelineEntry{
+			At:    ticket.ReportMeta.GeneratedAt,
+			Event: fmt.Sprintf("Incident report generated (%s, prompt %s)", ticket.ReportMeta.Model, ticket.ReportMeta.PromptVersion),
+		})
+	}
+	if ticket.ResolutionTime != nil {
+		event := "Incident resolved"
+		if ticket.ActionTaken != nil {
+			event += ": " + *ticket.ActionTaken
+		}
+		entries = append(entries, TimelineEntry{At: *ticket.ResolutionTime, Event: event})
+	}
+	if pm != nil {
+		for _, item := range pm.ActionItems {
+			if item.CompletedAt != "" {
+				entries = append(entries, TimelineEntry{At: item.CompletedAt, Event: "Action item completed: " + item.Description})
+			}
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		ti, erri := models.ParseTimestamp(entries[i].At)
+		tj, errj := models.ParseTimestamp(entries[j].At)
+		if erri != nil || errj != nil {
+			return entries[i].At < entries[j].At
+		}
+		return ti.Before(tj)
+	})
+	return entries
+}
+
+func buildImpact(ticket models.IncidentTicket, now time.Time) Impact {
+	impact := Impact{
+		Environment:      ticket.Environment,
+		Severity:         ticket.Severity,
+		AffectedServices: ticket.AffectedServices,
+		ServiceCount:     len(ticket.AffectedServices),
+		Resolved:         ticket.ResolutionTime != nil,
+		Duration:         "unknown",
+	}
+
+	start, err := models.ParseTimestamp(ticket.CreatedAt)
+	if err != nil {
+		return impact
+	}
+
+	end := now
+	if ticket.ResolutionTime != nil {
+		if resolved, err := models.ParseTimestamp(*ticket.ResolutionTime); err == nil {
+			end = resolved
+		}
+	}
+
+	impact.Duration = FormatDuration(end.Sub(start))
+	if !impact.Resolved {
+		impact.Duration += " (ongoing)"
+	}
+	return impact
+}
+
+// FormatDuration renders a duration as days, hours and minutes, e.g. "1d 2h 5m"
+func FormatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "less than a minute"
+	}
+
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	return strings.Join(parts, " ")
+}
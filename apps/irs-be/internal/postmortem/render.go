@@ -0,0 +1,97 @@
+package postmortem
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Template file names, both for the embedded defaults and in POSTMORTEM_TEMPLATE_DIR
+const (
+	markdownTemplateName = "postmortem.md.tmpl"
+	htmlTemplateName     = "postmortem.html.tmpl"
+)
+
+// Supported output formats
+const (
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+)
+
+var templateFuncs = map[string]interface{}{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+}
+
+// Renderer renders postmortem documents as markdown or HTML
+type Renderer struct {
+	markdown *texttemplate.Template
+	html     *htmltemplate.Template
+}
+
+// NewRenderer loads the templates. Files found in templateDir override the embedded defaults.
+func NewRenderer(templateDir string) (*Renderer, error) {
+	mdSource, err := loadTemplate(templateDir, markdownTemplateName)
+	if err != nil {
+		return nil, err
+	}
+	markdown, err := texttemplate.New(markdownTemplateName).Funcs(templateFuncs).Parse(mdSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", markdownTemplateName, err)
+	}
+
+	htmlSource, err := loadTemplate(templateDir, htmlTemplateName)
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.New(htmlTemplateName).Funcs(templateFuncs).Parse(htmlSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", htmlTemplateName, err)
+	}
+
+	return &Renderer{markdown: markdown, html: html}, nil
+}
+
+func loadTemplate(templateDir, name string) (string, error) {
+	if templateDir != "" {
+		data, err := os.ReadFile(filepath.Join(templateDir, name))
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read template %s: %v", name, err)
+		}
+	}
+
+	data, err := templateFS.ReadFile("templates/" + name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read embedded template %s: %v", name, err)
+	}
+	return string(data), nil
+}
+
+// Render renders the document in the requested format
+func (r *Renderer) Render(doc Document, format string) (string, error) {
+	var out strings.Builder
+	var err error
+
+	switch format {
+	case FormatMarkdown:
+		err = r.markdown.Execute(&out, doc)
+	case FormatHTML:
+		err = r.html.Execute(&out, doc)
+	default:
+		return "", fmt.Errorf("unsupported postmortem format %q", format)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to render postmortem: %v", err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,164 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the dependency while the breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	// StateClosed lets every call through
+	StateClosed State = iota
+	// StateHalfOpen lets a single probe call through to test recovery
+	StateHalfOpen
+	// StateOpen rejects calls until the open timeout has elapsed
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker is a consecutive-failure circuit breaker. After threshold failures in a
+// row it opens and fails fast; once openTimeout has passed it lets one probe
+// through, closing again on success and reopening on failure.
+type Breaker struct {
+	threshold   int
+	openTimeout time.Duration
+	onChange    func(State)
+	now         func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreaker creates a closed breaker. onChange, when set, is called on every
+// state transition, outside the breaker lock.
+func NewBreaker(threshold int, openTimeout time.Duration, onChange func(State)) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Breaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		onChange:    onChange,
+		now:         time.Now,
+	}
+}
+
+// State returns the current state, moving an expired open breaker to half-open
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// RetryAfter returns how long until an open breaker lets a probe through
+func (b *Breaker) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != StateOpen {
+		return 0
+	}
+	if remaining := b.openTimeout - b.now().Sub(b.openedAt); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be followed
+// by exactly one Success or Failure.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	changed := false
+	defer func() {
+		b.mu.Unlock()
+		if changed {
+			b.notify(StateHalfOpen)
+		}
+	}()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = StateHalfOpen
+		changed = true
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success records a successful call
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	changed := b.state != StateClosed
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+	b.mu.Unlock()
+
+	if changed {
+		b.notify(StateClosed)
+	}
+}
+
+// Failure records a failed call
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	b.failures++
+	opened := false
+	if b.state == StateHalfOpen || (b.state == StateClosed && b.failures >= b.threshold) {
+		b.state = StateOpen
+		b.openedAt = b.now()
+		opened = true
+	}
+	b.probing = false
+	b.mu.Unlock()
+
+	if opened {
+		b.notify(StateOpen)
+	}
+}
+
+// Release ends an allowed call without counting it either way, e.g. when the
+// caller gave up or the error says nothing about the dependency's health
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+func (b *Breaker) notify(state State) {
+	if b.onChange != nil {
+		b.onChange(state)
+	}
+}
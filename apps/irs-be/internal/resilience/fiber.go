@@ -0,0 +1,80 @@
+package resilience
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// StaleHeader marks responses served from the last known good result while
+// DynamoDB is unavailable; its value is the age of the data in seconds
+const StaleHeader = "X-IRS-Stale-Age"
+
+// outcome collects what the resilience layer did while serving a request
+type outcome struct {
+	mu         sync.Mutex
+	staleAge   time.Duration
+	stale      bool
+	rejected   bool
+	retryAfter time.Duration
+}
+
+type outcomeKey struct{}
+
+// MarkStale records that the request was answered with data of the given age
+func MarkStale(ctx context.Context, age time.Duration) {
+	if o, ok := ctx.Value(outcomeKey{}).(*outcome); ok {
+		o.mu.Lock()
+		if !o.stale || age > o.staleAge {
+			o.staleAge = age
+		}
+		o.stale = true
+		o.mu.Unlock()
+	}
+}
+
+// MarkRejected records that a call was refused by an open circuit breaker
+func MarkRejected(ctx context.Context, retryAfter time.Duration) {
+	if o, ok := ctx.Value(outcomeKey{}).(*outcome); ok {
+		o.mu.Lock()
+		o.rejected = true
+		o.retryAfter = retryAfter
+		o.mu.Unlock()
+	}
+}
+
+// IsStale reports whether the request has been answered with stale data so far
+func IsStale(ctx context.Context) bool {
+	if o, ok := ctx.Value(outcomeKey{}).(*outcome); ok {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		return o.stale
+	}
+	return false
+}
+
+// Middleware reports the resilience layer's decisions to the client: stale
+// responses carry StaleHeader, and server errors caused by an open circuit breaker
+// become 503 Service Unavailable with Retry-After
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		o := &outcome{}
+		c.SetUserContext(context.WithValue(c.UserContext(), outcomeKey{}, o))
+
+		err := c.Next()
+
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		if o.stale {
+			c.Set(StaleHeader, strconv.Itoa(int(o.staleAge.Seconds())))
+		}
+		if o.rejected && c.Response().StatusCode() >= fiber.StatusInternalServerError {
+			c.Status(fiber.StatusServiceUnavailable)
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(o.retryAfter.Seconds())+1))
+		}
+		return err
+	}
+}
@@ -0,0 +1,115 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/aws/smithy-go"
+)
+
+// RetryPolicy bounds retries of a single logical call
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Backoff returns the delay before the given retry (1-based), using full jitter
+// so concurrent callers do not retry in lockstep
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	delay := p.BaseDelay << (retry - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+// retryableCodes are AWS error codes for throttling and transient server faults
+var retryableCodes = map[string]bool{
+	"ProvisionedThroughputExceededException": true,
+	"ThrottlingException":                    true,
+	"RequestLimitExceeded":                   true,
+	"InternalServerError":                    true,
+	"ServiceUnavailable":                     true,
+	"TransactionInProgressException":         true,
+}
+
+// IsRetryable reports whether an error is throttling or a transient fault that a
+// later attempt may not hit
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return retryableCodes[apiErr.ErrorCode()]
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// IsDependencyFailure reports whether an error says the dependency is unhealthy,
+// as opposed to a rejected request (validation, failed conditions) or a caller
+// that gave up. Only these errors count against the circuit breaker.
+func IsDependencyFailure(err error) bool {
+	return IsRetryable(err) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Do runs fn through the breaker, retrying retryable errors with jittered backoff.
+// onRetry, when set, is called before every retry.
+func Do(ctx context.Context, breaker *Breaker, policy RetryPolicy, onRetry func(err error), fn func(ctx context.Context) error) error {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if allowErr := breaker.Allow(); allowErr != nil {
+			if err != nil {
+				return err
+			}
+			return allowErr
+		}
+
+		err = fn(ctx)
+		switch {
+		case err == nil:
+			breaker.Success()
+			return nil
+		case IsDependencyFailure(err):
+			breaker.Failure()
+		case errors.Is(err, context.Canceled):
+			breaker.Release()
+			return err
+		default:
+			// DynamoDB answered, so it is healthy even though it rejected the request
+			breaker.Success()
+			return err
+		}
+
+		if !IsRetryable(err) || attempt == attempts {
+			return err
+		}
+		if onRetry != nil {
+			onRetry(err)
+		}
+
+		timer := time.NewTimer(policy.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+	return err
+}
@@ -0,0 +1,127 @@
+package retention
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"irs-be/internal/backup"
+	"irs-be/internal/models"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// ExpiresAttribute is the TTL attribute of archived table items, in epoch seconds
+	ExpiresAttribute = "expiresAt"
+
+	// indexWriters bounds the index objects written at once
+	indexWriters = 8
+)
+
+// Archive stores archived tickets in a Store. Tickets are written in batches, each
+// a backup archive that irs-be admin restore can load, and an index object per
+// ticket points at the batch holding it so tickets stay retrievable by ID.
+type Archive struct {
+	store Store
+	table string
+}
+
+// indexEntry is the index object of one archived ticket
+type indexEntry struct {
+	Object     string `json:"object"`
+	ArchivedAt string `json:"archivedAt"`
+}
+
+// NewArchive creates an archive of the tickets of table
+func NewArchive(store Store, table string) *Archive {
+	return &Archive{store: store, table: table}
+}
+
+// ObjectKey names the object of one batch of an archive run, grouped by day
+func ObjectKey(at time.Time, run string, batch int) string {
+	return fmt.Sprintf("tickets/%s/%s-%04d.ndjson", at.UTC().Format("2006/01/02"), run, batch)
+}
+
+// Write stores a batch of tickets under the object key, then their index objects.
+// The ticket table should only be marked once Write succeeded, so a ticket is never
+// archived without being retrievable.
+func (a *Archive) Write(ctx context.Context, object string, tickets []models.IncidentTicket, archivedAt time.Time) error {
+	var buf bytes.Buffer
+	writer, err := backup.NewWriter(&buf, a.table)
+	for i := 0; err == nil && i < len(tickets); i++ {
+		err = writer.Write(tickets[i])
+	}
+	if err != nil {
+		return err
+	}
+	if err := a.store.Put(ctx, object, buf.Bytes()); err != nil {
+		return err
+	}
+
+	entry, err := json.Marshal(indexEntry{Object: object, ArchivedAt: models.FormatTimestamp(archivedAt)})
+	if err != nil {
+		return err
+	}
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(indexWriters)
+	for _, ticket := range tickets {
+		key := indexKey(ticket.ID)
+		group.Go(func() error {
+			return a.store.Put(ctx, key, entry)
+		})
+	}
+	return group.Wait()
+}
+
+// Get returns an archived ticket with ArchivedAt set, or nil when the ticket was
+// never archived
+func (a *Archive) Get(ctx context.Context, id string) (*models.IncidentTicket, error) {
+	data, err := a.store.Get(ctx, indexKey(id))
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry indexEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("invalid archive index for %s: %v", id, err)
+	}
+
+	data, err = a.store.Get(ctx, entry.Object)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := backup.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", entry.Object, err)
+	}
+	for {
+		ticket, err := reader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("archive object %s does not hold ticket %s", entry.Object, id)
+		}
+		var recordErr *backup.RecordError
+		if errors.As(err, &recordErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ticket.ID == id {
+			ticket.ArchivedAt = &entry.ArchivedAt
+			return &ticket, nil
+		}
+	}
+}
+
+// indexKey escapes the ID so any ID maps to a single object below index/
+func indexKey(id string) string {
+	return "index/" + url.PathEscape(id) + ".json"
+}
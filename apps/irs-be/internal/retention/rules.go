@@ -0,0 +1,125 @@
+package retention
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"irs-be/internal/models"
+)
+
+// Deleted is the rule status matching soft deleted tickets, whatever their status
+const Deleted = "deleted"
+
+// Rule archives the tickets in a status once they are older than MaxAge
+type Rule struct {
+	// Status is a ticket status or Deleted
+	Status string
+	// MaxAge is counted from the resolution time, or the creation time of tickets
+	// never resolved; for Deleted it is counted from the deletion
+	MaxAge time.Duration
+}
+
+func (r Rule) String() string {
+	return fmt.Sprintf("%s:%s", r.Status, formatAge(r.MaxAge))
+}
+
+var ruleAge = regexp.MustCompile(`^(\d+)([hdw])$`)
+
+// ParseRules parses a comma-separated list of status:age rules such as
+// "closed:180d,resolved:365d,deleted:30d". Ages are in hours, days or weeks.
+func ParseRules(value string) ([]Rule, error) {
+	var rules []Rule
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		status, age, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid retention rule %q, use status:age such as closed:180d", part)
+		}
+		status = strings.ToLower(strings.TrimSpace(status))
+		if status != Deleted {
+			if _, err := models.ParseStatus(status); err != nil {
+				return nil, fmt.Errorf("invalid retention rule %q: %v", part, err)
+			}
+		}
+		if seen[status] {
+			return nil, fmt.Errorf("duplicate retention rule for %s", status)
+		}
+		seen[status] = true
+
+		m := ruleAge.FindStringSubmatch(strings.TrimSpace(age))
+		if m == nil {
+			return nil, fmt.Errorf("invalid age in retention rule %q, use hours, days or weeks such as 72h, 180d or 4w", part)
+		}
+		n, _ := strconv.Atoi(m[1])
+		unit := map[string]time.Duration{"h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[m[2]]
+		rules = append(rules, Rule{Status: status, MaxAge: time.Duration(n) * unit})
+	}
+	return rules, nil
+}
+
+// Match returns the rule that archives the ticket at now, if any. Deleted tickets
+// are only matched by the Deleted rule, so undeleting stays possible until it applies.
+func Match(rules []Rule, ticket models.IncidentTicket, now time.Time) (Rule, bool) {
+	if ticket.ArchivedAt != nil {
+		return Rule{}, false
+	}
+	for _, rule := range rules {
+		var since *string
+		switch {
+		case ticket.DeletedAt != nil:
+			if rule.Status != Deleted {
+				continue
+			}
+			since = ticket.DeletedAt
+		case rule.Status == string(ticket.Status):
+			since = ticket.ResolutionTime
+			if since == nil {
+				since = &ticket.CreatedAt
+			}
+		default:
+			continue
+		}
+
+		at, err := models.ParseTimestamp(*since)
+		if err == nil && now.Sub(at) > rule.MaxAge {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// formatAge writes an age in the largest unit ParseRules reads that divides it
+func formatAge(age time.Duration) string {
+	switch day := 24 * time.Hour; {
+	case age%(7*day) == 0:
+		return fmt.Sprintf("%dw", age/(7*day))
+	case age%day == 0:
+		return fmt.Sprintf("%dd", age/day)
+	}
+	return fmt.Sprintf("%dh", age/time.Hour)
+}
+
+// Report summarizes a retention run
+type Report struct {
+	Rules  []string `json:"rules"`
+	DryRun bool     `json:"dryRun"`
+	// Scanned counts the tickets read, Matched the ones a rule applies to
+	Scanned int `json:"scanned"`
+	Matched int `json:"matched"`
+	// Archived counts the tickets written to the archive and marked in the table
+	Archived int `json:"archived"`
+	// Conflicts counts tickets changed between the scan and marking them; they stay
+	// in the table and are considered again by the next run
+	Conflicts int      `json:"conflicts"`
+	Failed    int      `json:"failed"`
+	Objects   []string `json:"objects"`
+	Errors    []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,129 @@
+package retention
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// S3Store keeps archive objects in an S3 bucket, or any store speaking the S3 API
+// such as MinIO. Requests use path-style addressing so custom endpoints need no
+// wildcard DNS.
+type S3Store struct {
+	client      *http.Client
+	signer      *v4.Signer
+	credentials aws.CredentialsProvider
+	region      string
+	endpoint    string
+	bucket      string
+	prefix      string
+}
+
+// NewS3Store creates a store for the objects below prefix in bucket. An empty
+// endpoint uses the regional AWS endpoint.
+func NewS3Store(awsCfg aws.Config, endpoint, bucket, prefix string) *S3Store {
+	region := awsCfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+
+	return &S3Store{
+		client: &http.Client{Timeout: time.Minute},
+		// Object keys are escaped once, S3 does not expect the path escaped again
+		signer:      v4.NewSigner(func(o *v4.SignerOptions) { o.DisableURIPathEscaping = true }),
+		credentials: awsCfg.Credentials,
+		region:      region,
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		bucket:      bucket,
+		prefix:      prefix,
+	}
+}
+
+// Put uploads an object
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to write %s: %s", key, s3Error(resp))
+	}
+	return nil
+}
+
+// Get downloads an object
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", key, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode/100 != 2:
+		return nil, fmt.Errorf("failed to read %s: %s", key, s3Error(resp))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", key, err)
+	}
+	return data, nil
+}
+
+// do sends a signed request for the object with the key
+func (s *S3Store) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	segments := []string{s.bucket}
+	if s.prefix != "" {
+		segments = append(segments, strings.Split(s.prefix, "/")...)
+	}
+	segments = append(segments, strings.Split(key, "/")...)
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint+"/"+strings.Join(segments, "/"), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+
+	hash := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(hash[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %v", err)
+	}
+	if err := s.signer.SignHTTP(ctx, creds, req, payloadHash, "s3", s.region, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to sign request: %v", err)
+	}
+
+	return s.client.Do(req)
+}
+
+// s3Error describes a failed response, including the start of its error document
+func s3Error(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return strings.TrimSpace(fmt.Sprintf("%s %s", resp.Status, body))
+}
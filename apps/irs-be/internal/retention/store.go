@@ -0,0 +1,46 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// ErrNotFound is returned by Store.Get when no object has the key
+var ErrNotFound = errors.New("archive object not found")
+
+// Store keeps archive objects under slash-separated keys
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// Open opens the store an archive URL points at: file:///path for a directory or
+// s3://bucket/prefix for a bucket. endpoint overrides the S3 endpoint for
+// S3-compatible stores; loadAWS is only called for s3 URLs.
+func Open(ctx context.Context, rawURL, endpoint string, loadAWS func(ctx context.Context) (aws.Config, error)) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive URL %q: %v", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		// file:///var/lib/irs is absolute, file://archive is relative to the working directory
+		return NewFileStore(u.Host + u.Path), nil
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid archive URL %q: missing bucket", rawURL)
+		}
+		awsCfg, err := loadAWS(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return NewS3Store(awsCfg, endpoint, u.Host, strings.Trim(u.Path, "/")), nil
+	}
+	return nil, fmt.Errorf("invalid archive URL %q, use file:///path or s3://bucket/prefix", rawURL)
+}
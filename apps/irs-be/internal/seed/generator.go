@@ -0,0 +1,299 @@
+package seed
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"irs-be/internal/models"
+)
+
+// Options controls the generated history
+type Options struct {
+	// Seed makes the history reproducible: the same options generate the same tickets
+	Seed int64
+	// End is when the history ends; tickets created shortly before it are still open
+	End time.Time
+	// Days is the length of the history
+	Days int
+	// Rate scales the volume; at 1 there are about 12 incidents a day
+	Rate float64
+}
+
+// reporters are the people who file incidents by hand
+var reporters = []string{
+	"oncall@company.com", "sre-team@company.com", "qa-team@company.com", "dev-lead@company.com", "support@company.com",
+}
+
+// Generate synthesizes an incident history, oldest ticket first. Incidents arrive
+// in bursts of related alarms, follow each environment's daily rhythm and type mix,
+// and move through the lifecycle the lambdas and the dashboard give them: automatic
+// remediation that resolves or escalates, then manual resolution that takes longer
+// the lower the severity. Tickets whose lifecycle would end after End are left open.
+func Generate(opts Options) []models.IncidentTicket {
+	g := &generator{
+		r:         rand.New(rand.NewSource(opts.Seed)),
+		end:       opts.End.UTC(),
+		instances: map[string]string{},
+	}
+
+	start := g.end.AddDate(0, 0, -opts.Days).Truncate(24 * time.Hour)
+	for day := start; day.Before(g.end); day = day.Add(24 * time.Hour) {
+		volume := opts.Rate * incidentsPerDay
+		if g.r.Float64() < stormChance {
+			volume *= stormFactor
+		}
+		for _, env := range environments {
+			envVolume := volume * env.share
+			if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+				envVolume *= env.weekend
+			}
+
+			var hourlyTotal float64
+			for _, weight := range env.hourly {
+				hourlyTotal += weight
+			}
+			for hour, weight := range env.hourly {
+				// Each episode is a burst of incidents; fewer episodes keep the mean volume
+				mean := envVolume * weight / hourlyTotal * (1 - burstContinue)
+				for episodes := poisson(g.r, mean); episodes > 0; episodes-- {
+					at := day.Add(time.Duration(hour)*time.Hour + time.Duration(g.r.Int63n(int64(time.Hour))))
+					g.burst(env, at)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(g.tickets, func(i, j int) bool { return g.tickets[i].CreatedAt < g.tickets[j].CreatedAt })
+	return g.tickets
+}
+
+type generator struct {
+	r   *rand.Rand
+	end time.Time
+	// instances maps environment and service to a stable EC2 instance ID
+	instances map[string]string
+	tickets   []models.IncidentTicket
+}
+
+// burst raises an incident and, with a decreasing chance, the related incidents that
+// follow it: the same alarm firing again, the same problem on another service, or a
+// memory problem that ends in a crash
+func (g *generator) burst(env environment, at time.Time) {
+	svc := env.services[g.r.Intn(len(env.services))]
+	incidentType := g.incidentType(env)
+
+	for {
+		if at.Before(g.end) {
+			g.tickets = append(g.tickets, g.incident(env, svc, incidentType, at))
+		}
+		if g.r.Float64() >= burstContinue {
+			return
+		}
+
+		at = at.Add(2*time.Minute + time.Duration(g.r.Int63n(int64(13*time.Minute))))
+		switch p := g.r.Float64(); {
+		case incidentType == models.IncidentTypeMemHigh && p < 0.3:
+			incidentType = models.IncidentTypeAppCrash
+		case p < 0.6:
+			svc = env.services[g.r.Intn(len(env.services))]
+		}
+	}
+}
+
+func (g *generator) incidentType(env environment) models.IncidentType {
+	var total float64
+	for _, t := range models.IncidentTypes {
+		total += env.types[t]
+	}
+	pick := g.r.Float64() * total
+	for _, t := range models.IncidentTypes {
+		pick -= env.types[t]
+		if pick < 0 {
+			return t
+		}
+	}
+	return models.IncidentTypeOther
+}
+
+// incident creates one ticket and plays its lifecycle up to End
+func (g *generator) incident(env environment, svc service, incidentType models.IncidentType, created time.Time) models.IncidentTicket {
+	manual := g.r.Float64() < env.manualReports
+	threshold := []float64{60, 80, 90}[g.r.Intn(3)]
+	severity := g.severity(env, incidentType, threshold, manual)
+	alarm := alarmName(env.name, svc, incidentType)
+
+	ticket := models.IncidentTicket{
+		ID:               fmt.Sprintf("INC-%s-%08X", created.Format("20060102"), g.r.Uint32()),
+		Title:            fmt.Sprintf("%s - %s", texts[incidentType].title, alarm),
+		Description:      description(g.r, incidentType, threshold),
+		Severity:         severity,
+		Category:         category(svc, incidentType),
+		IncidentType:     incidentType,
+		Environment:      env.name,
+		Reporter:         "cloudwatch-alarm",
+		CreatedAt:        models.FormatTimestamp(created),
+		AffectedServices: []string{svc.name},
+		Tags:             tags(incidentType, env.name, manual),
+	}
+	// The lambdas start with a report of the raw alarm, replaced once the incident is analysed
+	ticket.Report = fmt.Sprintf("Incident auto-detected from CloudWatch alarm: %s. Instance: %s. Triggered at: %s",
+		alarm, g.instance(env, svc), ticket.CreatedAt)
+	ticket.Suggestions = append([]string{}, texts[incidentType].suggestions...)
+
+	if manual {
+		ticket.Title = fmt.Sprintf("%s on %s", texts[incidentType].title, svc.name)
+		ticket.Report = ticket.Description
+		ticket.Reporter = reporters[g.r.Intn(len(reporters))]
+		ticket.ActionStatus = models.ActionStatusManual
+		g.resolveManually(&ticket, incidentType, alarm, created, created, false)
+		return ticket
+	}
+
+	ticket.ActionStatus = models.ActionStatusAuto
+	remediated := created.Add(g.duration(autoRemediation))
+	switch {
+	case !remediated.Before(g.end):
+		ticket.Status = models.StatusProcessing
+		if g.end.Sub(created) < time.Minute {
+			ticket.Status = models.StatusOpen
+		}
+	case g.r.Float64() < pipelineFailure:
+		ticket.Status = models.StatusFailed
+	case g.r.Float64() < autoSuccess[incidentType]:
+		ticket.Report, ticket.Suggestions = report(g.r, incidentType, alarm, remediated.Sub(created))
+		ticket.Status = models.StatusSolved
+		g.notify(&ticket, remediated)
+		resolved := models.FormatTimestamp(remediated)
+		ticket.ResolutionTime = &resolved
+		action := "Auto resolution completed: " + ticket.Report
+		ticket.ActionTaken = &action
+	default:
+		ticket.ActionStatus = models.ActionStatusManual
+		g.notify(&ticket, remediated)
+		action := "Auto resolution failed: remediation did not clear the alarm"
+		ticket.ActionTaken = &action
+		g.resolveManually(&ticket, incidentType, alarm, created, remediated, true)
+	}
+	return ticket
+}
+
+// resolveManually plays the part of the lifecycle a person handles, from the
+// time the ticket reached them
+func (g *generator) resolveManually(ticket *models.IncidentTicket, incidentType models.IncidentType, alarm string, created, from time.Time, escalated bool) {
+	resolved := from.Add(g.duration(manualResolution[ticket.Severity]))
+	if resolved.Before(g.end) {
+		ticket.Report, ticket.Suggestions = report(g.r, incidentType, alarm, resolved.Sub(created))
+		resolution := models.FormatTimestamp(resolved)
+		ticket.ResolutionTime = &resolution
+		switch p := g.r.Float64(); {
+		case p < 0.15:
+			ticket.Status = models.StatusSolved
+		case p < 0.45 && g.end.Sub(resolved) > 3*24*time.Hour:
+			ticket.Status = models.StatusClosed
+		default:
+			ticket.Status = models.StatusResolved
+		}
+		return
+	}
+
+	switch p := g.r.Float64(); {
+	case escalated && p < 0.4:
+		ticket.Status = models.StatusPending
+	case escalated && p < 0.7:
+		ticket.Status = models.StatusManualInterventionRequired
+	case p < 0.5:
+		ticket.Status = models.StatusInProgress
+	default:
+		ticket.Status = models.StatusOpen
+	}
+}
+
+// notify records the email the lambdas send when remediation finishes
+func (g *generator) notify(ticket *models.IncidentTicket, at time.Time) {
+	sent := models.FormatTimestamp(at)
+	ticket.EmailSent = true
+	ticket.EmailSentAt = &sent
+}
+
+// severity rates an incident the way the lambdas do, lowered for manual reports
+// and outside production
+func (g *generator) severity(env environment, incidentType models.IncidentType, threshold float64, manual bool) models.Severity {
+	var severity models.Severity
+	switch incidentType {
+	case models.IncidentTypeAppCrash, models.IncidentTypeAppShutdown:
+		severity = models.SeverityCritical
+	case models.IncidentTypeCPUHigh, models.IncidentTypeMemHigh:
+		switch {
+		case threshold >= 90:
+			severity = models.SeverityCritical
+		case threshold >= 70:
+			severity = models.SeverityHigh
+		default:
+			severity = models.SeverityMedium
+		}
+	case models.IncidentTypeAppError:
+		severity = models.SeverityHigh
+	default:
+		severity = models.SeverityMedium
+	}
+
+	rank := models.SeverityRank(severity)
+	if manual && g.r.Float64() < 0.5 {
+		rank--
+	}
+	if g.r.Float64() < env.downgrade {
+		rank--
+	}
+	return models.Severities[max(0, rank-1)]
+}
+
+// category follows the lambdas, except that workloads on Kubernetes and the CI
+// runners are filed under their platform
+func category(svc service, incidentType models.IncidentType) models.Category {
+	switch {
+	case svc.name == "ci-runners":
+		return models.CategoryCICD
+	case svc.kubernetes && incidentType != models.IncidentTypeOther:
+		return models.CategoryKubernetes
+	}
+	switch incidentType {
+	case models.IncidentTypeCPUHigh, models.IncidentTypeMemHigh:
+		return models.CategoryInfrastructure
+	case models.IncidentTypeAppCrash, models.IncidentTypeAppShutdown, models.IncidentTypeAppError:
+		return models.CategoryCICD
+	}
+	return models.CategoryOther
+}
+
+// instance returns the EC2 instance a service runs on in an environment
+func (g *generator) instance(env environment, svc service) string {
+	key := string(env.name) + "/" + svc.name
+	id, ok := g.instances[key]
+	if !ok {
+		id = fmt.Sprintf("i-0%016x", g.r.Uint64())
+		g.instances[key] = id
+	}
+	return id
+}
+
+// duration samples a log-normal distribution
+func (g *generator) duration(d lognormal) time.Duration {
+	return time.Duration(float64(d.median) * math.Exp(d.sigma*g.r.NormFloat64()))
+}
+
+// poisson samples the number of events of a Poisson process with the given mean
+func poisson(r *rand.Rand, mean float64) int {
+	if mean > 30 {
+		// The normal approximation is close enough here and avoids underflowing limit
+		return max(0, int(math.Round(mean+math.Sqrt(mean)*r.NormFloat64())))
+	}
+	limit := math.Exp(-mean)
+	n := 0
+	for p := r.Float64(); p > limit; p *= r.Float64() {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,151 @@
+package seed
+
+import (
+	"time"
+
+	"irs-be/internal/models"
+)
+
+// service is a workload incidents are raised for
+type service struct {
+	name string
+	// kubernetes services file resource and crash incidents under the kubernetes category
+	kubernetes bool
+}
+
+// environment describes how often and what kind of incidents one environment has
+type environment struct {
+	name models.Environment
+	// share is the part of all incidents that happen in this environment
+	share float64
+	// types weighs the incident types
+	types map[models.IncidentType]float64
+	// hourly weighs the hours of the day, UTC
+	hourly [24]float64
+	// weekend scales the volume on Saturdays and Sundays
+	weekend float64
+	// downgrade is the chance an incident is one severity lower than production would rate it
+	downgrade float64
+	// manualReports is the chance an incident is reported by a person rather than an alarm
+	manualReports float64
+	services      []service
+}
+
+// trafficHours peaks in the afternoon and evening, when customers use production
+var trafficHours = [24]float64{
+	0.4, 0.3, 0.3, 0.3, 0.3, 0.4, 0.6, 0.8, 1.0, 1.1, 1.2, 1.3,
+	1.4, 1.4, 1.5, 1.5, 1.5, 1.6, 1.7, 1.7, 1.5, 1.2, 0.8, 0.5,
+}
+
+// workingHours follows deployments and tests, which happen during office hours
+var workingHours = [24]float64{
+	0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.2, 0.5, 1.2, 1.8, 2.0, 1.8,
+	1.2, 1.6, 2.0, 2.0, 1.8, 1.4, 0.8, 0.4, 0.2, 0.1, 0.1, 0.1,
+}
+
+var environments = []environment{
+	{
+		name:  models.EnvironmentProduction,
+		share: 0.45,
+		types: map[models.IncidentType]float64{
+			models.IncidentTypeCPUHigh:     0.22,
+			models.IncidentTypeMemHigh:     0.18,
+			models.IncidentTypeAppCrash:    0.12,
+			models.IncidentTypeAppShutdown: 0.06,
+			models.IncidentTypeAppError:    0.32,
+			models.IncidentTypeOther:       0.10,
+		},
+		hourly:        trafficHours,
+		weekend:       0.8,
+		downgrade:     0,
+		manualReports: 0.08,
+		services: []service{
+			{name: "api-gateway"}, {name: "auth-service", kubernetes: true}, {name: "checkout", kubernetes: true},
+			{name: "payment-service", kubernetes: true}, {name: "order-service", kubernetes: true},
+			{name: "search-indexer"}, {name: "notification-worker"}, {name: "orders-db"}, {name: "reporting-db"},
+		},
+	},
+	{
+		name:  models.EnvironmentStaging,
+		share: 0.35,
+		types: map[models.IncidentType]float64{
+			models.IncidentTypeCPUHigh:     0.15,
+			models.IncidentTypeMemHigh:     0.15,
+			models.IncidentTypeAppCrash:    0.25,
+			models.IncidentTypeAppShutdown: 0.10,
+			models.IncidentTypeAppError:    0.25,
+			models.IncidentTypeOther:       0.10,
+		},
+		hourly:        workingHours,
+		weekend:       0.15,
+		downgrade:     0.4,
+		manualReports: 0.15,
+		services: []service{
+			{name: "api-gateway"}, {name: "auth-service", kubernetes: true}, {name: "checkout", kubernetes: true},
+			{name: "payment-service", kubernetes: true}, {name: "order-service", kubernetes: true},
+			{name: "notification-worker"}, {name: "load-generator"},
+		},
+	},
+	{
+		name:  models.EnvironmentDevelopment,
+		share: 0.20,
+		types: map[models.IncidentType]float64{
+			models.IncidentTypeCPUHigh:     0.10,
+			models.IncidentTypeMemHigh:     0.20,
+			models.IncidentTypeAppCrash:    0.20,
+			models.IncidentTypeAppShutdown: 0.25,
+			models.IncidentTypeAppError:    0.10,
+			models.IncidentTypeOther:       0.15,
+		},
+		hourly:        workingHours,
+		weekend:       0.05,
+		downgrade:     0.6,
+		manualReports: 0.25,
+		services: []service{
+			{name: "sandbox-api"}, {name: "feature-preview", kubernetes: true}, {name: "ci-runners"}, {name: "dev-db"},
+		},
+	},
+}
+
+// autoSuccess is the chance automatic remediation resolves an incident of a type.
+// Incidents it cannot resolve are escalated for manual intervention.
+var autoSuccess = map[models.IncidentType]float64{
+	models.IncidentTypeCPUHigh:     0.75,
+	models.IncidentTypeMemHigh:     0.65,
+	models.IncidentTypeAppCrash:    0.55,
+	models.IncidentTypeAppShutdown: 0.80,
+	models.IncidentTypeAppError:    0.30,
+	models.IncidentTypeOther:       0.10,
+}
+
+// lognormal is a duration distribution given by its median and the standard
+// deviation of its logarithm
+type lognormal struct {
+	median time.Duration
+	sigma  float64
+}
+
+// manualResolution is how long a person takes to resolve an incident, by severity
+var manualResolution = map[models.Severity]lognormal{
+	models.SeverityCritical: {median: 45 * time.Minute, sigma: 0.7},
+	models.SeverityHigh:     {median: 3 * time.Hour, sigma: 0.8},
+	models.SeverityMedium:   {median: 12 * time.Hour, sigma: 0.9},
+	models.SeverityLow:      {median: 36 * time.Hour, sigma: 1.0},
+}
+
+// autoRemediation is how long the remediation step function runs
+var autoRemediation = lognormal{median: 6 * time.Minute, sigma: 0.5}
+
+const (
+	// incidentsPerDay is the mean number of incidents a day across environments at rate 1
+	incidentsPerDay = 12.0
+	// burstContinue is the chance an incident is followed by a related one, which
+	// makes the mean burst size 1/(1-burstContinue)
+	burstContinue = 0.35
+	// stormChance is the chance a day has an incident storm, such as a bad release
+	stormChance = 0.04
+	// stormFactor scales the volume of a storm day
+	stormFactor = 4.0
+	// pipelineFailure is the chance the remediation pipeline itself fails
+	pipelineFailure = 0.02
+)
@@ -0,0 +1,159 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"irs-be/internal/models"
+)
+
+// cause is a root cause an incident report can name, with the suggestions that follow from it
+type cause struct {
+	report      string
+	suggestions []string
+}
+
+// incidentText holds the wording the lambdas and the report model use for one incident type
+type incidentText struct {
+	// alarm is the suffix of the CloudWatch alarm name
+	alarm string
+	// title is the lambda title prefix
+	title string
+	// metric is the metric the alarm watches, empty for log based alarms
+	metric string
+	// suggestions are the lambda's base suggestions
+	suggestions []string
+	causes      []cause
+}
+
+var texts = map[models.IncidentType]incidentText{
+	models.IncidentTypeCPUHigh: {
+		alarm:       "cpu-high",
+		title:       "High CPU Usage",
+		metric:      "CPUUtilization",
+		suggestions: []string{"Check running processes consuming high CPU", "Scale up instance if needed", "Restart application services", "Review application performance"},
+		causes: []cause{
+			{"A traffic spike after a marketing campaign pushed CPU utilization above the threshold for %d minutes.", []string{"Scale the Auto Scaling group out ahead of campaigns"}},
+			{"A runaway background job kept one core saturated for %d minutes until it was restarted.", []string{"Add a timeout to the background job", "Limit the job's CPU with a cgroup quota"}},
+			{"A retry storm from a client release doubled the request rate for %d minutes.", []string{"Enable exponential backoff in the client", "Throttle requests per client"}},
+		},
+	},
+	models.IncidentTypeMemHigh: {
+		alarm:       "memory-high",
+		title:       "High Memory Usage",
+		metric:      "mem_used_percent",
+		suggestions: []string{"Check memory usage by processes", "Clear application cache", "Restart memory-intensive services", "Scale up instance memory"},
+		causes: []cause{
+			{"Memory grew steadily for %d minutes because of a connection leak introduced in the latest release.", []string{"Roll back to the previous release", "Capture a heap profile before restarting"}},
+			{"An unbounded in-memory cache kept growing for %d minutes under a new access pattern.", []string{"Set a size limit on the cache"}},
+			{"A large export loaded its whole result set into memory for %d minutes.", []string{"Stream the export instead of buffering it", "Run exports on a dedicated worker"}},
+		},
+	},
+	models.IncidentTypeAppCrash: {
+		alarm:       "app-crash",
+		title:       "Application Crash",
+		suggestions: []string{"Check application logs for crash reason", "Restart crashed application", "Check resource limits and dependencies", "Review recent deployments"},
+		causes: []cause{
+			{"The process was killed by the OOM killer and restarted %d times.", []string{"Raise the memory limit", "Investigate the allocation spike before the crash"}},
+			{"A nil pointer dereference in a new code path crashed the service %d times.", []string{"Roll back the deployment", "Add a regression test for the failing request"}},
+			{"A missing configuration value made the service exit on startup %d times.", []string{"Validate configuration in the deployment pipeline"}},
+		},
+	},
+	models.IncidentTypeAppShutdown: {
+		alarm:       "app-shutdown",
+		title:       "Service Shutdown",
+		suggestions: []string{"Check if shutdown was planned", "Restart the application service", "Check system resources", "Review application health"},
+		causes: []cause{
+			{"Host patching stopped the service, which stayed down for %d minutes.", []string{"Add a post-patch health check", "Announce patch windows to the on-call engineer"}},
+			{"A spot instance interruption terminated the host; the service was down for %d minutes.", []string{"Spread the service across on-demand and spot capacity"}},
+			{"The disk filled up and the service shut itself down for %d minutes.", []string{"Rotate logs more aggressively", "Alert on disk usage above 80%"}},
+		},
+	},
+	models.IncidentTypeAppError: {
+		alarm:       "app-error",
+		title:       "Application Error",
+		suggestions: []string{"Check application logs for error details", "Review recent deployments", "Check database connectivity", "Restart application services"},
+		causes: []cause{
+			{"The database connection pool was exhausted and requests failed for %d minutes.", []string{"Move long-running queries to a read replica", "Set a statement timeout"}},
+			{"An expired TLS certificate on a downstream API caused errors for %d minutes.", []string{"Automate certificate renewal", "Alert on certificates expiring within 14 days"}},
+			{"A schema migration locked a hot table and writes failed for %d minutes.", []string{"Run migrations online", "Schedule migrations outside peak hours"}},
+		},
+	},
+	models.IncidentTypeOther: {
+		alarm:       "health-check",
+		title:       "System Alert",
+		suggestions: []string{"Investigate the issue", "Check system logs"},
+		causes: []cause{
+			{"The health check endpoint timed out intermittently for %d minutes while DNS resolution was slow.", []string{"Cache DNS lookups", "Lower the resolver timeout"}},
+			{"The registry rate limit failed image pulls for %d minutes.", []string{"Mirror images to the internal registry"}},
+		},
+	},
+}
+
+// alarmName is the CloudWatch alarm name the lambdas put in titles
+func alarmName(env models.Environment, svc service, incidentType models.IncidentType) string {
+	return fmt.Sprintf("%s-%s-%s", env, svc.name, texts[incidentType].alarm)
+}
+
+// description is the alarm reason, as CloudWatch writes it
+func description(r *rand.Rand, incidentType models.IncidentType, threshold float64) string {
+	text := texts[incidentType]
+	if text.metric == "" {
+		events := 5 + r.Intn(60)
+		return fmt.Sprintf("Threshold Crossed: %d error events in the last 5 minutes were greater than the threshold (5.0).", events)
+	}
+	points := 1 + r.Intn(3)
+	values := make([]string, points)
+	for i := range values {
+		values[i] = fmt.Sprintf("%.1f", threshold+r.Float64()*(100-threshold))
+	}
+	verb := "was"
+	if points > 1 {
+		verb = "were"
+	}
+	return fmt.Sprintf("Threshold Crossed: %d out of the last %d datapoints [%s] %s greater than the threshold (%.1f).",
+		points, points, strings.Join(values, ", "), verb, threshold)
+}
+
+// report is the analysis of an incident with its suggestions; both name the same cause
+func report(r *rand.Rand, incidentType models.IncidentType, alarm string, duration time.Duration) (string, []string) {
+	text := texts[incidentType]
+	c := text.causes[r.Intn(len(text.causes))]
+
+	// Crash causes count restarts rather than minutes
+	n := max(1, int(duration.Minutes()))
+	if incidentType == models.IncidentTypeAppCrash {
+		n = 1 + n/15
+	}
+	analysis := fmt.Sprintf("Incident detected by %s. %s", alarm, fmt.Sprintf(c.report, n))
+	suggestions := append([]string{}, c.suggestions...)
+	for _, s := range text.suggestions {
+		if len(suggestions) == 4 {
+			break
+		}
+		if r.Float64() < 0.5 {
+			suggestions = append(suggestions, s)
+		}
+	}
+	return analysis, suggestions
+}
+
+// tags mirrors the lambda's generate_tags
+func tags(incidentType models.IncidentType, env models.Environment, manual bool) []string {
+	source := "cloudwatch-auto"
+	if manual {
+		source = "manual-report"
+	}
+	tags := []string{strings.ToLower(string(incidentType)), string(env), source}
+	switch incidentType {
+	case models.IncidentTypeCPUHigh, models.IncidentTypeMemHigh:
+		tags = append(tags, "resource", "performance")
+	case models.IncidentTypeAppCrash, models.IncidentTypeAppShutdown:
+		tags = append(tags, "availability", "application")
+	case models.IncidentTypeAppError:
+		tags = append(tags, "application", "error")
+	}
+	return tags
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"irs-be/internal/config"
+	"irs-be/internal/models"
+	"irs-be/internal/retention"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// archivePageSize is the number of tickets scanned per page while applying retention rules
+const archivePageSize = 200
+
+var (
+	// ErrArchiveNotConfigured is returned by Run when ARCHIVE_URL is not set
+	ErrArchiveNotConfigured = errors.New("archive is not configured, set ARCHIVE_URL")
+	// ErrRetentionRunning is returned by Run while another run is in progress
+	ErrRetentionRunning = errors.New("a retention run is already in progress")
+)
+
+// ArchiveService applies the retention rules: it moves the tickets they match to the
+// archive and marks their table items archived with a TTL, after which DynamoDB
+// removes them. Archived tickets stay retrievable by ID through the ticket service.
+type ArchiveService struct {
+	ticketService *TicketService
+	archive       *retention.Archive
+	rules         []retention.Rule
+	ttlDelay      time.Duration
+	batchSize     int
+	interval      time.Duration
+	running       sync.Mutex
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewArchiveService creates a new archive service instance and lets the ticket
+// service read archived tickets
+func NewArchiveService(ctx context.Context, ticketService *TicketService, cfg config.Config) (*ArchiveService, error) {
+	rules, err := retention.ParseRules(cfg.Retention.Rules)
+	if err != nil {
+		return nil, err
+	}
+	batchSize := cfg.Retention.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	s := &ArchiveService{
+		ticketService: ticketService,
+		rules:         rules,
+		ttlDelay:      cfg.Retention.TTLDelay,
+		batchSize:     batchSize,
+		interval:      cfg.Retention.Interval,
+		stop:          make(chan struct{}),
+	}
+
+	if cfg.Retention.ArchiveURL == "" {
+		if len(rules) > 0 {
+			return nil, fmt.Errorf("RETENTION_RULES needs ARCHIVE_URL to archive tickets to")
+		}
+		return s, nil
+	}
+	store, err := retention.Open(ctx, cfg.Retention.ArchiveURL, cfg.Retention.S3Endpoint, func(ctx context.Context) (aws.Config, error) {
+		return LoadAWSConfig(ctx, cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.archive = retention.NewArchive(store, ticketService.tableName)
+	ticketService.archive = s.archive
+
+	slog.Info("Initialized archive service", "archive", cfg.Retention.ArchiveURL, "rules", len(rules))
+	return s, nil
+}
+
+// Start applies the rules every interval, when an interval and rules are configured
+func (s *ArchiveService) Start() {
+	if s.interval <= 0 || len(s.rules) == 0 || s.archive == nil {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.runScheduled()
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic runs, waiting for a run in progress
+func (s *ArchiveService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	s.wg.Wait()
+}
+
+func (s *ArchiveService) runScheduled() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+	defer cancel()
+
+	report, err := s.Run(ctx, false)
+	if err != nil {
+		slog.Error("Failed to apply retention rules", "error", err)
+		return
+	}
+	slog.Info("Applied retention rules", "scanned", report.Scanned, "archived", report.Archived,
+		"conflicts", report.Conflicts, "failed", report.Failed)
+}
+
+// Run archives the tickets the rules match. A dry run only counts them. Tickets are
+// written to the archive before they are marked, so a failed run leaves them in the
+// table, possibly archived twice, but never lost.
+func (s *ArchiveService) Run(ctx context.Context, dryRun bool) (*retention.Report, error) {
+	if s.archive == nil {
+		return nil, ErrArchiveNotConfigured
+	}
+	if !s.running.TryLock() {
+		return nil, ErrRetentionRunning
+	}
+	defer s.running.Unlock()
+
+	report := &retention.Report{DryRun: dryRun, Objects: []string{}}
+	for _, rule := range s.rules {
+		report.Rules = append(report.Rules, rule.String())
+	}
+
+	now := time.Now()
+	run := fmt.Sprintf("%s-%s", now.UTC().Format("150405"), uuid.NewString()[:8])
+	var batch []models.IncidentTicket
+	var statuses []string
+	flush := func() error {
+		if len(batch) == 0 || dryRun {
+			batch, statuses = batch[:0], statuses[:0]
+			return nil
+		}
+		object := retention.ObjectKey(now, run, len(report.Objects)+1)
+		if err := s.archive.Write(ctx, object, batch, now); err != nil {
+			return err
+		}
+		report.Objects = append(report.Objects, object)
+		s.markArchived(ctx, batch, statuses, now, report)
+		batch, statuses = batch[:0], statuses[:0]
+		return nil
+	}
+
+	err := s.ticketService.StreamTickets(ctx, archivePageSize, func(ticket models.IncidentTicket) error {
+		report.Scanned++
+		rule, ok := retention.Match(s.rules, ticket, now)
+		if !ok {
+			return nil
+		}
+		report.Matched++
+		batch = append(batch, ticket)
+		statuses = append(statuses, rule.Status)
+		if len(batch) == s.batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	return report, err
+}
+
+// markArchived sets archivedAt and the TTL on the table items of an archived batch.
+// Each update only applies if the ticket still matches its rule.
+func (s *ArchiveService) markArchived(ctx context.Context, tickets []models.IncidentTicket, statuses []string, now time.Time, report *retention.Report) {
+	values := map[string]types.AttributeValue{
+		":archivedAt": &types.AttributeValueMemberS{Value: models.FormatTimestamp(now)},
+		":expiresAt":  &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(s.ttlDelay).Unix(), 10)},
+	}
+
+	var marked []string
+	for i, ticket := range tickets {
+		input := &dynamodb.UpdateItemInput{
+			TableName: aws.String(s.ticketService.tableName),
+			Key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: ticket.ID},
+			},
+			UpdateExpression:          aws.String("SET archivedAt = :archivedAt, " + retention.ExpiresAttribute + " = :expiresAt"),
+			ConditionExpression:       aws.String("attribute_exists(deletedAt) AND attribute_not_exists(archivedAt)"),
+			ExpressionAttributeValues: values,
+		}
+		if statuses[i] != retention.Deleted {
+			input.ConditionExpression = aws.String("#status = :status AND attribute_not_exists(deletedAt) AND attribute_not_exists(archivedAt)")
+			input.ExpressionAttributeNames = map[string]string{"#status": "status"}
+			input.ExpressionAttributeValues = map[string]types.AttributeValue{
+				":archivedAt": values[":archivedAt"],
+				":expiresAt":  values[":expiresAt"],
+				":status":     &types.AttributeValueMemberS{Value: statuses[i]},
+			}
+		}
+
+		_, err := s.ticketService.client.UpdateItem(ctx, input)
+		var conditionErr *types.ConditionalCheckFailedException
+		switch {
+		case errors.As(err, &conditionErr):
+			report.Conflicts++
+		case err != nil:
+			report.Failed++
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", ticket.ID, err))
+		default:
+			report.Archived++
+			marked = append(marked, ticket.ID)
+		}
+	}
+
+	if len(marked) > 0 {
+		s.ticketService.InvalidateTickets(marked...)
+	}
+}
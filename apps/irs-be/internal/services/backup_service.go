@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"irs-be/internal/backup"
+	"irs-be/internal/config"
+	"irs-be/internal/models"
+	"irs-be/internal/ticketcodec"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// batchWriteSize is the BatchWriteItem limit on requests per call
+	batchWriteSize = 25
+	// dumpPageSize is the number of tickets scanned per page while dumping
+	dumpPageSize = 100
+)
+
+// BackupService dumps the ticket table to a versioned archive and restores it
+type BackupService struct {
+	client         *dynamodb.Client
+	tableName      string
+	ticketService  *TicketService
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// NewBackupService creates a new backup service instance
+func NewBackupService(client *dynamodb.Client, ticketService *TicketService, cfg config.Config) *BackupService {
+	return &BackupService{
+		client:         client,
+		tableName:      cfg.DynamoDB.TableName,
+		ticketService:  ticketService,
+		maxAttempts:    cfg.Admin.BatchMaxAttempts,
+		initialBackoff: cfg.Admin.BatchInitialBackoff,
+		maxBackoff:     cfg.Admin.BatchMaxBackoff,
+	}
+}
+
+// TableName returns the table the service reads from and writes to
+func (s *BackupService) TableName() string {
+	return s.tableName
+}
+
+// Dump writes every ticket to w as an archive and returns the number of tickets written
+func (s *BackupService) Dump(ctx context.Context, w io.Writer) (int, error) {
+	writer, err := backup.NewWriter(w, s.tableName)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.ticketService.StreamTickets(ctx, dumpPageSize, writer.Write)
+	return writer.Count(), err
+}
+
+// DecodeFailures lists the table items that could not be decoded as tickets when
+// last read; dumps leave them out
+func (s *BackupService) DecodeFailures() []DecodeFailure {
+	return s.ticketService.DecodeFailures()
+}
+
+// Restore writes the tickets of an archive into the table. Records that cannot be
+// mapped to a ticket are listed in the report and do not stop the restore. Restored
+// tickets do not publish lifecycle events.
+func (s *BackupService) Restore(ctx context.Context, r io.Reader, mode backup.RestoreMode, dryRun bool) (*backup.RestoreReport, error) {
+	if !mode.IsValid() {
+		return nil, fmt.Errorf("unsupported restore mode %q, use upsert or insert", mode)
+	}
+
+	reader, err := backup.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &backup.RestoreReport{
+		Header:  reader.Header(),
+		Mode:    mode,
+		DryRun:  dryRun,
+		Invalid: []*backup.RecordError{},
+	}
+
+	batcher := s.newRestoreBatcher(mode, dryRun, report)
+	for {
+		ticket, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		var recordErr *backup.RecordError
+		if errors.As(err, &recordErr) {
+			report.Invalid = append(report.Invalid, recordErr)
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+
+		report.Read++
+		if err := batcher.add(ctx, ticket); err != nil {
+			return report, err
+		}
+	}
+
+	return report, batcher.flush(ctx)
+}
+
+// Import writes tickets that did not come from an archive, such as generated seed
+// data, the same way Restore does. Imported tickets do not publish lifecycle events.
+func (s *BackupService) Import(ctx context.Context, tickets []models.IncidentTicket, mode backup.RestoreMode, dryRun bool) (*backup.RestoreReport, error) {
+	if !mode.IsValid() {
+		return nil, fmt.Errorf("unsupported restore mode %q, use upsert or insert", mode)
+	}
+
+	report := &backup.RestoreReport{
+		Header:  backup.Header{Table: s.tableName},
+		Mode:    mode,
+		DryRun:  dryRun,
+		Invalid: []*backup.RecordError{},
+	}
+
+	batcher := s.newRestoreBatcher(mode, dryRun, report)
+	for _, ticket := range tickets {
+		report.Read++
+		if err := batcher.add(ctx, ticket); err != nil {
+			return report, err
+		}
+	}
+	return report, batcher.flush(ctx)
+}
+
+// restoreBatcher groups tickets into batches for restoreBatch
+type restoreBatcher struct {
+	service *BackupService
+	mode    backup.RestoreMode
+	dryRun  bool
+	report  *backup.RestoreReport
+	batch   []models.IncidentTicket
+	inBatch map[string]bool
+}
+
+func (s *BackupService) newRestoreBatcher(mode backup.RestoreMode, dryRun bool, report *backup.RestoreReport) *restoreBatcher {
+	return &restoreBatcher{
+		service: s,
+		mode:    mode,
+		dryRun:  dryRun,
+		report:  report,
+		batch:   make([]models.IncidentTicket, 0, batchWriteSize),
+		inBatch: make(map[string]bool, batchWriteSize),
+	}
+}
+
+// add queues a ticket and writes the batch once it is full
+func (b *restoreBatcher) add(ctx context.Context, ticket models.IncidentTicket) error {
+	// A batch may not contain the same key twice; the later record wins
+	if b.inBatch[ticket.ID] {
+		if err := b.flush(ctx); err != nil {
+			return err
+		}
+	}
+	b.batch = append(b.batch, ticket)
+	b.inBatch[ticket.ID] = true
+
+	if len(b.batch) == batchWriteSize {
+		return b.flush(ctx)
+	}
+	return nil
+}
+
+// flush writes the queued tickets
+func (b *restoreBatcher) flush(ctx context.Context) error {
+	if len(b.batch) == 0 {
+		return nil
+	}
+	err := b.service.restoreBatch(ctx, b.batch, b.mode, b.dryRun, b.report)
+	b.batch = b.batch[:0]
+	b.inBatch = make(map[string]bool, batchWriteSize)
+	return err
+}
+
+// restoreBatch writes up to batchWriteSize tickets
+func (s *BackupService) restoreBatch(ctx context.Context, tickets []models.IncidentTicket, mode backup.RestoreMode, dryRun bool, report *backup.RestoreReport) error {
+	if mode == backup.RestoreInsert {
+		existing, err := s.existingIDs(ctx, tickets)
+		if err != nil {
+			return err
+		}
+		kept := tickets[:0]
+		for _, ticket := range tickets {
+			if existing[ticket.ID] {
+				report.Skipped++
+				continue
+			}
+			kept = append(kept, ticket)
+		}
+		tickets = kept
+	}
+
+	if len(tickets) == 0 {
+		return nil
+	}
+	if dryRun {
+		report.Written += len(tickets)
+		return nil
+	}
+
+	requests := make([]types.WriteRequest, 0, len(tickets))
+	for _, ticket := range tickets {
+		item, err := ticketcodec.Encode(ticket)
+		if err != nil {
+			report.Failed++
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+
+	unprocessed, err := s.batchWrite(ctx, requests)
+	if err != nil {
+		return err
+	}
+
+	report.Written += len(requests) - len(unprocessed)
+	s.ticketService.InvalidateTickets()
+	for _, request := range unprocessed {
+		report.Failed++
+		id := ""
+		if attr, ok := request.PutRequest.Item["id"].(*types.AttributeValueMemberS); ok {
+			id = attr.Value
+		}
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: still unprocessed after %d attempts", id, s.maxAttempts))
+	}
+	return nil
+}
+
+// batchWrite sends a BatchWriteItem call and resends unprocessed items with
+// exponential backoff, which is how DynamoDB signals throttling for batches.
+// It returns the requests that were still unprocessed after the last attempt.
+func (s *BackupService) batchWrite(ctx context.Context, requests []types.WriteRequest) ([]types.WriteRequest, error) {
+	pending := requests
+	for attempt := 1; len(pending) > 0; attempt++ {
+		output, err := s.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{s.tableName: pending},
+		})
+		switch {
+		case err != nil && !isThrottlingError(err):
+			return pending, fmt.Errorf("failed to batch write tickets: %v", err)
+		case err == nil:
+			pending = output.UnprocessedItems[s.tableName]
+			if len(pending) == 0 {
+				return nil, nil
+			}
+		}
+
+		if attempt >= s.maxAttempts {
+			return pending, nil
+		}
+		if err := sleepContext(ctx, s.backoff(attempt)); err != nil {
+			return pending, err
+		}
+	}
+	return nil, nil
+}
+
+// existingIDs returns which of the tickets are already in the table
+func (s *BackupService) existingIDs(ctx context.Context, tickets []models.IncidentTicket) (map[string]bool, error) {
+	keys := make([]map[string]types.AttributeValue, 0, len(tickets))
+	for _, ticket := range tickets {
+		keys = append(keys, map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: ticket.ID},
+		})
+	}
+
+	existing := make(map[string]bool, len(tickets))
+	for attempt := 1; len(keys) > 0; attempt++ {
+		output, err := s.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]types.KeysAndAttributes{
+				s.tableName: {
+					Keys:                 keys,
+					ProjectionExpression: aws.String("id"),
+				},
+			},
+		})
+		if err != nil && !isThrottlingError(err) {
+			return nil, fmt.Errorf("failed to check existing tickets: %v", err)
+		}
+
+		if err == nil {
+			for _, item := range output.Responses[s.tableName] {
+				if attr, ok := item["id"].(*types.AttributeValueMemberS); ok {
+					existing[attr.Value] = true
+				}
+			}
+			keys = output.UnprocessedKeys[s.tableName].Keys
+			if len(keys) == 0 {
+				break
+			}
+		}
+
+		if attempt >= s.maxAttempts {
+			return nil, fmt.Errorf("failed to check existing tickets: %d keys still unprocessed after %d attempts", len(keys), attempt)
+		}
+		if err := sleepContext(ctx, s.backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+	return existing, nil
+}
+
+// backoff returns the delay before the next attempt, with full jitter
+func (s *BackupService) backoff(attempt int) time.Duration {
+	delay := s.initialBackoff << (attempt - 1)
+	if delay <= 0 || delay > s.maxBackoff {
+		delay = s.maxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+// isThrottlingError reports whether DynamoDB rejected a call for capacity reasons
+func isThrottlingError(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "ProvisionedThroughputExceededException", "ThrottlingException", "RequestLimitExceeded":
+		return true
+	}
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
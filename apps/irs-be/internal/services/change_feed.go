@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+
+	"irs-be/internal/changefeed"
+	"irs-be/internal/config"
+)
+
+// NewChangeFeed creates a consumer of the ticket table's stream, checkpointing to
+// STREAM_CHECKPOINT_FILE when set and in memory otherwise
+func NewChangeFeed(ctx context.Context, table changefeed.TableClient, cfg config.Config) (*changefeed.Consumer, error) {
+	client, err := NewDynamoDBStreamsClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	streamARN := cfg.Stream.ARN
+	if streamARN == "" {
+		if streamARN, err = changefeed.LatestStreamARN(ctx, table, cfg.DynamoDB.TableName); err != nil {
+			return nil, err
+		}
+	}
+
+	var checkpoints changefeed.Checkpoints = changefeed.NewMemoryCheckpoints()
+	if cfg.Stream.CheckpointFile != "" {
+		checkpoints = changefeed.NewFileCheckpoints(cfg.Stream.CheckpointFile)
+	}
+	return changefeed.NewConsumer(client, streamARN, checkpoints, cfg.Stream)
+}
+
+// HandleChange drops the cached reads a change read from the table's stream may
+// affect, so writes of the lambdas and of other replicas show up before the cache
+// TTL runs out
+func (s *TicketService) HandleChange(change changefeed.Change) {
+	if change.Type == changefeed.ChangeReset {
+		s.InvalidateTickets()
+		return
+	}
+	s.InvalidateTickets(change.ID)
+}
+
+// PublishExternalChanges returns a change handler publishing the lifecycle events of
+// the changes made outside irs-be, such as a ticket created or resolved by the
+// lambdas. irs-be's own writes publish their events as they happen.
+func PublishExternalChanges(events *EventBus) changefeed.Handler {
+	return func(change changefeed.Change) {
+		if !change.External || change.New == nil {
+			return
+		}
+		// Without the old image a modification cannot be told from a creation
+		if change.Type == changefeed.ChangeModify && change.Old == nil {
+			return
+		}
+		for _, eventType := range lifecycleEvents(change.Old, *change.New) {
+			events.Publish(eventType, *change.New, change.Old)
+		}
+	}
+}
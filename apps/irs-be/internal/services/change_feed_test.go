@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"irs-be/internal/cache"
+	"irs-be/internal/changefeed"
+	"irs-be/internal/config"
+	"irs-be/internal/models"
+	"irs-be/internal/ticketcodec"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const testStreamARN = "arn:aws:dynamodb:us-east-1:000000000000:table/insident/stream/test"
+
+// itemStore serves GetItem from a map of items and counts the reads
+type itemStore struct {
+	ticketStore
+	mu    sync.Mutex
+	items map[string]map[string]types.AttributeValue
+	reads int
+}
+
+func (s *itemStore) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	id := params.Key["id"].(*types.AttributeValueMemberS).Value
+	return &dynamodb.GetItemOutput{Item: s.items[id]}, nil
+}
+
+func (s *itemStore) put(item map[string]types.AttributeValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[item["id"].(*types.AttributeValueMemberS).Value] = item
+}
+
+func (s *itemStore) readCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reads
+}
+
+func newCachedTicketService(store ticketStore, events *EventBus) *TicketService {
+	return &TicketService{
+		client:    store,
+		snapshot:  newTicketSnapshot(0),
+		cache:     cache.NewTTL[[]models.IncidentTicket](time.Hour, 100),
+		failures:  newDecodeFailures(maxDecodeFailures),
+		tableName: "insident",
+		events:    events,
+	}
+}
+
+func encodeTicket(t *testing.T, ticket models.IncidentTicket) map[string]types.AttributeValue {
+	t.Helper()
+	item, err := ticketcodec.Encode(ticket)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return item
+}
+
+// lambdaUpdate changes an item the way the lambdas do, leaving its write ID alone
+func lambdaUpdate(item map[string]types.AttributeValue, status models.Status) map[string]types.AttributeValue {
+	updated := make(map[string]types.AttributeValue, len(item))
+	for name, value := range item {
+		updated[name] = value
+	}
+	updated["status"] = &types.AttributeValueMemberS{Value: string(status)}
+	return updated
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHandleChangeInvalidatesCachedReads(t *testing.T) {
+	store := &itemStore{items: map[string]map[string]types.AttributeValue{}}
+	store.put(encodeTicket(t, models.IncidentTicket{ID: "INC-1", Status: models.StatusOpen}))
+	store.put(encodeTicket(t, models.IncidentTicket{ID: "INC-2", Status: models.StatusOpen}))
+	s := newCachedTicketService(store, nil)
+	purged := 0
+	s.SubscribeInvalidations(func() { purged++ })
+	ctx := context.Background()
+
+	read := func(id string) {
+		t.Helper()
+		if _, err := s.GetTicketByID(ctx, id); err != nil {
+			t.Fatalf("GetTicketByID(%s): %v", id, err)
+		}
+	}
+	read("INC-1")
+	read("INC-2")
+	read("INC-1")
+	if store.readCount() != 2 {
+		t.Fatalf("reads = %d, want 2 with the cache warm", store.readCount())
+	}
+
+	s.HandleChange(changefeed.Change{Type: changefeed.ChangeModify, ID: "INC-1"})
+	read("INC-1")
+	read("INC-2")
+	if store.readCount() != 3 {
+		t.Errorf("reads = %d, want only INC-1 read again", store.readCount())
+	}
+
+	s.HandleChange(changefeed.Change{Type: changefeed.ChangeReset})
+	read("INC-1")
+	read("INC-2")
+	if store.readCount() != 5 {
+		t.Errorf("reads = %d, want both read again after a reset", store.readCount())
+	}
+	if purged != 2 {
+		t.Errorf("invalidation subscribers called %d times, want once per change", purged)
+	}
+}
+
+func TestChangeFeedPublishesExternalChanges(t *testing.T) {
+	store := &itemStore{items: map[string]map[string]types.AttributeValue{}}
+	events := NewEventBus()
+	var mu sync.Mutex
+	var published []models.TicketEvent
+	events.Subscribe(func(event models.TicketEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		published = append(published, event)
+	})
+	eventTypes := func() []models.TicketEventType {
+		mu.Lock()
+		defer mu.Unlock()
+		types := make([]models.TicketEventType, len(published))
+		for i, event := range published {
+			types[i] = event.Type
+		}
+		return types
+	}
+	s := newCachedTicketService(store, events)
+
+	stream := changefeed.NewFakeStream(testStreamARN)
+	consumer, err := changefeed.NewConsumer(stream, testStreamARN, changefeed.NewMemoryCheckpoints(), config.StreamConfig{
+		StartPosition: "trim_horizon",
+		PollInterval:  5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewConsumer: %v", err)
+	}
+	handled := make(chan string, 10)
+	consumer.Subscribe(s.HandleChange)
+	consumer.Subscribe(PublishExternalChanges(events))
+	consumer.Subscribe(func(change changefeed.Change) { handled <- change.SequenceNumber })
+	consumer.Start()
+	defer consumer.Stop()
+
+	// A ticket created by irs-be: its event was published by the write itself
+	created := encodeTicket(t, models.IncidentTicket{ID: "INC-1", Status: models.StatusOpen})
+	store.put(created)
+	stream.Insert(created)
+	<-handled
+	if _, err := s.GetTicketByID(context.Background(), "INC-1"); err != nil {
+		t.Fatalf("GetTicketByID: %v", err)
+	}
+
+	// The lambdas resolve it
+	resolved := lambdaUpdate(created, models.StatusResolved)
+	store.put(resolved)
+	stream.Modify(created, resolved)
+	<-handled
+
+	ticket, err := s.GetTicketByID(context.Background(), "INC-1")
+	if err != nil {
+		t.Fatalf("GetTicketByID: %v", err)
+	}
+	if ticket.Status != models.StatusResolved {
+		t.Errorf("status = %s, want the cached read replaced by the lambda's resolution", ticket.Status)
+	}
+	waitFor(t, "the lambda's events", func() bool { return len(eventTypes()) == 2 })
+	if got := eventTypes(); got[0] != models.TicketEventStatusChanged || got[1] != models.TicketEventResolved {
+		t.Errorf("events = %v, want [status_changed resolved]", got)
+	}
+	mu.Lock()
+	if event := published[1]; event.Previous == nil || event.Previous.Status != models.StatusOpen || event.Ticket.Status != models.StatusResolved {
+		t.Errorf("resolved event = %+v, want the ticket before and after", event)
+	}
+	mu.Unlock()
+
+	// irs-be reopens it, with a new write ID, and a lambda creates another ticket
+	reopened := encodeTicket(t, models.IncidentTicket{ID: "INC-1", Status: models.StatusOpen})
+	stream.Modify(resolved, reopened)
+	<-handled
+	lambdaCreated := map[string]types.AttributeValue{
+		"id":     &types.AttributeValueMemberS{Value: "INC-2"},
+		"status": &types.AttributeValueMemberS{Value: string(models.StatusOpen)},
+	}
+	stream.Insert(lambdaCreated)
+	<-handled
+
+	if got := eventTypes(); len(got) != 3 || got[2] != models.TicketEventCreated {
+		t.Errorf("events = %v, want only the lambda's creation added", got)
+	}
+}
@@ -0,0 +1,368 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"irs-be/internal/config"
+	"irs-be/internal/dto"
+	"irs-be/internal/models"
+	"irs-be/internal/tenant"
+)
+
+// Slack interactive action IDs used on incident cards
+const (
+	SlackActionAck     = "incident_ack"
+	SlackActionResolve = "incident_resolve"
+)
+
+// slackSignatureMaxAge bounds how old a signed Slack request may be, to prevent replays
+const slackSignatureMaxAge = 5 * time.Minute
+
+// maxListedIncidents caps the number of incidents shown by "/incident list"
+const maxListedIncidents = 10
+
+var ErrInvalidSlackSignature = errors.New("invalid slack signature")
+
+// ChatOpsService exposes ticket operations to Slack-compatible chat tools. Commands
+// act on the tickets of the team owning the channel they come from.
+type ChatOpsService struct {
+	ticketService *TicketService
+	teams         *tenant.Directory
+	signingSecret string
+	webhookURL    string
+	dashboardURL  string
+	httpClient    *http.Client
+}
+
+// NewChatOpsService creates a new ChatOps service instance
+func NewChatOpsService(ticketService *TicketService, teams *tenant.Directory, cfg config.Config) *ChatOpsService {
+	return &ChatOpsService{
+		ticketService: ticketService,
+		teams:         teams,
+		signingSecret: cfg.Slack.SigningSecret.Reveal(),
+		webhookURL:    cfg.Slack.WebhookURL.Reveal(),
+		dashboardURL:  strings.TrimRight(cfg.Slack.DashboardURL, "/"),
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Enabled reports whether a signing secret is configured. Without one every
+// request would be unauthenticated, so the endpoints stay closed.
+func (s *ChatOpsService) Enabled() bool {
+	return s.signingSecret != ""
+}
+
+// VerifySignature checks Slack's v0 request signature over "v0:<timestamp>:<body>"
+func (s *ChatOpsService) VerifySignature(timestamp, signature string, body []byte) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSlackSignature
+	}
+	age := time.Since(time.Unix(seconds, 0))
+	if age > slackSignatureMaxAge || age < -slackSignatureMaxAge {
+		return ErrInvalidSlackSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.signingSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSlackSignature
+	}
+	return nil
+}
+
+// HandleSlashCommand runs "/incident list|ack|resolve|help"
+func (s *ChatOpsService) HandleSlashCommand(ctx context.Context, cmd dto.SlackSlashCommand) dto.SlackMessage {
+	ctx = tenant.WithScope(ctx, tenant.Team(s.teams.TeamForChannel(cmd.ChannelID)))
+	args := strings.Fields(cmd.Text)
+	if len(args) == 0 {
+		return s.helpMessage(cmd.Command)
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list":
+		return s.listOpenIncidents(ctx)
+	case "ack":
+		if len(args) < 2 {
+			return ephemeral(fmt.Sprintf("Usage: `%s ack <id>`", cmd.Command))
+		}
+		return s.acknowledge(ctx, args[1], cmd.UserName)
+	case "resolve":
+		if len(args) < 3 {
+			return ephemeral(fmt.Sprintf("Usage: `%s resolve <id> <note>`", cmd.Command))
+		}
+		note := strings.Join(args[2:], " ")
+		return s.resolve(ctx, args[1], note, cmd.UserName)
+	default:
+		return s.helpMessage(cmd.Command)
+	}
+}
+
+// HandleInteraction runs the ticket operation behind an incident card button
+func (s *ChatOpsService) HandleInteraction(ctx context.Context, interaction dto.SlackInteraction) dto.SlackMessage {
+	if len(interaction.Actions) == 0 {
+		return ephemeral("No action received")
+	}
+	ctx = tenant.WithScope(ctx, tenant.Team(s.teams.TeamForChannel(interaction.Channel.ID)))
+
+	user := interaction.User.Username
+	if user == "" {
+		user = interaction.User.Name
+	}
+
+	action := interaction.Actions[0]
+	var message dto.SlackMessage
+	switch action.ActionID {
+	case SlackActionAck:
+		message = s.acknowledge(ctx, action.Value, user)
+	case SlackActionResolve:
+		message = s.resolve(ctx, action.Value, "Resolved from chat", user)
+	default:
+		return ephemeral("Unknown action: " + action.ActionID)
+	}
+
+	// Replace the card the button was clicked on with its updated version
+	message.ReplaceOriginal = true
+	return message
+}
+
+// HandleEvent is an EventHandler that posts incident cards for the events the
+// ticket's team is notified of, by default new and escalated tickets. Cards go to the
+// team's Slack webhook, or to SLACK_WEBHOOK_URL for teams without one.
+func (s *ChatOpsService) HandleEvent(event models.TicketEvent) {
+	settings := s.teams.Settings(event.Ticket.Team)
+	url := settings.SlackWebhookURL.Reveal()
+	if url == "" {
+		url = s.webhookURL
+	}
+	if url == "" || !settings.Notifies(event.Type) {
+		return
+	}
+
+	var text string
+	switch event.Type {
+	case models.TicketEventCreated:
+		text = "New incident opened"
+	case models.TicketEventEscalated:
+		text = "Incident escalated to " + strings.ToUpper(string(event.Ticket.Severity))
+	case models.TicketEventStatusChanged:
+		text = "Incident moved to " + string(event.Ticket.Status)
+	case models.TicketEventResolved:
+		text = "Incident resolved"
+	case models.TicketEventDeleted:
+		text = "Incident deleted"
+	case models.TicketEventUndeleted:
+		text = "Incident restored"
+	default:
+		return
+	}
+
+	go func() {
+		if err := s.PostIncidentCard(context.Background(), url, text, event.Ticket); err != nil {
+			slog.Error("Failed to post incident card", "ticketId", event.Ticket.ID, "error", err)
+		}
+	}()
+}
+
+// PostIncidentCard posts an incident card to an incoming-webhook URL
+func (s *ChatOpsService) PostIncidentCard(ctx context.Context, url, text string, ticket models.IncidentTicket) error {
+	message := dto.SlackMessage{
+		Text:   fmt.Sprintf("%s: %s", text, ticket.Title),
+		Blocks: s.incidentCard(ticket),
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack message: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build slack request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack returned status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *ChatOpsService) listOpenIncidents(ctx context.Context) dto.SlackMessage {
+	tickets, err := s.ticketService.GetAllTickets(ctx)
+	if err != nil {
+		return ephemeral("Failed to fetch incidents: " + err.Error())
+	}
+
+	var open []models.IncidentTicket
+	for _, ticket := range tickets {
+		if !models.IsResolvedStatus(ticket.Status) {
+			open = append(open, ticket)
+		}
+	}
+	if len(open) == 0 {
+		return ephemeral(":white_check_mark: No open incidents")
+	}
+
+	// Most severe first, newest first within a severity
+	sort.Slice(open, func(i, j int) bool {
+		ri, rj := models.SeverityRank(open[i].Severity), models.SeverityRank(open[j].Severity)
+		if ri != rj {
+			return ri > rj
+		}
+		return open[i].CreatedAt > open[j].CreatedAt
+	})
+
+	message := ephemeral(fmt.Sprintf("%d open incident(s)", len(open)))
+	for i, ticket := range open {
+		if i == maxListedIncidents {
+			message.Blocks = append(message.Blocks, noteBlock(fmt.Sprintf("…and %d more", len(open)-maxListedIncidents)))
+			break
+		}
+		message.Blocks = append(message.Blocks, s.incidentCard(ticket)...)
+	}
+	return message
+}
+
+func (s *ChatOpsService) acknowledge(ctx context.Context, id, user string) dto.SlackMessage {
+	ticket, err := s.ticketService.AcknowledgeTicket(ctx, id)
+	if err != nil {
+		return ephemeral("Failed to acknowledge incident: " + err.Error())
+	}
+	if ticket == nil {
+		return ephemeral("Incident not found: " + id)
+	}
+
+	return inChannel(fmt.Sprintf(":eyes: %s acknowledged by @%s", ticket.ID, user), s.incidentCard(*ticket))
+}
+
+func (s *ChatOpsService) resolve(ctx context.Context, id, note, user string) dto.SlackMessage {
+	ticket, err := s.ticketService.ResolveTicket(ctx, id, fmt.Sprintf("%s (by @%s)", note, user))
+	if err != nil {
+		return ephemeral("Failed to resolve incident: " + err.Error())
+	}
+	if ticket == nil {
+		return ephemeral("Incident not found: " + id)
+	}
+
+	return inChannel(fmt.Sprintf(":white_check_mark: %s resolved by @%s", ticket.ID, user), s.incidentCard(*ticket))
+}
+
+func (s *ChatOpsService) helpMessage(command string) dto.SlackMessage {
+	if command == "" {
+		command = "/incident"
+	}
+	return ephemeral(strings.Join([]string{
+		"Available commands:",
+		fmt.Sprintf("• `%s list` - show open incidents", command),
+		fmt.Sprintf("• `%s ack <id>` - acknowledge an incident", command),
+		fmt.Sprintf("• `%s resolve <id> <note>` - resolve an incident with a note", command),
+	}, "\n"))
+}
+
+// incidentCard renders a ticket as Block Kit blocks with ack/resolve buttons
+func (s *ChatOpsService) incidentCard(ticket models.IncidentTicket) []dto.SlackBlock {
+	title := fmt.Sprintf("%s *%s*\n`%s`", severityEmoji(ticket.Severity), ticket.Title, ticket.ID)
+	if s.dashboardURL != "" {
+		title = fmt.Sprintf("%s *<%s/incidents|%s>*\n`%s`",
+			severityEmoji(ticket.Severity), s.dashboardURL, ticket.Title, ticket.ID)
+	}
+
+	services := "-"
+	if len(ticket.AffectedServices) > 0 {
+		services = strings.Join(ticket.AffectedServices, ", ")
+	}
+
+	blocks := []dto.SlackBlock{
+		{
+			Type: "section",
+			Text: &dto.SlackText{Type: "mrkdwn", Text: title},
+			Fields: []dto.SlackText{
+				{Type: "mrkdwn", Text: "*Severity:*\n" + string(ticket.Severity)},
+				{Type: "mrkdwn", Text: "*Status:*\n" + string(ticket.Status)},
+				{Type: "mrkdwn", Text: "*Type:*\n" + string(ticket.IncidentType)},
+				{Type: "mrkdwn", Text: "*Environment:*\n" + string(ticket.Environment)},
+				{Type: "mrkdwn", Text: "*Services:*\n" + services},
+				{Type: "mrkdwn", Text: "*Created:*\n" + ticket.CreatedAt},
+			},
+		},
+	}
+
+	if !models.IsResolvedStatus(ticket.Status) {
+		var elements []dto.SlackElement
+		if ticket.Status != models.StatusInProgress {
+			elements = append(elements, dto.SlackElement{
+				Type:     "button",
+				Text:     &dto.SlackText{Type: "plain_text", Text: "Acknowledge"},
+				ActionID: SlackActionAck,
+				Value:    ticket.ID,
+			})
+		}
+		elements = append(elements, dto.SlackElement{
+			Type:     "button",
+			Text:     &dto.SlackText{Type: "plain_text", Text: "Resolve"},
+			ActionID: SlackActionResolve,
+			Value:    ticket.ID,
+			Style:    "primary",
+		})
+		blocks = append(blocks, dto.SlackBlock{Type: "actions", Elements: elements})
+	} else if ticket.ActionTaken != nil {
+		blocks = append(blocks, noteBlock("Action taken: "+*ticket.ActionTaken))
+	}
+
+	return append(blocks, dto.SlackBlock{Type: "divider"})
+}
+
+func noteBlock(text string) dto.SlackBlock {
+	return dto.SlackBlock{Type: "section", Text: &dto.SlackText{Type: "mrkdwn", Text: "_" + text + "_"}}
+}
+
+func ephemeral(text string) dto.SlackMessage {
+	return dto.SlackMessage{ResponseType: "ephemeral", Text: text}
+}
+
+func inChannel(text string, blocks []dto.SlackBlock) dto.SlackMessage {
+	header := dto.SlackBlock{Type: "section", Text: &dto.SlackText{Type: "mrkdwn", Text: text}}
+	return dto.SlackMessage{
+		ResponseType: "in_channel",
+		Text:         text,
+		Blocks:       append([]dto.SlackBlock{header}, blocks...),
+	}
+}
+
+func severityEmoji(severity models.Severity) string {
+	switch severity {
+	case models.SeverityCritical:
+		return ":red_circle:"
+	case models.SeverityHigh:
+		return ":large_orange_circle:"
+	case models.SeverityMedium:
+		return ":large_yellow_circle:"
+	default:
+		return ":white_circle:"
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"irs-be/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// EventHandler receives ticket lifecycle events
+type EventHandler func(event models.TicketEvent)
+
+// EventBus fans ticket lifecycle events out to in-process subscribers
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers []EventHandler
+}
+
+// NewEventBus creates an empty event bus
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe registers a handler for every published event.
+// Handlers run on the publisher's goroutine and must not block.
+func (b *EventBus) Subscribe(handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish delivers an event to every subscriber
+func (b *EventBus) Publish(eventType models.TicketEventType, ticket models.IncidentTicket, previous *models.IncidentTicket) {
+	if b == nil {
+		return
+	}
+
+	event := models.TicketEvent{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		OccurredAt: models.FormatTimestamp(time.Now()),
+		Ticket:     ticket,
+		Previous:   previous,
+	}
+
+	b.mu.RLock()
+	handlers := make([]EventHandler, len(b.handlers))
+	copy(handlers, b.handlers)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
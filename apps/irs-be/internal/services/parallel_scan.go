@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"irs-be/internal/config"
+	"irs-be/internal/logging"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// parallelScanner reads a whole table as TotalSegments parallel segment scans,
+// spread over a fixed pool of workers
+type parallelScanner struct {
+	client   dynamodb.ScanAPIClient
+	segments int
+	workers  int
+	limiter  *capacityLimiter
+}
+
+func newParallelScanner(client dynamodb.ScanAPIClient, cfg config.ScanConfig) *parallelScanner {
+	segments := cfg.TotalSegments
+	if segments < 1 {
+		segments = 1
+	}
+	workers := cfg.Workers
+	if workers < 1 || workers > segments {
+		workers = segments
+	}
+
+	return &parallelScanner{
+		client:   client,
+		segments: segments,
+		workers:  workers,
+		limiter:  newCapacityLimiter(cfg.MaxReadCapacity),
+	}
+}
+
+// scan runs base over every segment and calls fn once per page. Pages arrive in no
+// particular order, but fn is never called concurrently, so it may use state
+// without locking. The first error from DynamoDB or fn stops the whole scan.
+func (p *parallelScanner) scan(ctx context.Context, base *dynamodb.ScanInput, pageSize int32, fn func(items []map[string]types.AttributeValue) error) error {
+	started := time.Now()
+
+	segments := make(chan int32, p.segments)
+	for segment := 0; segment < p.segments; segment++ {
+		segments <- int32(segment)
+	}
+	close(segments)
+
+	var mu sync.Mutex
+	var pages, items int
+	var consumed float64
+	emit := func(page *dynamodb.ScanOutput) error {
+		mu.Lock()
+		defer mu.Unlock()
+		pages++
+		items += len(page.Items)
+		if page.ConsumedCapacity != nil {
+			consumed += aws.ToFloat64(page.ConsumedCapacity.CapacityUnits)
+		}
+		return fn(page.Items)
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for worker := 0; worker < p.workers; worker++ {
+		g.Go(func() error {
+			for segment := range segments {
+				input := *base
+				input.Segment = aws.Int32(segment)
+				input.TotalSegments = aws.Int32(int32(p.segments))
+				input.ReturnConsumedCapacity = types.ReturnConsumedCapacityTotal
+				if pageSize > 0 {
+					input.Limit = aws.Int32(pageSize)
+				}
+
+				paginator := dynamodb.NewScanPaginator(p.client, &input)
+				for paginator.HasMorePages() {
+					if err := p.limiter.wait(ctx); err != nil {
+						return err
+					}
+					page, err := paginator.NextPage(ctx)
+					if err != nil {
+						return err
+					}
+					if page.ConsumedCapacity != nil {
+						p.limiter.consume(aws.ToFloat64(page.ConsumedCapacity.CapacityUnits))
+					}
+					if err := emit(page); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	}
+
+	err := g.Wait()
+	logging.FromContext(ctx).Debug("Parallel scan finished",
+		"table", aws.ToString(base.TableName),
+		"segments", p.segments,
+		"workers", p.workers,
+		"pages", pages,
+		"items", items,
+		"consumedRCU", consumed,
+		"duration", time.Since(started).String(),
+		"error", err,
+	)
+	return err
+}
+
+// capacityLimiter paces scans to a read capacity budget shared by every scan of
+// the service, leaving the rest of the table's throughput to other readers.
+// Consumed capacity is only known once a page returns, so each page is paid for
+// by delaying the page after it. A nil limiter does not limit.
+type capacityLimiter struct {
+	perSecond float64
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newCapacityLimiter returns a limiter for perSecond read capacity units, or nil
+// when perSecond is not positive
+func newCapacityLimiter(perSecond float64) *capacityLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &capacityLimiter{perSecond: perSecond}
+}
+
+// wait blocks until the budget has recovered from earlier pages
+func (l *capacityLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	delay := time.Until(l.next)
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	return sleepContext(ctx, delay)
+}
+
+// consume charges units against the budget
+func (l *capacityLimiter) consume(units float64) {
+	if l == nil || units <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(units / l.perSecond * float64(time.Second)))
+}
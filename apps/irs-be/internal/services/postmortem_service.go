@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"irs-be/internal/config"
+	"irs-be/internal/dto"
+	"irs-be/internal/models"
+	"irs-be/internal/postmortem"
+	"irs-be/internal/tenant"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTicketNotFound              = errors.New("ticket not found")
+	ErrPostmortemNotFound          = errors.New("postmortem not found")
+	ErrActionItemNotFound          = errors.New("action item not found")
+	ErrPostmortemConflict          = errors.New("postmortem was modified concurrently, retry the request")
+	ErrUnsupportedPostmortemFormat = errors.New("unsupported postmortem format")
+)
+
+const (
+	dueDateLayout = "2006-01-02"
+	// maxPostmortemSaveTrials bounds optimistic-locking retries on concurrent edits
+	maxPostmortemSaveTrials = 3
+)
+
+// PostmortemService stores postmortems and renders postmortem documents
+type PostmortemService struct {
+	client        *dynamodb.Client
+	tableName     string
+	ticketService *TicketService
+	renderer      *postmortem.Renderer
+}
+
+// NewPostmortemService creates a new postmortem service instance
+func NewPostmortemService(client *dynamodb.Client, ticketService *TicketService, cfg config.Config) (*PostmortemService, error) {
+	renderer, err := postmortem.NewRenderer(cfg.Postmortem.TemplateDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load postmortem templates: %v", err)
+	}
+
+	return &PostmortemService{
+		client:        client,
+		tableName:     cfg.Postmortem.TableName,
+		ticketService: ticketService,
+		renderer:      renderer,
+	}, nil
+}
+
+// RenderDocument fills the postmortem template for a ticket in the given format
+func (s *PostmortemService) RenderDocument(ctx context.Context, ticketID string, format string) (string, error) {
+	if format != postmortem.FormatMarkdown && format != postmortem.FormatHTML {
+		return "", fmt.Errorf("%w %q", ErrUnsupportedPostmortemFormat, format)
+	}
+
+	ticket, err := s.ticketService.GetTicketByID(ctx, ticketID)
+	if err != nil {
+		return "", err
+	}
+	if ticket == nil {
+		return "", ErrTicketNotFound
+	}
+
+	pm, err := s.GetPostmortem(ctx, ticketID)
+	if err != nil && !errors.Is(err, ErrPostmortemNotFound) {
+		return "", err
+	}
+
+	return s.renderer.Render(postmortem.Build(*ticket, pm, time.Now()), format)
+}
+
+// GetPostmortem retrieves the postmortem of a ticket. Postmortems of teams out of
+// scope read as not found.
+func (s *PostmortemService) GetPostmortem(ctx context.Context, ticketID string) (*models.Postmortem, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"ticketId": &types.AttributeValueMemberS{Value: ticketID},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get postmortem: %v", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrPostmortemNotFound
+	}
+
+	var pm models.Postmortem
+	if err := attributevalue.UnmarshalMap(result.Item, &pm); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal postmortem: %v", err)
+	}
+	if !tenant.FromContext(ctx).Includes(pm.Team) {
+		return nil, ErrPostmortemNotFound
+	}
+	return &pm, nil
+}
+
+// ListPostmortems retrieves the postmortems in scope, newest first
+func (s *PostmortemService) ListPostmortems(ctx context.Context) ([]models.Postmortem, error) {
+	postmortems := []models.Postmortem{}
+	scope := tenant.FromContext(ctx)
+
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName: aws.String(s.tableName),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan postmortems: %v", err)
+		}
+
+		var batch []models.Postmortem
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &batch); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal postmortems: %v", err)
+		}
+		for _, pm := range batch {
+			if scope.Includes(pm.Team) {
+				postmortems = append(postmortems, pm)
+			}
+		}
+	}
+
+	sort.Slice(postmortems, func(i, j int) bool {
+		return postmortems[i].CreatedAt > postmortems[j].CreatedAt
+	})
+	return postmortems, nil
+}
+
+// UpsertPostmortem creates the postmortem of a ticket or updates its narrative fields
+func (s *PostmortemService) UpsertPostmortem(ctx context.Context, ticketID string, req dto.UpsertPostmortemRequest) (*models.Postmortem, error) {
+	return s.modify(ctx, ticketID, func(pm *models.Postmortem) error {
+		pm.Summary = req.Summary
+		pm.RootCause = req.RootCause
+		pm.LessonsLearned = req.LessonsLearned
+		if req.Author != "" {
+			pm.Author = req.Author
+		}
+		return nil
+	})
+}
+
+// AddActionItem adds an action item, creating the postmortem if needed
+func (s *PostmortemService) AddActionItem(ctx context.Context, ticketID string, req dto.CreateActionItemRequest) (*models.Postmortem, error) {
+	return s.modify(ctx, ticketID, func(pm *models.Postmortem) error {
+		pm.ActionItems = append(pm.ActionItems, models.ActionItem{
+			ID:          uuid.NewString()[:8],
+			Description: req.Description,
+			Owner:       req.Owner,
+			DueDate:     req.DueDate,
+			Status:      models.ActionItemOpen,
+			CreatedAt:   models.FormatTimestamp(time.Now()),
+		})
+		return nil
+	})
+}
+
+// UpdateActionItem changes an action item; moving it to done records the completion time
+func (s *PostmortemService) UpdateActionItem(ctx context.Context, ticketID, itemID string, req dto.UpdateActionItemRequest) (*models.Postmortem, error) {
+	return s.modifyExisting(ctx, ticketID, func(pm *models.Postmortem) error {
+		for i := range pm.ActionItems {
+			item := &pm.ActionItems[i]
+			if item.ID != itemID {
+				continue
+			}
+
+			if req.Description != nil {
+				item.Description = *req.Description
+			}
+			if req.Owner != nil {
+				item.Owner = *req.Owner
+			}
+			if req.DueDate != nil {
+				item.DueDate = *req.DueDate
+			}
+			if req.Status != nil {
+				status := models.ActionItemStatus(*req.Status)
+				if status == models.ActionItemDone && item.Status != models.ActionItemDone {
+					item.CompletedAt = models.FormatTimestamp(time.Now())
+				} else if status != models.ActionItemDone {
+					item.CompletedAt = ""
+				}
+				item.Status = status
+			}
+			return nil
+		}
+		return ErrActionItemNotFound
+	})
+}
+
+// DeleteActionItem removes an action item from a postmortem
+func (s *PostmortemService) DeleteActionItem(ctx context.Context, ticketID, itemID string) (*models.Postmortem, error) {
+	return s.modifyExisting(ctx, ticketID, func(pm *models.Postmortem) error {
+		for i, item := range pm.ActionItems {
+			if item.ID == itemID {
+				pm.ActionItems = append(pm.ActionItems[:i], pm.ActionItems[i+1:]...)
+				return nil
+			}
+		}
+		return ErrActionItemNotFound
+	})
+}
+
+// ListActionItems lists action items across all postmortems, optionally filtered by
+// status, owner and whether they are past their due date
+func (s *PostmortemService) ListActionItems(ctx context.Context, status, owner string, overdueOnly bool) ([]dto.ActionItemResponse, error) {
+	postmortems, err := s.ListPostmortems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC().Format(dueDateLayout)
+	items := []dto.ActionItemResponse{}
+	for _, pm := range postmortems {
+		for _, item := range pm.ActionItems {
+			overdue := item.Status != models.ActionItemDone && item.DueDate != "" && item.DueDate < today
+			if status != "" && string(item.Status) != status {
+				continue
+			}
+			if owner != "" && item.Owner != owner {
+				continue
+			}
+			if overdueOnly && !overdue {
+				continue
+			}
+
+			items = append(items, dto.ActionItemResponse{
+				TicketID:    pm.TicketID,
+				ID:          item.ID,
+				Description: item.Description,
+				Owner:       item.Owner,
+				DueDate:     item.DueDate,
+				Status:      string(item.Status),
+				Overdue:     overdue,
+			})
+		}
+	}
+
+	// Earliest due date first, items without a due date last
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].DueDate == "" || items[j].DueDate == "" {
+			return items[j].DueDate == "" && items[i].DueDate != ""
+		}
+		return items[i].DueDate < items[j].DueDate
+	})
+	return items, nil
+}
+
+// modify applies a change to the ticket's postmortem, creating it when missing. The
+// postmortem takes the team of its ticket.
+func (s *PostmortemService) modify(ctx context.Context, ticketID string, change func(pm *models.Postmortem) error) (*models.Postmortem, error) {
+	ticket, err := s.ticketService.GetTicketByID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket == nil {
+		return nil, ErrTicketNotFound
+	}
+
+	return s.save(ctx, ticketID, true, func(pm *models.Postmortem) error {
+		pm.Team = ticket.Team
+		return change(pm)
+	})
+}
+
+// modifyExisting applies a change to an existing postmortem
+func (s *PostmortemService) modifyExisting(ctx context.Context, ticketID string, change func(pm *models.Postmortem) error) (*models.Postmortem, error) {
+	return s.save(ctx, ticketID, false, change)
+}
+
+// save runs a read-modify-write cycle guarded by the version attribute, retrying
+// when another request saved the same postmortem in between
+func (s *PostmortemService) save(ctx context.Context, ticketID string, create bool, change func(pm *models.Postmortem) error) (*models.Postmortem, error) {
+	for trial := 0; trial < maxPostmortemSaveTrials; trial++ {
+		pm, err := s.GetPostmortem(ctx, ticketID)
+		switch {
+		case errors.Is(err, ErrPostmortemNotFound) && create:
+			now := models.FormatTimestamp(time.Now())
+			pm = &models.Postmortem{TicketID: ticketID, ActionItems: []models.ActionItem{}, CreatedAt: now}
+		case err != nil:
+			return nil, err
+		}
+
+		previousVersion := pm.Version
+		if err := change(pm); err != nil {
+			return nil, err
+		}
+		pm.Version = previousVersion + 1
+		pm.UpdatedAt = models.FormatTimestamp(time.Now())
+		pm.Completed = len(pm.ActionItems) > 0 && pm.OpenActionItems() == 0
+
+		err = s.put(ctx, *pm, previousVersion)
+		if err == nil {
+			return pm, nil
+		}
+		if !errors.Is(err, ErrPostmortemConflict) {
+			return nil, err
+		}
+	}
+
+	return nil, ErrPostmortemConflict
+}
+
+func (s *PostmortemService) put(ctx context.Context, pm models.Postmortem, previousVersion int) error {
+	item, err := attributevalue.MarshalMap(pm)
+	if err != nil {
+		return fmt.Errorf("failed to marshal postmortem: %v", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(ticketId) OR #version = :previousVersion"),
+		ExpressionAttributeNames: map[string]string{
+			"#version": "version",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":previousVersion": &types.AttributeValueMemberN{Value: fmt.Sprint(previousVersion)},
+		},
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return ErrPostmortemConflict
+		}
+		return fmt.Errorf("failed to put postmortem: %v", err)
+	}
+	return nil
+}
+
+// ValidDueDate reports whether a due date uses the YYYY-MM-DD format
+func ValidDueDate(date string) bool {
+	_, err := time.Parse(dueDateLayout, date)
+	return err == nil
+}
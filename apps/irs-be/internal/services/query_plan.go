@@ -0,0 +1,263 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"irs-be/internal/models"
+	"irs-be/internal/tenant"
+	"irs-be/internal/ticketcodec"
+	"irs-be/internal/ticketquery"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ticketIndex is a GSI keyed on a single ticket attribute
+type ticketIndex struct {
+	name string
+	// field is the query language field of the key attribute
+	field     string
+	attribute string
+	// fallbackRank orders indexes by typical selectivity when no snapshot is
+	// available to count from; lower is more selective
+	fallbackRank int
+}
+
+var ticketIndexes = []ticketIndex{
+	{name: "IncidentTypeIndex", field: "type", attribute: "insident_type", fallbackRank: 0},
+	{name: "SeverityIndex", field: "severity", attribute: "severity", fallbackRank: 1},
+	{name: "StatusIndex", field: "status", attribute: "status", fallbackRank: 2},
+}
+
+// listAttributes is every stored ticket attribute except the report, which can be
+// many kilobytes and is only shown on the detail view
+var listAttributes = ticketAttributes("report")
+
+// ticketAttributes lists the DynamoDB attribute names of IncidentTicket, minus
+// exclude, and the attributes the codec reads besides them
+func ticketAttributes(exclude ...string) []string {
+	names := append([]string{}, ticketcodec.ReadAttributes...)
+	t := reflect.TypeOf(models.IncidentTicket{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("dynamodbav"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		excluded := false
+		for _, e := range exclude {
+			excluded = excluded || name == e
+		}
+		if !excluded {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// QueryPlan describes how a filtered ticket list is read from DynamoDB
+type QueryPlan struct {
+	// Query is the whole query; every ticket read is checked against it in memory
+	Query ticketquery.Node
+	// Index is the GSI queried, or empty for a parallel table scan
+	Index string
+	// KeyAttribute and KeyValue form the key condition of an index query
+	KeyAttribute string
+	KeyValue     string
+	// Pushed are the terms sent to DynamoDB as a FilterExpression
+	Pushed []ticketquery.Node
+	// InMemory are the terms DynamoDB cannot evaluate exactly, applied after reading
+	InMemory []ticketquery.Node
+	// ReadsReport is set when free text needs the report, which is otherwise not read
+	ReadsReport bool
+	// Estimate is the number of tickets the key condition was expected to match,
+	// or -1 when the index was chosen without a count
+	Estimate int
+	// Scope limits the plan to the tickets of a team
+	Scope tenant.Scope
+}
+
+// String renders the plan for the debug header, e.g.
+// "query SeverityIndex severity=critical estimate=12; filter status:open; team payments; memory "disk"; projection with report"
+func (p QueryPlan) String() string {
+	var parts []string
+	if p.Index == "" {
+		parts = append(parts, "scan")
+	} else {
+		part := fmt.Sprintf("query %s %s=%s", p.Index, p.KeyAttribute, p.KeyValue)
+		if p.Estimate >= 0 {
+			part += fmt.Sprintf(" estimate=%d", p.Estimate)
+		}
+		parts = append(parts, part)
+	}
+	if len(p.Pushed) > 0 {
+		parts = append(parts, "filter "+ticketquery.And(p.Pushed).String())
+	}
+	if !p.Scope.IsAll() {
+		parts = append(parts, "team "+p.Scope.Team())
+	}
+	if len(p.InMemory) > 0 {
+		parts = append(parts, "memory "+ticketquery.And(p.InMemory).String())
+	}
+	if p.ReadsReport {
+		parts = append(parts, "projection with report")
+	} else {
+		parts = append(parts, "projection without report")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// planQuery picks the GSI whose key condition matches the fewest tickets and pushes
+// the other terms DynamoDB can evaluate into a FilterExpression. count estimates
+// how many tickets have an attribute value; it returns false when no estimate is
+// available.
+func planQuery(query ticketquery.Node, count func(attribute, value string) (int, bool), now time.Time) QueryPlan {
+	plan := QueryPlan{Query: query, ReadsReport: ticketquery.UsesText(query), Estimate: -1}
+	conjuncts := ticketquery.Conjuncts(query)
+
+	// Prefer the index matching the fewest tickets; without a count for every
+	// candidate, fall back to the usual selectivity order
+	type candidate struct {
+		index     ticketIndex
+		conjunct  int
+		value     string
+		estimate  int
+		estimated bool
+	}
+	var candidates []candidate
+	allCounted := true
+	for i, node := range conjuncts {
+		term, ok := node.(ticketquery.Term)
+		if !ok || len(term.Values) != 1 || term.Values[0] == "" {
+			continue
+		}
+		for _, index := range ticketIndexes {
+			if index.field != term.Field {
+				continue
+			}
+			c := candidate{index: index, conjunct: i, value: term.Values[0]}
+			c.estimate, c.estimated = count(index.attribute, c.value)
+			allCounted = allCounted && c.estimated
+			candidates = append(candidates, c)
+		}
+	}
+
+	var best *candidate
+	for i := range candidates {
+		c := &candidates[i]
+		if best == nil ||
+			(allCounted && c.estimate < best.estimate) ||
+			(!allCounted && c.index.fallbackRank < best.index.fallbackRank) {
+			best = c
+		}
+	}
+
+	if best != nil {
+		plan.Index = best.index.name
+		plan.KeyAttribute = best.index.attribute
+		plan.KeyValue = best.value
+		if allCounted {
+			plan.Estimate = best.estimate
+		}
+	}
+
+	for i, node := range conjuncts {
+		if best != nil && i == best.conjunct {
+			continue
+		}
+		_, ok, exact := ticketquery.Compile(node, ticketquery.NewBuilder(), now)
+		if ok {
+			plan.Pushed = append(plan.Pushed, node)
+		}
+		if !ok || !exact {
+			plan.InMemory = append(plan.InMemory, node)
+		}
+	}
+	return plan
+}
+
+// planExpression holds the key condition, filter and projection of a plan
+type planExpression struct {
+	keyCondition *string
+	filter       *string
+	projection   *string
+	names        map[string]string
+	values       map[string]types.AttributeValue
+}
+
+// expression builds the DynamoDB expressions of the plan, reading only projection
+func (p QueryPlan) expression(projection []string, now time.Time) planExpression {
+	b := ticketquery.NewBuilder()
+	var expr planExpression
+
+	if p.Index != "" {
+		expr.keyCondition = aws.String(b.Name(p.KeyAttribute) + " = " + b.Value(p.KeyValue))
+	}
+	var filters []string
+	if len(p.Pushed) > 0 {
+		if condition, ok, _ := ticketquery.Compile(ticketquery.And(p.Pushed), b, now); ok {
+			filters = append(filters, condition)
+		}
+	}
+	if team := scopeCondition(p.Scope, b); team != "" {
+		filters = append(filters, team)
+	}
+	if len(filters) == 1 {
+		expr.filter = aws.String(filters[0])
+	} else if len(filters) > 1 {
+		expr.filter = aws.String("(" + filters[0] + ") AND " + filters[1])
+	}
+
+	placeholders := make([]string, len(projection))
+	for i, name := range projection {
+		placeholders[i] = b.Name(name)
+	}
+	expr.projection = aws.String(strings.Join(placeholders, ", "))
+	expr.names = b.Names
+	expr.values = b.Values
+	return expr
+}
+
+// queryInput returns the Query input of an index plan
+func (e planExpression) queryInput(tableName, index string) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		TableName:                 aws.String(tableName),
+		IndexName:                 aws.String(index),
+		KeyConditionExpression:    e.keyCondition,
+		FilterExpression:          e.filter,
+		ProjectionExpression:      e.projection,
+		ExpressionAttributeNames:  e.names,
+		ExpressionAttributeValues: e.values,
+	}
+}
+
+// scanInput returns the Scan input of a plan without an index
+func (e planExpression) scanInput(tableName string) *dynamodb.ScanInput {
+	input := &dynamodb.ScanInput{
+		TableName:                aws.String(tableName),
+		FilterExpression:         e.filter,
+		ProjectionExpression:     e.projection,
+		ExpressionAttributeNames: e.names,
+	}
+	// DynamoDB rejects an empty value map
+	if len(e.values) > 0 {
+		input.ExpressionAttributeValues = e.values
+	}
+	return input
+}
+
+// indexAttribute returns the value of an indexed attribute of a ticket
+func indexAttribute(ticket models.IncidentTicket, attribute string) string {
+	switch attribute {
+	case "status":
+		return string(ticket.Status)
+	case "severity":
+		return string(ticket.Severity)
+	case "insident_type":
+		return string(ticket.IncidentType)
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"irs-be/internal/config"
+	"irs-be/internal/llm"
+	"irs-be/internal/models"
+)
+
+var ErrReportGenerationDisabled = errors.New("report generation is not configured: set OLLAMA_ENDPOINT")
+
+// ReportService regenerates incident reports and suggestions with an LLM
+type ReportService struct {
+	ticketService  *TicketService
+	client         *llm.OllamaClient
+	defaultVersion string
+}
+
+// NewReportService creates a new report service instance.
+// Without an Ollama endpoint every regeneration fails with ErrReportGenerationDisabled.
+func NewReportService(ticketService *TicketService, cfg config.Config) *ReportService {
+	var client *llm.OllamaClient
+	if cfg.Ollama.Endpoint != "" {
+		client = llm.NewOllamaClient(cfg.Ollama.Endpoint, cfg.Ollama.Model, cfg.Ollama.Timeout, cfg.Ollama.MaxRetries)
+	}
+
+	return &ReportService{
+		ticketService:  ticketService,
+		client:         client,
+		defaultVersion: cfg.Ollama.PromptVersion,
+	}
+}
+
+// PromptVersions lists the available prompt templates
+func (s *ReportService) PromptVersions() []*llm.PromptTemplate {
+	return llm.PromptTemplates()
+}
+
+// RegenerateReport builds the prompt from the ticket, calls the model and parses the
+// result. Unless dryRun is set the report is saved on the ticket. It returns nil when
+// the ticket does not exist.
+func (s *ReportService) RegenerateReport(ctx context.Context, id string, version string, dryRun bool) (*models.ReportResult, error) {
+	if s.client == nil {
+		return nil, ErrReportGenerationDisabled
+	}
+
+	if version == "" {
+		version = s.defaultVersion
+	}
+	tmpl, err := llm.GetPromptTemplate(version)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.ticketService.GetTicketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ticket == nil {
+		return nil, nil
+	}
+
+	data := promptDataFromTicket(*ticket)
+	report, suggestions, err := s.generate(ctx, tmpl, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ReportResult{
+		TicketID:    ticket.ID,
+		Report:      report,
+		Suggestions: suggestions,
+		Meta: models.ReportMeta{
+			PromptVersion: tmpl.Version,
+			Model:         s.client.Model(),
+			GeneratedAt:   models.FormatTimestamp(time.Now()),
+		},
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	updated, err := s.ticketService.UpdateReport(ctx, ticket.ID, result.Report, result.Suggestions, result.Meta)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, nil // Deleted while the model was running
+	}
+
+	result.Saved = true
+	return result, nil
+}
+
+func (s *ReportService) generate(ctx context.Context, tmpl *llm.PromptTemplate, data llm.PromptData) (string, []string, error) {
+	reportPrompt, err := tmpl.RenderReport(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	output, err := s.client.Generate(ctx, reportPrompt, llm.DefaultGenerateOptions)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if tmpl.Combined {
+		report, suggestionsText := llm.SplitCombined(output)
+		return report, llm.ParseSuggestions(suggestionsText), nil
+	}
+
+	suggestionsPrompt, err := tmpl.RenderSuggestions(data)
+	if err != nil {
+		return "", nil, err
+	}
+	suggestionsText, err := s.client.Generate(ctx, suggestionsPrompt, llm.DefaultGenerateOptions)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return output, llm.ParseSuggestions(suggestionsText), nil
+}
+
+func promptDataFromTicket(ticket models.IncidentTicket) llm.PromptData {
+	data := llm.PromptData{
+		ID:               ticket.ID,
+		Title:            ticket.Title,
+		IncidentType:     string(ticket.IncidentType),
+		Severity:         string(ticket.Severity),
+		Environment:      string(ticket.Environment),
+		Status:           string(ticket.Status),
+		Description:      ticket.Description,
+		AffectedServices: ticket.AffectedServices,
+		CreatedAt:        ticket.CreatedAt,
+		Context:          llm.IncidentContext(string(ticket.IncidentType), string(ticket.Environment), string(ticket.Severity)),
+	}
+	if ticket.ActionTaken != nil {
+		data.ActionTaken = *ticket.ActionTaken
+	}
+	return data
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"irs-be/internal/config"
+	"irs-be/internal/metrics"
+	"irs-be/internal/resilience"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// ticketStore is the subset of the DynamoDB API used by the ticket service
+type ticketStore interface {
+	dynamodb.ScanAPIClient
+	dynamodb.QueryAPIClient
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+}
+
+// ticketBreakerName labels the ticket table breaker in metrics and logs
+const ticketBreakerName = "dynamodb-tickets"
+
+// resilientDynamoDB retries throttled and transient DynamoDB failures with jittered
+// backoff and fails fast through a circuit breaker while DynamoDB keeps failing
+type resilientDynamoDB struct {
+	client  *dynamodb.Client
+	breaker *resilience.Breaker
+	policy  resilience.RetryPolicy
+}
+
+func newResilientDynamoDB(client *dynamodb.Client, cfg config.Config) *resilientDynamoDB {
+	metrics.SetCircuitState(ticketBreakerName, int(resilience.StateClosed))
+	breaker := resilience.NewBreaker(cfg.Resilience.FailureThreshold, cfg.Resilience.OpenTimeout, func(state resilience.State) {
+		slog.Warn("Circuit breaker changed state", "breaker", ticketBreakerName, "state", state.String())
+		metrics.SetCircuitState(ticketBreakerName, int(state))
+	})
+
+	return &resilientDynamoDB{
+		client:  client,
+		breaker: breaker,
+		policy: resilience.RetryPolicy{
+			MaxAttempts: cfg.Resilience.MaxAttempts,
+			BaseDelay:   cfg.Resilience.BaseDelay,
+			MaxDelay:    cfg.Resilience.MaxDelay,
+		},
+	}
+}
+
+// call runs one DynamoDB operation through the retry policy and breaker. The SDK's
+// own retries are turned off for these calls so attempts are not multiplied.
+func call[In any, Out any](
+	r *resilientDynamoDB,
+	ctx context.Context,
+	operation string,
+	params *In,
+	fn func(context.Context, *In, ...func(*dynamodb.Options)) (*Out, error),
+	optFns []func(*dynamodb.Options),
+) (*Out, error) {
+	optFns = append(optFns, func(o *dynamodb.Options) {
+		o.RetryMaxAttempts = 1
+	})
+
+	var out *Out
+	err := resilience.Do(ctx, r.breaker, r.policy,
+		func(error) { metrics.IncDynamoDBRetry(operation) },
+		func(ctx context.Context) error {
+			var err error
+			out, err = fn(ctx, params, optFns...)
+			return err
+		},
+	)
+	if err == resilience.ErrCircuitOpen {
+		resilience.MarkRejected(ctx, r.breaker.RetryAfter())
+	}
+	return out, err
+}
+
+func (r *resilientDynamoDB) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return call(r, ctx, "Scan", params, r.client.Scan, optFns)
+}
+
+func (r *resilientDynamoDB) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return call(r, ctx, "Query", params, r.client.Query, optFns)
+}
+
+func (r *resilientDynamoDB) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return call(r, ctx, "GetItem", params, r.client.GetItem, optFns)
+}
+
+func (r *resilientDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return call(r, ctx, "PutItem", params, r.client.PutItem, optFns)
+}
+
+func (r *resilientDynamoDB) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return call(r, ctx, "UpdateItem", params, r.client.UpdateItem, optFns)
+}
+
+func (r *resilientDynamoDB) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	return call(r, ctx, "TransactWriteItems", params, r.client.TransactWriteItems, optFns)
+}
+
+func (r *resilientDynamoDB) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	return call(r, ctx, "DescribeTable", params, r.client.DescribeTable, optFns)
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"irs-be/internal/logging"
+	"irs-be/internal/metrics"
+	"irs-be/internal/models"
+	"irs-be/internal/ticketcodec"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxDecodeFailures bounds the undecodable items remembered for the admin API
+const maxDecodeFailures = 100
+
+// DecodeFailure describes a ticket item that could not be decoded
+type DecodeFailure struct {
+	ID            string `json:"id"`
+	SchemaVersion int    `json:"schemaVersion"`
+	Error         string `json:"error"`
+	LastSeenAt    string `json:"lastSeenAt"`
+}
+
+// decodeFailures remembers the latest failure of each undecodable item. An item
+// that decodes again, after a fix or a new upgrader, is forgotten.
+type decodeFailures struct {
+	mu       sync.Mutex
+	max      int
+	failures map[string]DecodeFailure
+}
+
+func newDecodeFailures(max int) *decodeFailures {
+	return &decodeFailures{max: max, failures: map[string]DecodeFailure{}}
+}
+
+func (f *decodeFailures) record(failure DecodeFailure) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.failures[failure.ID]; !ok && len(f.failures) >= f.max {
+		oldest := ""
+		for id, existing := range f.failures {
+			if oldest == "" || existing.LastSeenAt < f.failures[oldest].LastSeenAt {
+				oldest = id
+			}
+		}
+		delete(f.failures, oldest)
+	}
+	f.failures[failure.ID] = failure
+}
+
+func (f *decodeFailures) forget(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.failures, id)
+}
+
+func (f *decodeFailures) list() []DecodeFailure {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := make([]DecodeFailure, 0, len(f.failures))
+	for _, failure := range f.failures {
+		list = append(list, failure)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// decodeTicket maps an item to a ticket. Items that cannot be decoded are logged,
+// counted and listed by DecodeFailures instead of being dropped silently; list
+// reads skip them and single reads return the error.
+func (s *TicketService) decodeTicket(ctx context.Context, item map[string]types.AttributeValue) (models.IncidentTicket, error) {
+	ticket, err := ticketcodec.Decode(item)
+	if err == nil {
+		s.failures.forget(ticket.ID)
+		return ticket, nil
+	}
+
+	failure := DecodeFailure{Error: err.Error(), LastSeenAt: models.FormatTimestamp(time.Now())}
+	var decodeErr *ticketcodec.DecodeError
+	if errors.As(err, &decodeErr) {
+		failure.ID = decodeErr.ID
+		failure.SchemaVersion = decodeErr.Version
+	}
+	s.failures.record(failure)
+	metrics.IncTicketDecodeFailure()
+	logging.FromContext(ctx).Error("Failed to decode ticket", "id", failure.ID, "schemaVersion", failure.SchemaVersion, "error", err)
+	return models.IncidentTicket{}, err
+}
+
+// DecodeFailures lists the ticket items that could not be decoded when last read
+func (s *TicketService) DecodeFailures() []DecodeFailure {
+	return s.failures.list()
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"irs-be/internal/dto"
+	"irs-be/internal/models"
+	"irs-be/internal/tenant"
+	"irs-be/internal/ticketcodec"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var (
+	ErrInvalidLink  = errors.New("invalid link")
+	ErrLinkExists   = errors.New("conflicting link")
+	ErrLinkNotFound = errors.New("link not found")
+	ErrLinkConflict = errors.New("ticket was modified concurrently, retry the request")
+)
+
+const (
+	// MaxLinkDepth bounds how far a link graph reaches from its ticket
+	MaxLinkDepth = 5
+	// maxLinkSaveTrials bounds optimistic-locking retries on concurrent writes
+	maxLinkSaveTrials = 3
+	// maxLinkWalk bounds the tickets visited checking for cycles and cascading
+	maxLinkWalk = 200
+)
+
+// LinkTickets links a ticket to another one, which gets the inverse link. A ticket
+// is a child or a duplicate of at most one ticket, and child-of, duplicate-of and
+// caused-by links never form a cycle. Linking tickets that are already linked the
+// same way changes nothing.
+func (s *TicketService) LinkTickets(ctx context.Context, id string, req dto.CreateLinkRequest) (*models.IncidentTicket, error) {
+	if id == req.TicketID {
+		return nil, fmt.Errorf("%w: a ticket cannot be linked to itself", ErrInvalidLink)
+	}
+
+	return s.saveLinks(ctx, id, req.TicketID, true, func(ticket, linked *models.IncidentTicket) (bool, error) {
+		if existing, ok := ticket.Link(linked.ID); ok {
+			if existing.Type == req.Type {
+				return false, nil
+			}
+			return false, fmt.Errorf("%w: the tickets are already linked as %s, remove that link first", ErrLinkExists, existing.Type)
+		}
+		if req.Type == models.LinkChildOf || req.Type == models.LinkDuplicateOf {
+			if ids := ticket.LinkedIDs(req.Type); len(ids) > 0 {
+				return false, fmt.Errorf("%w: the ticket is already %s %s", ErrLinkExists, req.Type, ids[0])
+			}
+		}
+		if req.Type != models.LinkRelated {
+			cycle, err := s.reaches(ctx, *linked, ticket.ID, req.Type)
+			if err != nil {
+				return false, err
+			}
+			if cycle {
+				return false, fmt.Errorf("%w: %s %s would create a cycle", ErrInvalidLink, req.Type, linked.ID)
+			}
+		}
+
+		now := models.FormatTimestamp(time.Now())
+		ticket.Links = append(ticket.Links, models.TicketLink{Type: req.Type, TicketID: linked.ID, CreatedAt: now})
+		// Replace an inverse link left behind by an unlink the linked ticket missed
+		linked.Links = append(withoutLink(linked.Links, ticket.ID), models.TicketLink{Type: req.Type.Inverse(), TicketID: ticket.ID, CreatedAt: now})
+		return true, nil
+	})
+}
+
+// UnlinkTickets removes the link between two tickets from both. A linked ticket that
+// is soft deleted or archived keeps its side of the link.
+func (s *TicketService) UnlinkTickets(ctx context.Context, id, linkedID string) (*models.IncidentTicket, error) {
+	return s.saveLinks(ctx, id, linkedID, false, func(ticket, linked *models.IncidentTicket) (bool, error) {
+		if _, ok := ticket.Link(linkedID); !ok {
+			return false, ErrLinkNotFound
+		}
+		ticket.Links = withoutLink(ticket.Links, linkedID)
+		if linked != nil {
+			linked.Links = withoutLink(linked.Links, ticket.ID)
+		}
+		return true, nil
+	})
+}
+
+// ResolveChildren gives the children of a resolved ticket its status, and their
+// children in turn. Children that are already resolved, removed or outside the
+// scope keep their status. It returns the IDs of the tickets it resolved; a child
+// that cannot be resolved does not stop the others and is reported in the error.
+func (s *TicketService) ResolveChildren(ctx context.Context, parent models.IncidentTicket) ([]string, error) {
+	scope := tenant.FromContext(ctx)
+	resolved := []string{}
+	var errs []error
+
+	visited := map[string]bool{parent.ID: true}
+	queue := []models.IncidentTicket{parent}
+	for len(queue) > 0 && len(visited) < maxLinkWalk {
+		current := queue[0]
+		queue = queue[1:]
+		for _, childID := range current.LinkedIDs(models.LinkParentOf) {
+			if visited[childID] {
+				continue
+			}
+			visited[childID] = true
+
+			child, err := s.loadTicketByID(ctx, childID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", childID, err))
+				continue
+			}
+			if child == nil || child.Removed() || !scope.Includes(child.Team) {
+				continue
+			}
+			if !models.IsResolvedStatus(child.Status) {
+				req := dto.UpdateTicketRequest{Status: &parent.Status}
+				if child.ActionTaken == nil {
+					note := "Resolved with parent " + current.ID
+					req.ActionTaken = &note
+				}
+				updated, err := s.UpdateTicket(ctx, childID, req)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %v", childID, err))
+					continue
+				}
+				if updated == nil {
+					continue
+				}
+				resolved = append(resolved, childID)
+				child = updated
+			}
+			queue = append(queue, *child)
+		}
+	}
+	return resolved, errors.Join(errs...)
+}
+
+// LinkGraph walks the links of a ticket breadth first, up to depth links away. It
+// returns nil when the ticket does not exist. Linked tickets that are removed or
+// outside the scope are left out, with their edges.
+func (s *TicketService) LinkGraph(ctx context.Context, id string, depth int) (*models.LinkGraph, error) {
+	root, err := s.GetTicketByID(ctx, id)
+	if err != nil || root == nil {
+		return nil, err
+	}
+
+	graph := &models.LinkGraph{Root: root.ID, Nodes: []models.LinkNode{}, Edges: []models.LinkEdge{}}
+	tickets := map[string]*models.IncidentTicket{root.ID: root}
+	depths := map[string]int{root.ID: 0}
+	missing := map[string]bool{}
+	queue := []string{root.ID}
+	for len(queue) > 0 {
+		current := tickets[queue[0]]
+		queue = queue[1:]
+		graph.Nodes = append(graph.Nodes, models.LinkNode{
+			ID:           current.ID,
+			Title:        current.Title,
+			Status:       current.Status,
+			Severity:     current.Severity,
+			IncidentType: current.IncidentType,
+			Team:         current.Team,
+			CreatedAt:    current.CreatedAt,
+			Depth:        depths[current.ID],
+		})
+
+		for _, link := range current.Links {
+			if _, seen := tickets[link.TicketID]; seen || missing[link.TicketID] {
+				continue
+			}
+			if depths[current.ID] >= depth {
+				graph.Truncated = true
+				continue
+			}
+			linked, err := s.GetTicketByID(ctx, link.TicketID)
+			if err != nil {
+				return nil, err
+			}
+			if linked == nil {
+				missing[link.TicketID] = true
+				continue
+			}
+			tickets[linked.ID] = linked
+			depths[linked.ID] = depths[current.ID] + 1
+			queue = append(queue, linked.ID)
+		}
+	}
+
+	// Both tickets hold a link; each becomes one edge in the direction it was created
+	seen := map[models.LinkEdge]bool{}
+	for _, node := range graph.Nodes {
+		for _, link := range tickets[node.ID].Links {
+			if _, ok := tickets[link.TicketID]; !ok {
+				continue
+			}
+			edge := models.LinkEdge{From: node.ID, To: link.TicketID, Type: link.Type}
+			if !link.Type.IsCreatable() {
+				edge = models.LinkEdge{From: link.TicketID, To: node.ID, Type: link.Type.Inverse()}
+			}
+			if edge.Type == models.LinkRelated && edge.From > edge.To {
+				edge.From, edge.To = edge.To, edge.From
+			}
+			if !seen[edge] {
+				seen[edge] = true
+				graph.Edges = append(graph.Edges, edge)
+			}
+		}
+	}
+	return graph, nil
+}
+
+// reaches reports whether target can be reached from a ticket by following links of
+// one type
+func (s *TicketService) reaches(ctx context.Context, from models.IncidentTicket, target string, linkType models.LinkType) (bool, error) {
+	visited := map[string]bool{from.ID: true}
+	queue := from.LinkedIDs(linkType)
+	for len(queue) > 0 && len(visited) < maxLinkWalk {
+		id := queue[0]
+		queue = queue[1:]
+		if id == target {
+			return true, nil
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		ticket, err := s.loadTicketByID(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		if ticket != nil {
+			queue = append(queue, ticket.LinkedIDs(linkType)...)
+		}
+	}
+	return false, nil
+}
+
+// saveLinks runs a read-modify-write cycle over the links of two tickets, guarded
+// by their write IDs and retried when either was written in between. change edits
+// the links of both and reports whether anything changed. linked is nil when the
+// linked ticket does not exist, is removed or outside the scope, which is an error
+// unless requireLinked is false; only the ticket's links are saved then.
+func (s *TicketService) saveLinks(ctx context.Context, id, linkedID string, requireLinked bool, change func(ticket, linked *models.IncidentTicket) (bool, error)) (*models.IncidentTicket, error) {
+	for trial := 0; trial < maxLinkSaveTrials; trial++ {
+		ticket, writeID, err := s.loadForLinking(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if ticket == nil {
+			return nil, ErrTicketNotFound
+		}
+		linked, linkedWriteID, err := s.loadForLinking(ctx, linkedID)
+		if err != nil {
+			return nil, err
+		}
+		if linked == nil && requireLinked {
+			return nil, fmt.Errorf("linked %w", ErrTicketNotFound)
+		}
+
+		changed, err := change(ticket, linked)
+		if err != nil {
+			return nil, err
+		}
+		if !changed {
+			return ticket, nil
+		}
+
+		items := []types.TransactWriteItem{}
+		item, err := s.linkUpdate(ctx, *ticket, writeID)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if linked != nil {
+			if item, err = s.linkUpdate(ctx, *linked, linkedWriteID); err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+
+		_, err = s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+		if err == nil {
+			s.InvalidateTickets(id, linkedID)
+			return ticket, nil
+		}
+		// A condition failed or another transaction wrote one of the tickets; read them again
+		var canceledErr *types.TransactionCanceledException
+		if !errors.As(err, &canceledErr) {
+			return nil, fmt.Errorf("failed to save links: %v", err)
+		}
+	}
+
+	return nil, ErrLinkConflict
+}
+
+// loadForLinking reads a ticket and its write ID past the cache. It returns nil when
+// the ticket does not exist, is removed or outside the scope.
+func (s *TicketService) loadForLinking(ctx context.Context, id string) (*models.IncidentTicket, string, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get item: %v", err)
+	}
+	if result.Item == nil {
+		return nil, "", nil
+	}
+
+	ticket, err := s.decodeTicket(ctx, result.Item)
+	if err != nil {
+		return nil, "", err
+	}
+	if ticket.Removed() || !tenant.FromContext(ctx).Includes(ticket.Team) {
+		return nil, "", nil
+	}
+	var writeID string
+	if v, ok := result.Item[ticketcodec.WriteIDAttribute].(*types.AttributeValueMemberS); ok {
+		writeID = v.Value
+	}
+	return &ticket, writeID, nil
+}
+
+// linkUpdate replaces the links of a ticket, provided it still has the write ID it
+// was read with
+func (s *TicketService) linkUpdate(ctx context.Context, ticket models.IncidentTicket, writeID string) (types.TransactWriteItem, error) {
+	names := map[string]string{"#links": "links", "#writeId": ticketcodec.WriteIDAttribute}
+	values := map[string]types.AttributeValue{":writeId": ticketcodec.NewWriteID()}
+
+	update := "SET #writeId = :writeId REMOVE #links"
+	if len(ticket.Links) > 0 {
+		links, err := attributevalue.Marshal(ticket.Links)
+		if err != nil {
+			return types.TransactWriteItem{}, fmt.Errorf("failed to marshal links: %v", err)
+		}
+		values[":links"] = links
+		update = "SET #writeId = :writeId, #links = :links"
+	}
+
+	condition := writableCondition + " AND attribute_not_exists(#writeId)"
+	if writeID != "" {
+		condition = writableCondition + " AND #writeId = :previousWriteId"
+		values[":previousWriteId"] = &types.AttributeValueMemberS{Value: writeID}
+	}
+	conditionExpression, names, values := scopeWrite(ctx, condition, names, values)
+	return types.TransactWriteItem{
+		Update: &types.Update{
+			TableName: aws.String(s.tableName),
+			Key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: ticket.ID},
+			},
+			UpdateExpression:          aws.String(update),
+			ConditionExpression:       conditionExpression,
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: values,
+		},
+	}, nil
+}
+
+// withoutLink returns the links except the one to the given ticket
+func withoutLink(links []models.TicketLink, ticketID string) []models.TicketLink {
+	kept := make([]models.TicketLink, 0, len(links))
+	for _, link := range links {
+		if link.TicketID != ticketID {
+			kept = append(kept, link)
+		}
+	}
+	return kept
+}
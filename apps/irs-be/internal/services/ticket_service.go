@@ -2,46 +2,81 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"irs-be/internal/config"
+	"log/slog"
+	"sort"
 	"strings"
+	"time"
 
+	"irs-be/internal/cache"
+	"irs-be/internal/dto"
+	"irs-be/internal/metrics"
 	"irs-be/internal/models"
+	"irs-be/internal/resilience"
+	"irs-be/internal/retention"
+	"irs-be/internal/tenant"
+	"irs-be/internal/ticketcodec"
+	"irs-be/internal/ticketquery"
+	"irs-be/internal/tracing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ticketCacheName labels the ticket read cache in metrics
+const ticketCacheName = "tickets"
+
+// ErrTeamNotAllowed is returned when a ticket is created for a team outside the caller's scope
+var ErrTeamNotAllowed = errors.New("tickets can only be created for your own team")
+
+// writableCondition holds for tickets that exist and are neither soft deleted nor archived
+const writableCondition = "attribute_exists(id) AND attribute_not_exists(deletedAt) AND attribute_not_exists(archivedAt)"
+
 type TicketService struct {
-	client    *dynamodb.Client
+	client    ticketStore
+	breaker   *resilience.Breaker
+	snapshot  *ticketSnapshot
+	cache     *cache.TTL[[]models.IncidentTicket]
+	scanner   *parallelScanner
+	failures  *decodeFailures
 	tableName string
+	events    *EventBus
+	// invalidated are called whenever cached reads are dropped; see SubscribeInvalidations
+	invalidated []func()
+	// archive is set by NewArchiveService, so archived tickets stay retrievable
+	// by ID once their table items expire
+	archive *retention.Archive
 }
 
-// NewTicketService creates a new Ticket service instance
-func NewTicketService(cfg config.Config) (*TicketService, error) {
+// LoadAWSConfig loads the AWS SDK configuration, with the configured credentials
+// when given and the default credential chain otherwise
+func LoadAWSConfig(ctx context.Context, cfg config.Config) (aws.Config, error) {
 	region := cfg.AWS.Region
 	if region == "" {
 		region = "us-east-1"
 	}
 
-	accessKeyID := cfg.AWS.AccessKeyID
-	secretAccessKey := cfg.AWS.SecretAccessKey
-	sessionToken := cfg.AWS.SessionToken
-	tableName := cfg.DynamoDB.TableName
-	if tableName == "" {
-		tableName = "insident"
-	}
+	accessKeyID := cfg.AWS.AccessKeyID.Reveal()
+	secretAccessKey := cfg.AWS.SecretAccessKey.Reveal()
+	sessionToken := cfg.AWS.SessionToken.Reveal()
 
 	var awsCfg aws.Config
 	var err error
 
 	// Always use provided credentials if they exist
 	if accessKeyID != "" && secretAccessKey != "" {
-		fmt.Printf("Using provided AWS credentials for region: %s, table: %s\n", region, tableName)
-		awsCfg, err = awsconfig.LoadDefaultConfig(context.TODO(),
+		slog.Info("Using provided AWS credentials", "region", region)
+		awsCfg, err = awsconfig.LoadDefaultConfig(ctx,
 			awsconfig.WithRegion(region),
 			awsconfig.WithCredentialsProvider(credentials.StaticCredentialsProvider{
 				Value: aws.Credentials{
@@ -53,57 +88,351 @@ func NewTicketService(cfg config.Config) (*TicketService, error) {
 		)
 	} else {
 		// Fallback to default credentials (IAM role, shared credentials file, etc.)
-		fmt.Printf("No explicit credentials provided, using default AWS credential chain for region: %s, table: %s\n", region, tableName)
-		awsCfg, err = awsconfig.LoadDefaultConfig(context.TODO(),
+		slog.Info("No explicit credentials provided, using default AWS credential chain", "region", region)
+		awsCfg, err = awsconfig.LoadDefaultConfig(ctx,
 			awsconfig.WithRegion(region),
 		)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("unable to load AWS SDK config: %v", err)
+		return aws.Config{}, fmt.Errorf("unable to load AWS SDK config: %v", err)
+	}
+	return awsCfg, nil
+}
+
+// NewDynamoDBClient creates a DynamoDB client from the AWS configuration
+func NewDynamoDBClient(cfg config.Config) (*dynamodb.Client, error) {
+	awsCfg, err := dynamoDBAWSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamodb.NewFromConfig(awsCfg, tracing.InstrumentDynamoDB, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, metrics.AddDynamoDBMiddleware)
+		if cfg.DynamoDB.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.DynamoDB.Endpoint)
+		}
+	}), nil
+}
+
+// NewDynamoDBStreamsClient creates a DynamoDB Streams client from the AWS configuration.
+// DynamoDB Local serves streams on the DynamoDB endpoint.
+func NewDynamoDBStreamsClient(cfg config.Config) (*dynamodbstreams.Client, error) {
+	awsCfg, err := dynamoDBAWSConfig(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	client := dynamodb.NewFromConfig(awsCfg)
+	return dynamodbstreams.NewFromConfig(awsCfg, func(o *dynamodbstreams.Options) {
+		o.APIOptions = append(o.APIOptions, metrics.AddDynamoDBMiddleware)
+		if cfg.DynamoDB.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.DynamoDB.Endpoint)
+		}
+	}), nil
+}
 
-	// Test the credentials by trying to list tables
-	// Commented out due to permission issues - uncomment when credentials are properly configured
-	/*
-		_, err = client.ListTables(context.TODO(), &dynamodb.ListTablesInput{Limit: aws.Int32(1)})
+// dynamoDBAWSConfig loads the AWS configuration of the DynamoDB clients
+func dynamoDBAWSConfig(cfg config.Config) (aws.Config, error) {
+	if cfg.DynamoDB.Endpoint != "" && (cfg.AWS.AccessKeyID.Reveal() == "" || cfg.AWS.SecretAccessKey.Reveal() == "") {
+		region := cfg.AWS.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		// DynamoDB Local accepts any credentials, but requests must still be signed
+		slog.Info("No explicit credentials provided, using placeholder credentials for the DynamoDB endpoint", "region", region, "endpoint", cfg.DynamoDB.Endpoint)
+		awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(),
+			awsconfig.WithRegion(region),
+			awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("local", "local", "")),
+		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to test AWS credentials: %v", err)
+			return aws.Config{}, fmt.Errorf("unable to load AWS SDK config: %v", err)
 		}
-	*/
+		return awsCfg, nil
+	}
+	return LoadAWSConfig(context.Background(), cfg)
+}
 
-	fmt.Printf("Successfully initialized DynamoDB client for table: %s\n", tableName)
+// NewTicketService creates a new Ticket service instance
+func NewTicketService(client *dynamodb.Client, cfg config.Config, events *EventBus) *TicketService {
+	tableName := cfg.DynamoDB.TableName
+	if tableName == "" {
+		tableName = "insident"
+	}
 
+	slog.Info("Initialized ticket service", "table", tableName)
+
+	store := newResilientDynamoDB(client, cfg)
 	return &TicketService{
-		client:    client,
+		client:    store,
+		breaker:   store.breaker,
+		snapshot:  newTicketSnapshot(cfg.Resilience.StaleMaxAge),
+		cache:     cache.NewTTL[[]models.IncidentTicket](cfg.Cache.TTL, cfg.Cache.MaxEntries),
+		scanner:   newParallelScanner(store, cfg.Scan),
+		failures:  newDecodeFailures(maxDecodeFailures),
 		tableName: tableName,
-	}, nil
+		events:    events,
+	}
 }
 
-// GetAllTickets retrieves all tickets from DynamoDB
-func (s *TicketService) GetAllTickets() ([]models.IncidentTicket, error) {
-	input := &dynamodb.ScanInput{
-		TableName: aws.String(s.tableName),
+// BreakerState reports the state of the circuit breaker guarding the ticket table
+func (s *TicketService) BreakerState() resilience.State {
+	return s.breaker.State()
+}
+
+// staleTickets answers a read from the last full scan when the breaker rejected the
+// call. The response is marked stale so clients can tell.
+func (s *TicketService) staleTickets(ctx context.Context, operation string, err error, keep func(models.IncidentTicket) bool) ([]models.IncidentTicket, bool) {
+	if !errors.Is(err, resilience.ErrCircuitOpen) {
+		return nil, false
+	}
+	tickets, age, ok := s.snapshot.filter(keep)
+	if !ok {
+		return nil, false
 	}
+	resilience.MarkStale(ctx, age)
+	metrics.IncStaleResponse(operation)
+	return tickets, true
+}
+
+// The reads below return the tickets of the team the context is scoped to, and of
+// every team for unscoped contexts; see tenant.FromContext. Tickets of other teams
+// read as not found.
 
-	result, err := s.client.Scan(context.TODO(), input)
+// GetAllTickets retrieves all tickets, from the cache when fresh
+func (s *TicketService) GetAllTickets(ctx context.Context) ([]models.IncidentTicket, error) {
+	return s.cached(ctx, "all", s.loadAllTickets)
+}
+
+// GetTicketByID retrieves a specific ticket by ID, from the cache when fresh.
+// Archived tickets are read from the archive once their table item expired. It
+// returns nil when the ticket does not exist, is soft deleted or belongs to a team
+// out of scope.
+func (s *TicketService) GetTicketByID(ctx context.Context, id string) (*models.IncidentTicket, error) {
+	tickets, err := s.cached(ctx, "id:"+id, func(ctx context.Context) ([]models.IncidentTicket, error) {
+		ticket, err := s.loadTicketByID(ctx, id)
+		if err == nil && ticket == nil && s.archive != nil {
+			if ticket, err = s.archive.Get(ctx, id); err != nil {
+				err = fmt.Errorf("failed to read archive: %v", err)
+			}
+		}
+		if err != nil || ticket == nil || ticket.DeletedAt != nil || !tenant.FromContext(ctx).Includes(ticket.Team) {
+			return nil, err
+		}
+		return []models.IncidentTicket{*ticket}, nil
+	})
+	if err != nil || len(tickets) == 0 {
+		return nil, err
+	}
+	ticket := tickets[0]
+	return &ticket, nil
+}
+
+// GetTicketsByStatus retrieves tickets by status, from the cache when fresh
+func (s *TicketService) GetTicketsByStatus(ctx context.Context, status models.Status) ([]models.IncidentTicket, error) {
+	return s.cached(ctx, "status:"+string(status), func(ctx context.Context) ([]models.IncidentTicket, error) {
+		return s.loadTicketsByStatus(ctx, status)
+	})
+}
+
+// GetTicketsBySeverity retrieves tickets by severity, from the cache when fresh
+func (s *TicketService) GetTicketsBySeverity(ctx context.Context, severity models.Severity) ([]models.IncidentTicket, error) {
+	return s.cached(ctx, "severity:"+string(severity), func(ctx context.Context) ([]models.IncidentTicket, error) {
+		return s.loadTicketsBySeverity(ctx, severity)
+	})
+}
+
+// GetTicketsByIncidentType retrieves tickets by incident type, from the cache when fresh
+func (s *TicketService) GetTicketsByIncidentType(ctx context.Context, incidentType models.IncidentType) ([]models.IncidentTicket, error) {
+	return s.cached(ctx, "incident-type:"+string(incidentType), func(ctx context.Context) ([]models.IncidentTicket, error) {
+		return s.loadTicketsByIncidentType(ctx, incidentType)
+	})
+}
+
+// cached answers a read from the ticket cache. Entries are kept per scope, so teams
+// never share one. Concurrent misses share the load started by the first caller,
+// under that caller's context.
+func (s *TicketService) cached(ctx context.Context, key string, load func(ctx context.Context) ([]models.IncidentTicket, error)) ([]models.IncidentTicket, error) {
+	key += "@" + tenant.FromContext(ctx).Key()
+	tickets, result, err := s.cache.Get(key, func() ([]models.IncidentTicket, error) {
+		return load(ctx)
+	})
+	if s.cache.Enabled() {
+		metrics.IncCacheRequest(ticketCacheName, string(result))
+	}
 	if err != nil {
+		return nil, err
+	}
+	// Callers may reorder or filter the slice in place; keep the cached one intact
+	if tickets == nil {
+		return nil, nil
+	}
+	return append(make([]models.IncidentTicket, 0, len(tickets)), tickets...), nil
+}
+
+// InvalidateTickets drops cached reads that may include the given tickets, or the
+// whole cache when no ID is given. irs-be writes call it; it is also the hook for
+// writes made outside irs-be, such as the lambdas, reported by a change feed.
+func (s *TicketService) InvalidateTickets(ids ...string) {
+	for _, fn := range s.invalidated {
+		fn()
+	}
+	if len(ids) == 0 {
+		s.cache.Purge()
+		metrics.IncCacheInvalidation(ticketCacheName)
+		return
+	}
+
+	// Keys end in the scope they were read under; see cached
+	prefixes := []string{"all@", "status:", "severity:", "incident-type:", "filter:"}
+	for _, id := range ids {
+		prefixes = append(prefixes, "id:"+id+"@")
+	}
+	// Any list may contain the ticket, before or after the write
+	s.cache.Invalidate(nil, prefixes)
+	metrics.IncCacheInvalidation(ticketCacheName)
+}
+
+// SubscribeInvalidations registers fn to be called whenever InvalidateTickets
+// drops cached reads, so caches built on top of them, such as encoded responses,
+// are dropped too. Register subscribers before serving requests.
+func (s *TicketService) SubscribeInvalidations(fn func()) {
+	s.invalidated = append(s.invalidated, fn)
+}
+
+// visible reports whether a ticket belongs in lists, i.e. is neither soft deleted nor archived
+func visible(ticket models.IncidentTicket) bool {
+	return !ticket.Removed()
+}
+
+// scopeCondition returns the condition limiting items to the scope's team, with its
+// placeholders added to b, or "" for all teams
+func scopeCondition(scope tenant.Scope, b *ticketquery.Builder) string {
+	if scope.IsAll() {
+		return ""
+	}
+	name := b.Name("team")
+	condition := name + " = " + b.Value(scope.Team())
+	if scope.IsDefault() {
+		return "(" + condition + " OR attribute_not_exists(" + name + "))"
+	}
+	return condition
+}
+
+// scopeQuery limits an index query to the team of the context's scope
+func scopeQuery(ctx context.Context, input *dynamodb.QueryInput) *dynamodb.QueryInput {
+	b := ticketquery.NewBuilder()
+	condition := scopeCondition(tenant.FromContext(ctx), b)
+	if condition == "" {
+		return input
+	}
+	input.FilterExpression = aws.String(condition)
+	for placeholder, name := range b.Names {
+		input.ExpressionAttributeNames[placeholder] = name
+	}
+	for placeholder, value := range b.Values {
+		input.ExpressionAttributeValues[placeholder] = value
+	}
+	return input
+}
+
+// scopeWrite adds the team of the context's scope to a write condition, so a write
+// never reaches another team's ticket. names and values hold the placeholders of
+// condition and may be nil; the returned maps are nil when empty, which DynamoDB requires.
+func scopeWrite(ctx context.Context, condition string, names map[string]string, values map[string]types.AttributeValue) (*string, map[string]string, map[string]types.AttributeValue) {
+	b := ticketquery.NewBuilder()
+	if team := scopeCondition(tenant.FromContext(ctx), b); team != "" {
+		condition += " AND " + team
+	}
+	for placeholder, name := range names {
+		b.Names[placeholder] = name
+	}
+	for placeholder, value := range values {
+		b.Values[placeholder] = value
+	}
+	if len(b.Names) == 0 {
+		b.Names = nil
+	}
+	if len(b.Values) == 0 {
+		b.Values = nil
+	}
+	return aws.String(condition), b.Names, b.Values
+}
+
+// loadAllTickets retrieves all tickets in scope from DynamoDB. The whole table is
+// read, so the snapshot for stale reads covers every team.
+func (s *TicketService) loadAllTickets(ctx context.Context) ([]models.IncidentTicket, error) {
+	scope := tenant.FromContext(ctx)
+	inScope := func(t models.IncidentTicket) bool { return scope.Includes(t.Team) }
+
+	tickets, err := s.scanTickets(ctx, visible)
+	if err != nil {
+		if tickets, ok := s.staleTickets(ctx, "GetAllTickets", err, inScope); ok {
+			return tickets, nil
+		}
 		return nil, fmt.Errorf("failed to scan table: %v", err)
 	}
 
+	s.snapshot.store(tickets)
+	if scope.IsAll() {
+		return tickets, nil
+	}
+	var scoped []models.IncidentTicket
+	for _, ticket := range tickets {
+		if inScope(ticket) {
+			scoped = append(scoped, ticket)
+		}
+	}
+	return scoped, nil
+}
+
+// scanTickets reads the whole table with a parallel scan and keeps the tickets
+// matching keep, ordered by ID
+func (s *TicketService) scanTickets(ctx context.Context, keep func(models.IncidentTicket) bool) ([]models.IncidentTicket, error) {
 	var tickets []models.IncidentTicket
-	for _, item := range result.Items {
-		ticket := s.unmarshalTicket(item)
-		tickets = append(tickets, ticket)
+	err := s.scanner.scan(ctx, &dynamodb.ScanInput{TableName: aws.String(s.tableName)}, 0, func(items []map[string]types.AttributeValue) error {
+		for _, item := range items {
+			ticket, err := s.decodeTicket(ctx, item)
+			if err == nil && keep(ticket) {
+				tickets = append(tickets, ticket)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	// Segments finish in any order; sorting keeps responses, and their ETags, stable
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].ID < tickets[j].ID })
 	return tickets, nil
 }
 
-// GetTicketByID retrieves a specific ticket by ID
-func (s *TicketService) GetTicketByID(id string) (*models.IncidentTicket, error) {
+// StreamTickets scans the whole table page by page and calls fn for every ticket in
+// scope, soft deleted and archived ones included. Only a page per scan worker is
+// held in memory, so callers can process any number of tickets. Tickets arrive in
+// no particular order; fn is never called concurrently.
+func (s *TicketService) StreamTickets(ctx context.Context, pageSize int32, fn func(ticket models.IncidentTicket) error) error {
+	scope := tenant.FromContext(ctx)
+	err := s.scanner.scan(ctx, &dynamodb.ScanInput{TableName: aws.String(s.tableName)}, pageSize, func(items []map[string]types.AttributeValue) error {
+		for _, item := range items {
+			ticket, err := s.decodeTicket(ctx, item)
+			if err != nil || !scope.Includes(ticket.Team) {
+				continue
+			}
+			if err := fn(ticket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan table: %v", err)
+	}
+	return nil
+}
+
+// loadTicketByID retrieves a specific ticket by ID
+func (s *TicketService) loadTicketByID(ctx context.Context, id string) (*models.IncidentTicket, error) {
 	input := &dynamodb.GetItemInput{
 		TableName: aws.String(s.tableName),
 		Key: map[string]types.AttributeValue{
@@ -111,8 +440,12 @@ func (s *TicketService) GetTicketByID(id string) (*models.IncidentTicket, error)
 		},
 	}
 
-	result, err := s.client.GetItem(context.TODO(), input)
+	result, err := s.client.GetItem(ctx, input)
 	if err != nil {
+		stale, ok := s.staleTickets(ctx, "GetTicketByID", err, func(t models.IncidentTicket) bool { return t.ID == id })
+		if ok && len(stale) == 1 {
+			return &stale[0], nil
+		}
 		return nil, fmt.Errorf("failed to get item: %v", err)
 	}
 
@@ -120,12 +453,34 @@ func (s *TicketService) GetTicketByID(id string) (*models.IncidentTicket, error)
 		return nil, nil // Item not found
 	}
 
-	ticket := s.unmarshalTicket(result.Item)
+	ticket, err := s.decodeTicket(ctx, result.Item)
+	if err != nil {
+		return nil, err
+	}
 	return &ticket, nil
 }
 
-// GetTicketsByStatus retrieves tickets by status using GSI
-func (s *TicketService) GetTicketsByStatus(status string) ([]models.IncidentTicket, error) {
+// queryIndex reads every page of a GSI query in the context's scope and keeps
+// the visible tickets
+func (s *TicketService) queryIndex(ctx context.Context, input *dynamodb.QueryInput) ([]models.IncidentTicket, error) {
+	var tickets []models.IncidentTicket
+	paginator := dynamodb.NewQueryPaginator(s.client, scopeQuery(ctx, input))
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			if ticket, err := s.decodeTicket(ctx, item); err == nil && visible(ticket) {
+				tickets = append(tickets, ticket)
+			}
+		}
+	}
+	return tickets, nil
+}
+
+// loadTicketsByStatus retrieves tickets by status using GSI
+func (s *TicketService) loadTicketsByStatus(ctx context.Context, status models.Status) ([]models.IncidentTicket, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(s.tableName),
 		IndexName:              aws.String("StatusIndex"),
@@ -134,26 +489,24 @@ func (s *TicketService) GetTicketsByStatus(status string) ([]models.IncidentTick
 			"#status": "status",
 		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":status": &types.AttributeValueMemberS{Value: status},
+			":status": &types.AttributeValueMemberS{Value: string(status)},
 		},
 	}
 
-	result, err := s.client.Query(context.TODO(), input)
+	tickets, err := s.queryIndex(ctx, input)
 	if err != nil {
+		scope := tenant.FromContext(ctx)
+		if tickets, ok := s.staleTickets(ctx, "GetTicketsByStatus", err, func(t models.IncidentTicket) bool { return t.Status == status && scope.Includes(t.Team) }); ok {
+			return tickets, nil
+		}
 		return nil, fmt.Errorf("failed to query by status: %v", err)
 	}
 
-	var tickets []models.IncidentTicket
-	for _, item := range result.Items {
-		ticket := s.unmarshalTicket(item)
-		tickets = append(tickets, ticket)
-	}
-
 	return tickets, nil
 }
 
-// GetTicketsBySeverity retrieves tickets by severity using GSI
-func (s *TicketService) GetTicketsBySeverity(severity string) ([]models.IncidentTicket, error) {
+// loadTicketsBySeverity retrieves tickets by severity using GSI
+func (s *TicketService) loadTicketsBySeverity(ctx context.Context, severity models.Severity) ([]models.IncidentTicket, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(s.tableName),
 		IndexName:              aws.String("SeverityIndex"),
@@ -162,26 +515,24 @@ func (s *TicketService) GetTicketsBySeverity(severity string) ([]models.Incident
 			"#severity": "severity",
 		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":severity": &types.AttributeValueMemberS{Value: severity},
+			":severity": &types.AttributeValueMemberS{Value: string(severity)},
 		},
 	}
 
-	result, err := s.client.Query(context.TODO(), input)
+	tickets, err := s.queryIndex(ctx, input)
 	if err != nil {
+		scope := tenant.FromContext(ctx)
+		if tickets, ok := s.staleTickets(ctx, "GetTicketsBySeverity", err, func(t models.IncidentTicket) bool { return t.Severity == severity && scope.Includes(t.Team) }); ok {
+			return tickets, nil
+		}
 		return nil, fmt.Errorf("failed to query by severity: %v", err)
 	}
 
-	var tickets []models.IncidentTicket
-	for _, item := range result.Items {
-		ticket := s.unmarshalTicket(item)
-		tickets = append(tickets, ticket)
-	}
-
 	return tickets, nil
 }
 
-// GetTicketsByIncidentType retrieves tickets by incident type using GSI
-func (s *TicketService) GetTicketsByIncidentType(incidentType string) ([]models.IncidentTicket, error) {
+// loadTicketsByIncidentType retrieves tickets by incident type using GSI
+func (s *TicketService) loadTicketsByIncidentType(ctx context.Context, incidentType models.IncidentType) ([]models.IncidentTicket, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(s.tableName),
 		IndexName:              aws.String("IncidentTypeIndex"),
@@ -190,142 +541,428 @@ func (s *TicketService) GetTicketsByIncidentType(incidentType string) ([]models.
 			"#incident_type": "insident_type",
 		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":incident_type": &types.AttributeValueMemberS{Value: incidentType},
+			":incident_type": &types.AttributeValueMemberS{Value: string(incidentType)},
 		},
 	}
 
-	result, err := s.client.Query(context.TODO(), input)
+	tickets, err := s.queryIndex(ctx, input)
 	if err != nil {
+		scope := tenant.FromContext(ctx)
+		if tickets, ok := s.staleTickets(ctx, "GetTicketsByIncidentType", err, func(t models.IncidentTicket) bool { return t.IncidentType == incidentType && scope.Includes(t.Team) }); ok {
+			return tickets, nil
+		}
 		return nil, fmt.Errorf("failed to query by incident type: %v", err)
 	}
 
-	var tickets []models.IncidentTicket
-	for _, item := range result.Items {
-		ticket := s.unmarshalTicket(item)
-		tickets = append(tickets, ticket)
-	}
-
 	return tickets, nil
 }
 
 // SearchTickets searches tickets by title, description, or report
-func (s *TicketService) SearchTickets(query string) ([]models.IncidentTicket, error) {
+func (s *TicketService) SearchTickets(ctx context.Context, query string) ([]models.IncidentTicket, error) {
 	// For simple search, we'll scan and filter
 	// In production, you might want to use Elasticsearch or DynamoDB Streams with Lambda
-	input := &dynamodb.ScanInput{
-		TableName: aws.String(s.tableName),
+	queryLower := strings.ToLower(query)
+	scope := tenant.FromContext(ctx)
+	matches := func(ticket models.IncidentTicket) bool {
+		return visible(ticket) && scope.Includes(ticket.Team) && (strings.Contains(strings.ToLower(ticket.Title), queryLower) ||
+			strings.Contains(strings.ToLower(ticket.Description), queryLower) ||
+			strings.Contains(strings.ToLower(ticket.Report), queryLower))
 	}
 
-	result, err := s.client.Scan(context.TODO(), input)
+	tickets, err := s.scanTickets(ctx, matches)
 	if err != nil {
+		if tickets, ok := s.staleTickets(ctx, "SearchTickets", err, matches); ok {
+			return tickets, nil
+		}
 		return nil, fmt.Errorf("failed to scan table: %v", err)
 	}
 
-	var tickets []models.IncidentTicket
-	queryLower := strings.ToLower(query)
+	return tickets, nil
+}
 
-	for _, item := range result.Items {
-		ticket := s.unmarshalTicket(item)
+// GetTicketsWithFilters retrieves the tickets matching a query, without their
+// reports and ordered by ID, and the plan used to read them
+func (s *TicketService) GetTicketsWithFilters(ctx context.Context, query ticketquery.Node) ([]models.IncidentTicket, QueryPlan, error) {
+	scope := tenant.FromContext(ctx)
+	plan := planQuery(query, func(attr, value string) (int, bool) {
+		return s.snapshot.count(func(t models.IncidentTicket) bool { return indexAttribute(t, attr) == value && scope.Includes(t.Team) })
+	}, time.Now())
+	plan.Scope = scope
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("query.plan", plan.String()))
+
+	tickets, err := s.cached(ctx, "filter:"+query.String(), func(ctx context.Context) ([]models.IncidentTicket, error) {
+		return s.loadTicketsWithFilters(ctx, plan)
+	})
+	return tickets, plan, err
+}
 
-		// Check if query matches title, description, or report
-		if strings.Contains(strings.ToLower(ticket.Title), queryLower) ||
-			strings.Contains(strings.ToLower(ticket.Description), queryLower) ||
-			strings.Contains(strings.ToLower(ticket.Report), queryLower) {
-			tickets = append(tickets, ticket)
+// loadTicketsWithFilters runs a query plan
+func (s *TicketService) loadTicketsWithFilters(ctx context.Context, plan QueryPlan) ([]models.IncidentTicket, error) {
+	now := time.Now()
+	matches := func(ticket models.IncidentTicket) bool {
+		return visible(ticket) && plan.Scope.Includes(ticket.Team) && plan.Query.Match(ticket, now)
+	}
+
+	// Free text matches the report too, so it has to be read even though it is not returned
+	projection := listAttributes
+	if plan.ReadsReport {
+		projection = ticketAttributes()
+	}
+	expr := plan.expression(projection, now)
+
+	var tickets []models.IncidentTicket
+	collect := func(items []map[string]types.AttributeValue) error {
+		for _, item := range items {
+			ticket, err := s.decodeTicket(ctx, item)
+			if err == nil && matches(ticket) {
+				ticket.Report = ""
+				tickets = append(tickets, ticket)
+			}
+		}
+		return nil
+	}
+
+	var err error
+	if plan.Index == "" {
+		err = s.scanner.scan(ctx, expr.scanInput(s.tableName), 0, collect)
+	} else {
+		paginator := dynamodb.NewQueryPaginator(s.client, expr.queryInput(s.tableName, plan.Index))
+		for paginator.HasMorePages() {
+			var page *dynamodb.QueryOutput
+			if page, err = paginator.NextPage(ctx); err != nil {
+				break
+			}
+			if err = collect(page.Items); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		stale, ok := s.staleTickets(ctx, "GetTicketsWithFilters", err, matches)
+		if !ok {
+			return nil, fmt.Errorf("failed to query tickets: %v", err)
+		}
+		tickets = stale
+		for i := range tickets {
+			tickets[i].Report = ""
 		}
 	}
 
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].ID < tickets[j].ID })
 	return tickets, nil
 }
 
-// unmarshalTicket converts DynamoDB item to IncidentTicket
-func (s *TicketService) unmarshalTicket(item map[string]types.AttributeValue) models.IncidentTicket {
-	ticket := models.IncidentTicket{}
-
-	if v, ok := item["id"].(*types.AttributeValueMemberS); ok {
-		ticket.ID = v.Value
+// CreateTicket stores a new ticket and publishes a created event. The ticket belongs
+// to the team of the context's scope; unscoped callers may name the team in the
+// request and default to the default team.
+func (s *TicketService) CreateTicket(ctx context.Context, req dto.CreateTicketRequest) (*models.IncidentTicket, error) {
+	scope := tenant.FromContext(ctx)
+	if req.Team != "" && !scope.Includes(req.Team) {
+		return nil, ErrTeamNotAllowed
+	}
+	team := scope.Team()
+	if scope.IsAll() {
+		team = tenant.Resolve(req.Team)
+	}
+
+	now := time.Now().UTC()
+	ticket := models.IncidentTicket{
+		ID:               fmt.Sprintf("INC-%s-%s", now.Format("20060102"), strings.ToUpper(uuid.NewString()[:8])),
+		Title:            req.Title,
+		Description:      req.Description,
+		Suggestions:      req.Suggestions,
+		Severity:         req.Severity,
+		Category:         req.Category,
+		IncidentType:     req.IncidentType,
+		Environment:      req.Environment,
+		ActionStatus:     req.ActionStatus,
+		Status:           req.Status,
+		Reporter:         req.Reporter,
+		Team:             team,
+		CreatedAt:        models.FormatTimestamp(now),
+		AffectedServices: req.AffectedServices,
+		Tags:             req.Tags,
+	}
+	if ticket.ActionStatus == "" {
+		ticket.ActionStatus = models.ActionStatusManual
+	}
+	if ticket.Status == "" {
+		ticket.Status = models.StatusOpen
+	}
+	if ticket.Reporter == "" {
+		ticket.Reporter = "irs-be"
+	}
+
+	item, err := ticketcodec.Encode(ticket)
+	if err != nil {
+		return nil, err
 	}
-	if v, ok := item["title"].(*types.AttributeValueMemberS); ok {
-		ticket.Title = v.Value
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to put item: %v", err)
 	}
-	if v, ok := item["description"].(*types.AttributeValueMemberS); ok {
-		ticket.Description = v.Value
+
+	s.InvalidateTickets(ticket.ID)
+	s.events.Publish(models.TicketEventCreated, ticket, nil)
+	return &ticket, nil
+}
+
+// UpdateTicket applies a partial update and publishes the resulting lifecycle events.
+// It returns nil when the ticket does not exist, is soft deleted or archived.
+func (s *TicketService) UpdateTicket(ctx context.Context, id string, req dto.UpdateTicketRequest) (*models.IncidentTicket, error) {
+	// Read past the cache so the update and its events start from the stored ticket
+	previous, err := s.loadTicketByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	if v, ok := item["report"].(*types.AttributeValueMemberS); ok {
-		ticket.Report = v.Value
+	if previous == nil || previous.Removed() || !tenant.FromContext(ctx).Includes(previous.Team) {
+		return nil, nil
 	}
-	if v, ok := item["severity"].(*types.AttributeValueMemberS); ok {
-		ticket.Severity = v.Value
+
+	names := map[string]string{}
+	values := map[string]types.AttributeValue{}
+	var sets []string
+	set := func(attr, value string) {
+		names["#"+attr] = attr
+		values[":"+attr] = &types.AttributeValueMemberS{Value: value}
+		sets = append(sets, fmt.Sprintf("#%s = :%s", attr, attr))
 	}
-	if v, ok := item["category"].(*types.AttributeValueMemberS); ok {
-		ticket.Category = v.Value
+
+	if req.Status != nil && *req.Status != previous.Status {
+		set("status", string(*req.Status))
+		if models.IsResolvedStatus(*req.Status) && previous.ResolutionTime == nil {
+			set("resolutionTime", models.FormatTimestamp(time.Now()))
+		}
 	}
-	if v, ok := item["insident_type"].(*types.AttributeValueMemberS); ok {
-		ticket.IncidentType = v.Value
+	if req.Severity != nil && *req.Severity != previous.Severity {
+		set("severity", string(*req.Severity))
 	}
-	if v, ok := item["environment"].(*types.AttributeValueMemberS); ok {
-		ticket.Environment = v.Value
+	if req.ActionStatus != nil && *req.ActionStatus != previous.ActionStatus {
+		set("actionStatus", string(*req.ActionStatus))
 	}
-	if v, ok := item["actionStatus"].(*types.AttributeValueMemberS); ok {
-		ticket.ActionStatus = v.Value
+	if req.ActionTaken != nil {
+		set("actionTaken", *req.ActionTaken)
 	}
-	if v, ok := item["status"].(*types.AttributeValueMemberS); ok {
-		ticket.Status = v.Value
+
+	if len(sets) == 0 {
+		return previous, nil
 	}
-	if v, ok := item["reporter"].(*types.AttributeValueMemberS); ok {
-		ticket.Reporter = v.Value
+	values[":writeId"] = ticketcodec.NewWriteID()
+	sets = append(sets, ticketcodec.WriteIDAttribute+" = :writeId")
+
+	condition, names, values := scopeWrite(ctx, writableCondition, names, values)
+	result, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:          aws.String("SET " + strings.Join(sets, ", ")),
+		ConditionExpression:       condition,
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update item: %v", err)
 	}
-	if v, ok := item["createdAt"].(*types.AttributeValueMemberS); ok {
-		ticket.CreatedAt = v.Value
+
+	s.InvalidateTickets(id)
+	ticket, err := s.decodeTicket(ctx, result.Attributes)
+	if err != nil {
+		return nil, err
 	}
-	if v, ok := item["emailSent"].(*types.AttributeValueMemberBOOL); ok {
-		ticket.EmailSent = v.Value
+	s.publishChanges(*previous, ticket)
+	return &ticket, nil
+}
+
+// AcknowledgeTicket moves a ticket to in-progress. It returns nil when the ticket does not exist.
+func (s *TicketService) AcknowledgeTicket(ctx context.Context, id string) (*models.IncidentTicket, error) {
+	status := models.StatusInProgress
+	return s.UpdateTicket(ctx, id, dto.UpdateTicketRequest{Status: &status})
+}
+
+// ResolveTicket marks a ticket solved and records the resolution note as the action taken.
+// It returns nil when the ticket does not exist.
+func (s *TicketService) ResolveTicket(ctx context.Context, id string, note string) (*models.IncidentTicket, error) {
+	status := models.StatusSolved
+	req := dto.UpdateTicketRequest{Status: &status}
+	if note != "" {
+		req.ActionTaken = &note
 	}
+	return s.UpdateTicket(ctx, id, req)
+}
 
-	// Handle optional fields
-	if v, ok := item["resolutionTime"].(*types.AttributeValueMemberS); ok {
-		ticket.ResolutionTime = &v.Value
+// UpdateReport stores a regenerated report and its suggestions. It returns nil when
+// the ticket does not exist, is soft deleted or archived.
+func (s *TicketService) UpdateReport(ctx context.Context, id string, report string, suggestions []string, meta models.ReportMeta) (*models.IncidentTicket, error) {
+	values, err := attributevalue.MarshalMap(map[string]interface{}{
+		":report":      report,
+		":suggestions": suggestions,
+		":reportMeta":  meta,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report: %v", err)
 	}
-	if v, ok := item["emailSentAt"].(*types.AttributeValueMemberS); ok {
-		ticket.EmailSentAt = &v.Value
+
+	condition, names, values := scopeWrite(ctx, writableCondition, nil, values)
+	result, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:          aws.String("SET report = :report, suggestions = :suggestions, reportMeta = :reportMeta"),
+		ConditionExpression:       condition,
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update report: %v", err)
 	}
-	if v, ok := item["actionTaken"].(*types.AttributeValueMemberS); ok {
-		ticket.ActionTaken = &v.Value
+
+	s.InvalidateTickets(id)
+	ticket, err := s.decodeTicket(ctx, result.Attributes)
+	if err != nil {
+		return nil, err
 	}
+	return &ticket, nil
+}
 
-	// Handle string arrays
-	if v, ok := item["suggestions"].(*types.AttributeValueMemberL); ok {
-		for _, suggestion := range v.Value {
-			if s, ok := suggestion.(*types.AttributeValueMemberS); ok {
-				ticket.Suggestions = append(ticket.Suggestions, s.Value)
-			}
+// DeleteTicket soft deletes a ticket: it is left out of lists and reads until it is
+// undeleted, or archived by a retention rule. It returns nil when the ticket does
+// not exist or was already removed.
+func (s *TicketService) DeleteTicket(ctx context.Context, id string) (*models.IncidentTicket, error) {
+	condition, names, values := scopeWrite(ctx, writableCondition, nil, map[string]types.AttributeValue{
+		":deletedAt": &types.AttributeValueMemberS{Value: models.FormatTimestamp(time.Now())},
+		":writeId":   ticketcodec.NewWriteID(),
+	})
+	result, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:          aws.String("SET deletedAt = :deletedAt, " + ticketcodec.WriteIDAttribute + " = :writeId"),
+		ConditionExpression:       condition,
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, nil
 		}
+		return nil, fmt.Errorf("failed to delete item: %v", err)
 	}
-	if v, ok := item["affectedServices"].(*types.AttributeValueMemberL); ok {
-		for _, service := range v.Value {
-			if s, ok := service.(*types.AttributeValueMemberS); ok {
-				ticket.AffectedServices = append(ticket.AffectedServices, s.Value)
-			}
-		}
+
+	s.InvalidateTickets(id)
+	ticket, err := s.decodeTicket(ctx, result.Attributes)
+	if err != nil {
+		return nil, err
 	}
-	if v, ok := item["tags"].(*types.AttributeValueMemberL); ok {
-		for _, tag := range v.Value {
-			if t, ok := tag.(*types.AttributeValueMemberS); ok {
-				ticket.Tags = append(ticket.Tags, t.Value)
-			}
+	s.events.Publish(models.TicketEventDeleted, ticket, nil)
+	return &ticket, nil
+}
+
+// UndeleteTicket restores a soft deleted ticket. It returns nil when the ticket does
+// not exist, is not deleted or was archived meanwhile.
+func (s *TicketService) UndeleteTicket(ctx context.Context, id string) (*models.IncidentTicket, error) {
+	condition, names, values := scopeWrite(ctx, "attribute_exists(deletedAt) AND attribute_not_exists(archivedAt)", nil, map[string]types.AttributeValue{
+		":writeId": ticketcodec.NewWriteID(),
+	})
+	result, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:          aws.String("REMOVE deletedAt SET " + ticketcodec.WriteIDAttribute + " = :writeId"),
+		ConditionExpression:       condition,
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, nil
 		}
+		return nil, fmt.Errorf("failed to undelete item: %v", err)
 	}
 
-	return ticket
+	s.InvalidateTickets(id)
+	ticket, err := s.decodeTicket(ctx, result.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(models.TicketEventUndeleted, ticket, nil)
+	return &ticket, nil
+}
+
+// GetDeletedTickets lists the soft deleted tickets that can still be undeleted
+func (s *TicketService) GetDeletedTickets(ctx context.Context) ([]models.IncidentTicket, error) {
+	scope := tenant.FromContext(ctx)
+	tickets, err := s.scanTickets(ctx, func(t models.IncidentTicket) bool {
+		return t.DeletedAt != nil && t.ArchivedAt == nil && scope.Includes(t.Team)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan table: %v", err)
+	}
+	return tickets, nil
+}
+
+// publishChanges emits lifecycle events describing the difference between two ticket versions
+func (s *TicketService) publishChanges(previous, current models.IncidentTicket) {
+	for _, eventType := range lifecycleEvents(&previous, current) {
+		s.events.Publish(eventType, current, &previous)
+	}
+}
+
+// lifecycleEvents lists the lifecycle events between two versions of a ticket, in
+// the order they are published. previous is nil for a new ticket.
+func lifecycleEvents(previous *models.IncidentTicket, current models.IncidentTicket) []models.TicketEventType {
+	if previous == nil {
+		return []models.TicketEventType{models.TicketEventCreated}
+	}
+
+	var events []models.TicketEventType
+	if current.Status != previous.Status {
+		events = append(events, models.TicketEventStatusChanged)
+		if models.IsResolvedStatus(current.Status) && !models.IsResolvedStatus(previous.Status) {
+			events = append(events, models.TicketEventResolved)
+		}
+	}
+	if models.SeverityRank(current.Severity) > models.SeverityRank(previous.Severity) {
+		events = append(events, models.TicketEventEscalated)
+	}
+	switch {
+	case current.DeletedAt != nil && previous.DeletedAt == nil:
+		events = append(events, models.TicketEventDeleted)
+	case current.DeletedAt == nil && previous.DeletedAt != nil:
+		events = append(events, models.TicketEventUndeleted)
+	}
+	return events
 }
 
 // HealthCheck checks if DynamoDB connection is working
-func (s *TicketService) HealthCheck() error {
+func (s *TicketService) HealthCheck(ctx context.Context) error {
 	input := &dynamodb.DescribeTableInput{
 		TableName: aws.String(s.tableName),
 	}
 
-	_, err := s.client.DescribeTable(context.TODO(), input)
+	_, err := s.client.DescribeTable(ctx, input)
 	if err != nil {
 		return fmt.Errorf("health check failed: %v", err)
 	}
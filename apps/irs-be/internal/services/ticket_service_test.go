@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"irs-be/internal/models"
+	"irs-be/internal/ticketcodec"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// pagedIndex answers GSI queries a few items per page, the way DynamoDB does once
+// a result passes 1 MB
+type pagedIndex struct {
+	ticketStore
+	pageSize int
+	items    []map[string]types.AttributeValue
+
+	mu      sync.Mutex
+	queries int
+}
+
+func (s *pagedIndex) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	s.mu.Lock()
+	s.queries++
+	s.mu.Unlock()
+
+	var attr string
+	for _, name := range params.ExpressionAttributeNames {
+		attr = name
+	}
+	var want string
+	for _, value := range params.ExpressionAttributeValues {
+		want = value.(*types.AttributeValueMemberS).Value
+	}
+	var after string
+	if key, ok := params.ExclusiveStartKey["id"].(*types.AttributeValueMemberS); ok {
+		after = key.Value
+	}
+
+	out := &dynamodb.QueryOutput{}
+	for _, item := range s.items {
+		id := item["id"].(*types.AttributeValueMemberS).Value
+		value, ok := item[attr].(*types.AttributeValueMemberS)
+		if id <= after || !ok || value.Value != want {
+			continue
+		}
+		if len(out.Items) == s.pageSize {
+			last := out.Items[len(out.Items)-1]["id"]
+			out.LastEvaluatedKey = map[string]types.AttributeValue{"id": last}
+			break
+		}
+		out.Items = append(out.Items, item)
+	}
+	return out, nil
+}
+
+func TestIndexReadsFollowEveryPage(t *testing.T) {
+	store := &pagedIndex{pageSize: 2}
+	for _, ticket := range []models.IncidentTicket{
+		{ID: "INC-1", Status: models.StatusOpen, Severity: models.SeverityHigh, IncidentType: models.IncidentTypeCPUHigh},
+		{ID: "INC-2", Status: models.StatusOpen, Severity: models.SeverityHigh, IncidentType: models.IncidentTypeCPUHigh},
+		{ID: "INC-3", Status: models.StatusResolved, Severity: models.SeverityLow, IncidentType: models.IncidentTypeAppError},
+		{ID: "INC-4", Status: models.StatusOpen, Severity: models.SeverityHigh, IncidentType: models.IncidentTypeCPUHigh},
+		{ID: "INC-5", Status: models.StatusOpen, Severity: models.SeverityHigh, IncidentType: models.IncidentTypeCPUHigh},
+		{ID: "INC-6", Status: models.StatusOpen, Severity: models.SeverityHigh, IncidentType: models.IncidentTypeCPUHigh},
+	} {
+		store.items = append(store.items, encodeTicket(t, ticket))
+	}
+	want := []string{"INC-1", "INC-2", "INC-4", "INC-5", "INC-6"}
+
+	tests := []struct {
+		name string
+		read func(s *TicketService) ([]models.IncidentTicket, error)
+	}{
+		{"status", func(s *TicketService) ([]models.IncidentTicket, error) {
+			return s.GetTicketsByStatus(context.Background(), models.StatusOpen)
+		}},
+		{"severity", func(s *TicketService) ([]models.IncidentTicket, error) {
+			return s.GetTicketsBySeverity(context.Background(), models.SeverityHigh)
+		}},
+		{"incident type", func(s *TicketService) ([]models.IncidentTicket, error) {
+			return s.GetTicketsByIncidentType(context.Background(), models.IncidentTypeCPUHigh)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.queries = 0
+			tickets, err := tt.read(newCachedTicketService(store, nil))
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			got := make([]string, len(tickets))
+			for i, ticket := range tickets {
+				got[i] = ticket.ID
+			}
+			sort.Strings(got)
+			if len(got) != len(want) {
+				t.Fatalf("tickets = %v, want %v", got, want)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("tickets = %v, want %v", got, want)
+				}
+			}
+			if store.queries != 3 {
+				t.Errorf("queries = %d, want 3 pages", store.queries)
+			}
+		})
+	}
+}
+
+func TestIndexReadsReportUndecodableItems(t *testing.T) {
+	store := &pagedIndex{pageSize: 2}
+	store.items = append(store.items, encodeTicket(t, models.IncidentTicket{ID: "INC-1", Status: models.StatusOpen}))
+	newer := encodeTicket(t, models.IncidentTicket{ID: "INC-2", Status: models.StatusOpen})
+	newer[ticketcodec.VersionAttribute] = &types.AttributeValueMemberN{Value: "999"}
+	store.items = append(store.items, newer)
+	s := newCachedTicketService(store, nil)
+
+	tickets, err := s.GetTicketsByStatus(context.Background(), models.StatusOpen)
+	if err != nil {
+		t.Fatalf("GetTicketsByStatus: %v", err)
+	}
+	if len(tickets) != 1 || tickets[0].ID != "INC-1" {
+		t.Errorf("tickets = %+v, want only INC-1", tickets)
+	}
+	failures := s.DecodeFailures()
+	if len(failures) != 1 || failures[0].ID != "INC-2" || failures[0].SchemaVersion != 999 {
+		t.Errorf("decode failures = %+v, want INC-2 at version 999", failures)
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"irs-be/internal/models"
+)
+
+// ticketSnapshot keeps the last successful full scan so reads can still be answered,
+// marked stale, while the circuit breaker is open. Every read can be derived from
+// the full table, which keeps the memory bounded by one copy of the table.
+type ticketSnapshot struct {
+	maxAge time.Duration
+
+	mu      sync.RWMutex
+	tickets []models.IncidentTicket
+	takenAt time.Time
+}
+
+func newTicketSnapshot(maxAge time.Duration) *ticketSnapshot {
+	return &ticketSnapshot{maxAge: maxAge}
+}
+
+// store replaces the snapshot; it is a no-op when stale reads are disabled
+func (s *ticketSnapshot) store(tickets []models.IncidentTicket) {
+	if s.maxAge <= 0 {
+		return
+	}
+	s.mu.Lock()
+	s.tickets = tickets
+	s.takenAt = time.Now()
+	s.mu.Unlock()
+}
+
+// filter returns the snapshot tickets matching keep and the snapshot age; ok is
+// false when there is no snapshot or it is older than the allowed maximum
+func (s *ticketSnapshot) filter(keep func(models.IncidentTicket) bool) (tickets []models.IncidentTicket, age time.Duration, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.takenAt.IsZero() {
+		return nil, 0, false
+	}
+	age = time.Since(s.takenAt)
+	if age > s.maxAge {
+		return nil, 0, false
+	}
+
+	for _, ticket := range s.tickets {
+		if keep(ticket) {
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets, age, true
+}
+
+// count returns how many snapshot tickets match keep, whatever the snapshot age;
+// ok is false when there is no snapshot
+func (s *ticketSnapshot) count(keep func(models.IncidentTicket) bool) (n int, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.takenAt.IsZero() {
+		return 0, false
+	}
+	for _, ticket := range s.tickets {
+		if keep(ticket) {
+			n++
+		}
+	}
+	return n, true
+}
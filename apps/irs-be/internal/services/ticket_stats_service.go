@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"irs-be/internal/config"
+	"irs-be/internal/metrics"
+	"irs-be/internal/models"
+	"irs-be/internal/tenant"
+)
+
+// statsPageSize is the number of tickets scanned per page while refreshing stats
+const statsPageSize = 200
+
+// TicketStatsService periodically summarizes the ticket table into the ticket
+// gauges, so scrapes never scan DynamoDB themselves
+type TicketStatsService struct {
+	ticketService *TicketService
+	teams         *tenant.Directory
+	interval      time.Duration
+	sla           map[string]time.Duration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewTicketStatsService creates a new ticket stats service instance
+func NewTicketStatsService(ticketService *TicketService, teams *tenant.Directory, cfg config.Config) *TicketStatsService {
+	return &TicketStatsService{
+		ticketService: ticketService,
+		teams:         teams,
+		interval:      cfg.Metrics.RefreshInterval,
+		sla:           cfg.Metrics.SLA,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start refreshes the stats now and then every refresh interval
+func (s *TicketStatsService) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.refresh()
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic refresh
+func (s *TicketStatsService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	s.wg.Wait()
+}
+
+func (s *TicketStatsService) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+	defer cancel()
+
+	stats, err := s.Compute(ctx, time.Now())
+	if err != nil {
+		slog.Error("Failed to refresh ticket stats", "error", err)
+		return
+	}
+	metrics.SetTicketStats(stats)
+}
+
+// Compute counts unresolved tickets and the ones past their team's SLA for their
+// severity, per team, leaving out soft deleted and archived ones
+func (s *TicketStatsService) Compute(ctx context.Context, now time.Time) (metrics.TicketStats, error) {
+	stats := metrics.TicketStats{
+		Open:        map[metrics.TicketStatsKey]int{},
+		SLABreached: map[metrics.TicketStatsKey]int{},
+	}
+	// Report zero for every configured team and severity with an SLA instead of
+	// omitting the series
+	for _, team := range s.teams.Teams() {
+		for severity := range s.sla {
+			key := metrics.TicketStatsKey{Team: team, Severity: severity}
+			stats.Open[key] = 0
+			stats.SLABreached[key] = 0
+		}
+	}
+
+	err := s.ticketService.StreamTickets(ctx, statsPageSize, func(ticket models.IncidentTicket) error {
+		if ticket.Removed() || models.IsResolvedStatus(ticket.Status) {
+			return nil
+		}
+		key := metrics.TicketStatsKey{Team: tenant.Resolve(ticket.Team), Severity: string(ticket.Severity)}
+		stats.Open[key]++
+
+		sla, ok := s.teams.SLA(ticket.Team, string(ticket.Severity))
+		if !ok {
+			return nil
+		}
+		createdAt, err := models.ParseTimestamp(ticket.CreatedAt)
+		if err != nil {
+			return nil
+		}
+		if now.Sub(createdAt) > sla {
+			stats.SLABreached[key]++
+		}
+		return nil
+	})
+	return stats, err
+}
@@ -0,0 +1,514 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"irs-be/internal/config"
+	"irs-be/internal/dto"
+	"irs-be/internal/models"
+	"irs-be/internal/tenant"
+	"irs-be/internal/ticketquery"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// Headers sent with every webhook delivery
+const (
+	WebhookEventHeader     = "X-IRS-Event"
+	WebhookDeliveryHeader  = "X-IRS-Delivery"
+	WebhookTimestampHeader = "X-IRS-Timestamp"
+	WebhookSignatureHeader = "X-IRS-Signature"
+)
+
+type webhookJob struct {
+	subscription models.WebhookSubscription
+	delivery     models.WebhookDelivery
+}
+
+// WebhookService manages webhook subscriptions and delivers ticket events to them
+type WebhookService struct {
+	client            *dynamodb.Client
+	subscriptionTable string
+	deliveryTable     string
+	httpClient        *http.Client
+	workers           int
+	maxAttempts       int
+	initialBackoff    time.Duration
+	maxBackoff        time.Duration
+
+	queue    chan webhookJob
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewWebhookService creates a new webhook service instance
+func NewWebhookService(client *dynamodb.Client, cfg config.Config) *WebhookService {
+	workers := cfg.Webhook.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	maxAttempts := cfg.Webhook.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	return &WebhookService{
+		client:            client,
+		subscriptionTable: cfg.Webhook.SubscriptionTable,
+		deliveryTable:     cfg.Webhook.DeliveryTable,
+		httpClient:        &http.Client{Timeout: cfg.Webhook.RequestTimeout},
+		workers:           workers,
+		maxAttempts:       maxAttempts,
+		initialBackoff:    cfg.Webhook.InitialBackoff,
+		maxBackoff:        cfg.Webhook.MaxBackoff,
+		queue:             make(chan webhookJob, 256),
+		stop:              make(chan struct{}),
+	}
+}
+
+// Start launches the delivery workers
+func (s *WebhookService) Start() {
+	for i := 0; i < s.workers; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+}
+
+// Stop stops the delivery workers. Pending retries are abandoned and stay
+// visible in the delivery log with status "retrying".
+func (s *WebhookService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	s.wg.Wait()
+}
+
+// HandleEvent is an EventHandler that fans an event out to matching subscriptions
+func (s *WebhookService) HandleEvent(event models.TicketEvent) {
+	go s.dispatch(event)
+}
+
+// CreateSubscription registers a new webhook subscription for the team of the
+// context's scope; unscoped callers may name the team in the request
+func (s *WebhookService) CreateSubscription(ctx context.Context, req dto.CreateWebhookRequest) (*models.WebhookSubscription, error) {
+	scope := tenant.FromContext(ctx)
+	if req.Team != "" && !scope.Includes(req.Team) {
+		return nil, ErrTeamNotAllowed
+	}
+	team := scope.Team()
+	if scope.IsAll() {
+		team = tenant.Resolve(req.Team)
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %v", err)
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	events := make([]models.TicketEventType, 0, len(req.Events))
+	for _, e := range req.Events {
+		events = append(events, models.TicketEventType(e))
+	}
+
+	subscription := models.WebhookSubscription{
+		ID:          uuid.NewString(),
+		Team:        team,
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      events,
+		Secret:      secret,
+		Active:      true,
+		CreatedAt:   models.FormatTimestamp(time.Now()),
+	}
+
+	item, err := attributevalue.MarshalMap(subscription)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook subscription: %v", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.subscriptionTable),
+		Item:      item,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to put webhook subscription: %v", err)
+	}
+
+	return &subscription, nil
+}
+
+// ListSubscriptions retrieves the webhook subscriptions in scope
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	scope := tenant.FromContext(ctx)
+
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName: aws.String(s.subscriptionTable),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscriptions: %v", err)
+		}
+
+		var batch []models.WebhookSubscription
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &batch); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal webhook subscriptions: %v", err)
+		}
+		for _, subscription := range batch {
+			if scope.Includes(subscription.Team) {
+				subscriptions = append(subscriptions, subscription)
+			}
+		}
+	}
+
+	return subscriptions, nil
+}
+
+// GetSubscription retrieves a webhook subscription by ID. It returns nil when the
+// subscription does not exist or belongs to a team out of scope.
+func (s *WebhookService) GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.subscriptionTable),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %v", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil // Item not found
+	}
+
+	var subscription models.WebhookSubscription
+	if err := attributevalue.UnmarshalMap(result.Item, &subscription); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook subscription: %v", err)
+	}
+	if !tenant.FromContext(ctx).Includes(subscription.Team) {
+		return nil, nil
+	}
+	return &subscription, nil
+}
+
+// DeleteSubscription removes a webhook subscription, reporting whether it existed
+// in scope
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id string) (bool, error) {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.subscriptionTable),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		ReturnValues: types.ReturnValueAllOld,
+	}
+	b := ticketquery.NewBuilder()
+	if condition := scopeCondition(tenant.FromContext(ctx), b); condition != "" {
+		input.ConditionExpression = aws.String(condition)
+		input.ExpressionAttributeNames = b.Names
+		input.ExpressionAttributeValues = b.Values
+	}
+
+	result, err := s.client.DeleteItem(ctx, input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to delete webhook subscription: %v", err)
+	}
+
+	return result.Attributes != nil, nil
+}
+
+// ListDeliveries retrieves the newest deliveries of a subscription, optionally
+// filtered by status. Subscriptions out of scope have no deliveries.
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID string, status string, limit int) ([]models.WebhookDelivery, error) {
+	if !tenant.FromContext(ctx).IsAll() {
+		subscription, err := s.GetSubscription(ctx, subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		if subscription == nil {
+			return []models.WebhookDelivery{}, nil
+		}
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.deliveryTable),
+		KeyConditionExpression: aws.String("#subscriptionId = :subscriptionId"),
+		ExpressionAttributeNames: map[string]string{
+			"#subscriptionId": "subscriptionId",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":subscriptionId": &types.AttributeValueMemberS{Value: subscriptionID},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+	if status != "" {
+		input.FilterExpression = aws.String("#status = :status")
+		input.ExpressionAttributeNames["#status"] = "status"
+		input.ExpressionAttributeValues[":status"] = &types.AttributeValueMemberS{Value: status}
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	paginator := dynamodb.NewQueryPaginator(s.client, input)
+	for paginator.HasMorePages() && len(deliveries) < limit {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query webhook deliveries: %v", err)
+		}
+
+		var batch []models.WebhookDelivery
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &batch); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal webhook deliveries: %v", err)
+		}
+		deliveries = append(deliveries, batch...)
+	}
+
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// Redeliver re-queues a delivery with a fresh attempt budget. It returns nil when
+// either the subscription or the delivery does not exist.
+func (s *WebhookService) Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*models.WebhookDelivery, error) {
+	subscription, err := s.GetSubscription(ctx, subscriptionID)
+	if err != nil || subscription == nil {
+		return nil, err
+	}
+
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.deliveryTable),
+		Key: map[string]types.AttributeValue{
+			"subscriptionId": &types.AttributeValueMemberS{Value: subscriptionID},
+			"id":             &types.AttributeValueMemberS{Value: deliveryID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %v", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var delivery models.WebhookDelivery
+	if err := attributevalue.UnmarshalMap(result.Item, &delivery); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook delivery: %v", err)
+	}
+
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = ""
+	delivery.UpdatedAt = models.FormatTimestamp(time.Now())
+	if err := s.saveDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	s.enqueue(webhookJob{subscription: *subscription, delivery: delivery})
+	return &delivery, nil
+}
+
+// dispatch records a pending delivery for every subscription of the ticket's team
+// interested in the event
+func (s *WebhookService) dispatch(event models.TicketEvent) {
+	ctx := context.Background()
+
+	subscriptions, err := s.ListSubscriptions(ctx)
+	if err != nil {
+		slog.Error("Failed to load webhook subscriptions", "eventId", event.ID, "error", err)
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("Failed to marshal webhook payload", "eventId", event.ID, "error", err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(event.Type) || tenant.Resolve(subscription.Team) != tenant.Resolve(event.Ticket.Team) {
+			continue
+		}
+
+		now := time.Now()
+		delivery := models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			// Delivery IDs sort chronologically so the log can be queried newest first
+			ID:        now.UTC().Format("20060102T150405.000000") + "-" + uuid.NewString()[:8],
+			EventID:   event.ID,
+			EventType: event.Type,
+			TicketID:  event.Ticket.ID,
+			Status:    models.WebhookDeliveryPending,
+			CreatedAt: models.FormatTimestamp(now),
+			UpdatedAt: models.FormatTimestamp(now),
+			Payload:   string(payload),
+		}
+		if err := s.saveDelivery(ctx, delivery); err != nil {
+			slog.Error("Failed to record webhook delivery", "subscriptionId", subscription.ID, "error", err)
+			continue
+		}
+
+		s.enqueue(webhookJob{subscription: subscription, delivery: delivery})
+	}
+}
+
+func (s *WebhookService) enqueue(job webhookJob) {
+	select {
+	case s.queue <- job:
+	case <-s.stop:
+	}
+}
+
+func (s *WebhookService) worker() {
+	defer s.wg.Done()
+	for {
+		select {
+		case job := <-s.queue:
+			s.attempt(job)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// attempt performs one delivery attempt and schedules a retry or dead-letters the delivery
+func (s *WebhookService) attempt(job webhookJob) {
+	ctx := context.Background()
+	delivery := job.delivery
+	delivery.Attempts++
+
+	statusCode, err := s.send(ctx, job.subscription, delivery)
+	delivery.LastStatusCode = statusCode
+	delivery.UpdatedAt = models.FormatTimestamp(time.Now())
+
+	switch {
+	case err == nil:
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.LastError = ""
+		delivery.NextAttemptAt = ""
+	case delivery.Attempts >= s.maxAttempts:
+		delivery.Status = models.WebhookDeliveryDeadLettered
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = ""
+		slog.Warn("Webhook delivery dead-lettered",
+			"deliveryId", delivery.ID, "subscriptionId", job.subscription.ID, "attempts", delivery.Attempts, "error", err)
+	default:
+		delay := s.backoff(delivery.Attempts)
+		delivery.Status = models.WebhookDeliveryRetrying
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = models.FormatTimestamp(time.Now().Add(delay))
+
+		retry := webhookJob{subscription: job.subscription, delivery: delivery}
+		time.AfterFunc(delay, func() {
+			s.enqueue(retry)
+		})
+	}
+
+	if err := s.saveDelivery(ctx, delivery); err != nil {
+		slog.Error("Failed to update webhook delivery", "deliveryId", delivery.ID, "error", err)
+	}
+}
+
+// send POSTs the signed payload; any non-2xx response counts as a failure
+func (s *WebhookService) send(ctx context.Context, subscription models.WebhookSubscription, delivery models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "irs-be-webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, string(delivery.EventType))
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+signWebhookPayload(subscription.Secret, timestamp, body))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the exponential delay before the next attempt, with jitter
+// in the upper half so that retries from a burst of events spread out
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	delay := s.initialBackoff
+	for i := 1; i < attempts && delay < s.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.maxBackoff {
+		delay = s.maxBackoff
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	jitter, err := rand.Int(rand.Reader, big.NewInt(int64(half)))
+	if err != nil {
+		return delay
+	}
+	return half + time.Duration(jitter.Int64())
+}
+
+func (s *WebhookService) saveDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	item, err := attributevalue.MarshalMap(delivery)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook delivery: %v", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.deliveryTable),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put webhook delivery: %v", err)
+	}
+	return nil
+}
+
+// signWebhookPayload computes the hex HMAC-SHA256 of "<timestamp>.<body>".
+// Receivers recompute it with their copy of the secret to authenticate a delivery.
+func signWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
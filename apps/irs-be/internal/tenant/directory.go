@@ -0,0 +1,178 @@
+package tenant
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"irs-be/internal/config"
+	"irs-be/internal/models"
+)
+
+// teamName is the form of team names, which end up in cache keys and metric labels
+var teamName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// defaultNotifyEvents are the events posted as incident cards when a team does not choose
+var defaultNotifyEvents = []models.TicketEventType{models.TicketEventCreated, models.TicketEventEscalated}
+
+// ValidTeam reports whether a team name is well formed
+func ValidTeam(team string) bool {
+	return teamName.MatchString(team)
+}
+
+// Duration is a time.Duration written as a Go duration string, e.g. "4h"
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Settings is the configuration of a team
+type Settings struct {
+	Team string `json:"team"`
+	// SLA is the time to resolve a ticket by severity; severities left out use the global SLA
+	SLA map[string]Duration `json:"sla"`
+	// SlackWebhookURL receives the team's incident cards instead of SLACK_WEBHOOK_URL
+	SlackWebhookURL config.Secret `json:"slackWebhookUrl,omitempty"`
+	// SlackChannels are the channels whose slash commands and buttons act on the team's tickets
+	SlackChannels []string `json:"slackChannels,omitempty"`
+	// NotifyEvents are the events posted as incident cards
+	NotifyEvents []models.TicketEventType `json:"notifyEvents"`
+}
+
+// Notifies reports whether the team wants incident cards for an event type
+func (s Settings) Notifies(eventType models.TicketEventType) bool {
+	for _, e := range s.NotifyEvents {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Directory holds the settings of every configured team
+type Directory struct {
+	teams    map[string]Settings
+	channels map[string]string
+	sla      map[string]time.Duration
+}
+
+// Load reads the team settings from a JSON file mapping team names to settings, e.g.
+//
+//	{"payments": {"sla": {"critical": "1h"}, "slackWebhookUrl": "https://hooks.slack.com/...",
+//	  "slackChannels": ["C0123ABC"], "notifyEvents": ["created", "escalated", "resolved"]}}
+//
+// sla is the global SLA by severity. An empty path configures no team, leaving every
+// team on the global settings.
+func Load(path string, sla map[string]time.Duration) (*Directory, error) {
+	d := &Directory{teams: map[string]Settings{}, channels: map[string]string{}, sla: sla}
+	if path == "" {
+		return d, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %v", err)
+	}
+	var teams map[string]Settings
+	if err := json.Unmarshal(data, &teams); err != nil {
+		return nil, fmt.Errorf("failed to parse tenants file %s: %v", path, err)
+	}
+
+	for team, settings := range teams {
+		if !ValidTeam(team) {
+			return nil, fmt.Errorf("invalid team name %q in %s: use lowercase letters, digits, - and _", team, path)
+		}
+		for severity := range settings.SLA {
+			if !models.Severity(severity).IsValid() {
+				return nil, fmt.Errorf("team %s: unknown severity %q in sla", team, severity)
+			}
+		}
+		for _, e := range settings.NotifyEvents {
+			if !e.IsValid() {
+				return nil, fmt.Errorf("team %s: unknown event type %q in notifyEvents", team, e)
+			}
+		}
+		for _, channel := range settings.SlackChannels {
+			if other, ok := d.channels[channel]; ok {
+				return nil, fmt.Errorf("slack channel %s is assigned to both %s and %s", channel, other, team)
+			}
+			d.channels[channel] = team
+		}
+		d.teams[team] = settings
+	}
+	return d, nil
+}
+
+// Teams lists the configured teams and the default team, sorted
+func (d *Directory) Teams() []string {
+	teams := []string{Default()}
+	for team := range d.teams {
+		if team != Default() {
+			teams = append(teams, team)
+		}
+	}
+	sort.Strings(teams)
+	return teams
+}
+
+// Settings returns the effective settings of a team: its own where configured, the
+// global ones otherwise
+func (d *Directory) Settings(team string) Settings {
+	team = Resolve(team)
+	own := d.teams[team]
+
+	settings := Settings{
+		Team:            team,
+		SLA:             map[string]Duration{},
+		SlackWebhookURL: own.SlackWebhookURL,
+		SlackChannels:   own.SlackChannels,
+		NotifyEvents:    own.NotifyEvents,
+	}
+	for severity, sla := range d.sla {
+		settings.SLA[severity] = Duration(sla)
+	}
+	for severity, sla := range own.SLA {
+		settings.SLA[severity] = sla
+	}
+	if settings.NotifyEvents == nil {
+		settings.NotifyEvents = defaultNotifyEvents
+	}
+	return settings
+}
+
+// SLA returns the time a team has to resolve a ticket of the given severity
+func (d *Directory) SLA(team, severity string) (time.Duration, bool) {
+	if sla, ok := d.teams[Resolve(team)].SLA[severity]; ok {
+		return time.Duration(sla), true
+	}
+	sla, ok := d.sla[severity]
+	return sla, ok
+}
+
+// TeamForChannel returns the team a Slack channel belongs to. Channels no team
+// claims belong to the default team.
+func (d *Directory) TeamForChannel(channel string) string {
+	if team, ok := d.channels[channel]; ok {
+		return team
+	}
+	return Default()
+}
@@ -0,0 +1,105 @@
+// Package tenant scopes tickets and the data hanging off them to the team that
+// owns them, so several teams can share one incident table.
+package tenant
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// defaultTeam owns the items written without a team, such as the tickets created
+// by the lambdas and every item written before teams were introduced
+var defaultTeam atomic.Value
+
+func init() {
+	defaultTeam.Store("default")
+}
+
+// SetDefault sets the team that owns items without a team
+func SetDefault(team string) {
+	if team != "" {
+		defaultTeam.Store(team)
+	}
+}
+
+// Default returns the team that owns items without a team
+func Default() string {
+	return defaultTeam.Load().(string)
+}
+
+// Resolve returns the team owning an item, given its team attribute
+func Resolve(team string) string {
+	if team == "" {
+		return Default()
+	}
+	return team
+}
+
+// Scope is the set of teams a request may read and write: either a single team or,
+// for platform admins and background work, all of them. The zero value spans all teams.
+type Scope struct {
+	team string
+}
+
+// Team returns the scope of a single team
+func Team(team string) Scope {
+	return Scope{team: Resolve(team)}
+}
+
+// All returns the scope spanning every team
+func All() Scope {
+	return Scope{}
+}
+
+// IsAll reports whether the scope spans every team
+func (s Scope) IsAll() bool {
+	return s.team == ""
+}
+
+// Team returns the team of a single-team scope, or "" for all teams
+func (s Scope) Team() string {
+	return s.team
+}
+
+// IsDefault reports whether the scope is the default team, which also owns the
+// items without a team
+func (s Scope) IsDefault() bool {
+	return s.team != "" && s.team == Default()
+}
+
+// Includes reports whether an item with the given team attribute is in scope
+func (s Scope) Includes(team string) bool {
+	return s.team == "" || Resolve(team) == s.team
+}
+
+// Key identifies the scope in cache keys
+func (s Scope) Key() string {
+	if s.IsAll() {
+		return "*"
+	}
+	return s.team
+}
+
+func (s Scope) String() string {
+	if s.IsAll() {
+		return "all teams"
+	}
+	return "team " + s.team
+}
+
+type scopeKey struct{}
+
+// WithScope returns a context carrying the scope
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// FromContext returns the scope of a context. Contexts without one span every team:
+// API requests always carry the scope of their principal, so only background jobs,
+// the admin API and the CLI run unscoped.
+func FromContext(ctx context.Context) Scope {
+	if scope, ok := ctx.Value(scopeKey{}).(Scope); ok {
+		return scope
+	}
+	return All()
+}
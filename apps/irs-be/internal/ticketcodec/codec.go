@@ -0,0 +1,161 @@
+package ticketcodec
+
+import (
+	"fmt"
+	"strconv"
+
+	"irs-be/internal/models"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// VersionAttribute holds the schema version of a ticket item
+	VersionAttribute = "schemaVersion"
+	// CurrentVersion is the item shape written by this build. Items without a
+	// version attribute were written by the lambdas or by older builds and are
+	// version 0.
+	CurrentVersion = 2
+
+	// IncidentTypeAttribute holds the incident type from version 2 on
+	IncidentTypeAttribute = "incident_type"
+	// LegacyIncidentTypeAttribute is the misspelled attribute the lambdas write and
+	// IncidentTypeIndex is keyed on. It is still written next to incident_type so
+	// both keep working until the cutover is complete, and wins when they differ.
+	LegacyIncidentTypeAttribute = "insident_type"
+
+	// WriteIDAttribute is replaced with a fresh ID by every irs-be write that can
+	// change a ticket's lifecycle. The lambdas never set it, so a change that leaves
+	// it as it was, as seen on the table's stream, was made outside irs-be.
+	WriteIDAttribute = "writeId"
+)
+
+// ReadAttributes are the attributes a projection needs besides those of
+// models.IncidentTicket for an item to decode the same as a full read
+var ReadAttributes = []string{VersionAttribute, LegacyIncidentTypeAttribute}
+
+// Upgrader rewrites an item of one schema version into the shape of the next.
+// It may replace or delete attributes of the map it is given but must not modify
+// the attribute values themselves, which are shared with the caller. Partial
+// updates keep the version of the item they touch, so an upgrader also has to
+// accept attributes that already have the newer shape.
+type Upgrader func(item map[string]types.AttributeValue) error
+
+// upgraders maps a schema version to the upgrader that lifts it to the next one
+var upgraders = map[int]Upgrader{}
+
+// Register adds the upgrader from a schema version to the next one. It is meant
+// to be called from init functions and panics on a duplicate or a version that
+// is not older than CurrentVersion.
+func Register(from int, upgrade Upgrader) {
+	if from < 0 || from >= CurrentVersion {
+		panic(fmt.Sprintf("ticketcodec: cannot register an upgrader from version %d, current version is %d", from, CurrentVersion))
+	}
+	if _, ok := upgraders[from]; ok {
+		panic(fmt.Sprintf("ticketcodec: upgrader from version %d registered twice", from))
+	}
+	upgraders[from] = upgrade
+}
+
+// DecodeError reports an item that could not be mapped to a ticket
+type DecodeError struct {
+	ID      string
+	Version int
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	id := e.ID
+	if id == "" {
+		id = "(no id)"
+	}
+	return fmt.Sprintf("cannot decode ticket %s (schema version %d): %v", id, e.Version, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Version returns the schema version of an item, 0 when it has none
+func Version(item map[string]types.AttributeValue) (int, error) {
+	switch v := item[VersionAttribute].(type) {
+	case nil:
+		return 0, nil
+	case *types.AttributeValueMemberN:
+		return strconv.Atoi(v.Value)
+	case *types.AttributeValueMemberS:
+		return strconv.Atoi(v.Value)
+	}
+	return 0, fmt.Errorf("%s has an unexpected type %T", VersionAttribute, item[VersionAttribute])
+}
+
+// Decode upgrades an item to the current schema version and maps it to a ticket.
+// The item itself is not modified.
+func Decode(item map[string]types.AttributeValue) (models.IncidentTicket, error) {
+	var ticket models.IncidentTicket
+	id := ""
+	if v, ok := item["id"].(*types.AttributeValueMemberS); ok {
+		id = v.Value
+	}
+
+	version, err := Version(item)
+	if err != nil {
+		return ticket, &DecodeError{ID: id, Err: err}
+	}
+	if version > CurrentVersion {
+		return ticket, &DecodeError{ID: id, Version: version, Err: fmt.Errorf("written by a newer build, this one reads up to version %d", CurrentVersion)}
+	}
+
+	upgraded := item
+	if version < CurrentVersion {
+		upgraded = make(map[string]types.AttributeValue, len(item))
+		for k, v := range item {
+			upgraded[k] = v
+		}
+		for v := version; v < CurrentVersion; v++ {
+			upgrade, ok := upgraders[v]
+			if !ok {
+				return ticket, &DecodeError{ID: id, Version: version, Err: fmt.Errorf("no upgrader from version %d", v)}
+			}
+			if err := upgrade(upgraded); err != nil {
+				return ticket, &DecodeError{ID: id, Version: version, Err: fmt.Errorf("upgrade from version %d: %v", v, err)}
+			}
+		}
+	}
+
+	if err := attributevalue.UnmarshalMap(upgraded, &ticket); err != nil {
+		return models.IncidentTicket{}, &DecodeError{ID: id, Version: version, Err: err}
+	}
+	if ticket.ID == "" {
+		return models.IncidentTicket{}, &DecodeError{Version: version, Err: fmt.Errorf("missing id")}
+	}
+	// The lambdas still update only insident_type, so incident_type can be stale on
+	// items of any version. insident_type wins until they write both; it is also what
+	// IncidentTypeIndex and the type filter read.
+	if v, ok := item[LegacyIncidentTypeAttribute].(*types.AttributeValueMemberS); ok && v.Value != "" {
+		ticket.IncidentType = models.IncidentType(v.Value)
+	}
+	return ticket, nil
+}
+
+// NewWriteID returns a value for WriteIDAttribute
+func NewWriteID() types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: uuid.NewString()}
+}
+
+// Encode maps a ticket to an item of the current schema version, with the legacy
+// incident type attribute alongside the current one and a fresh write ID
+func Encode(ticket models.IncidentTicket) (map[string]types.AttributeValue, error) {
+	item, err := attributevalue.MarshalMap(ticket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ticket %s: %v", ticket.ID, err)
+	}
+	item[VersionAttribute] = &types.AttributeValueMemberN{Value: strconv.Itoa(CurrentVersion)}
+	if v, ok := item[IncidentTypeAttribute]; ok {
+		item[LegacyIncidentTypeAttribute] = v
+	}
+	item[WriteIDAttribute] = NewWriteID()
+	return item, nil
+}
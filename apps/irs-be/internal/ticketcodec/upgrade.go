@@ -0,0 +1,105 @@
+package ticketcodec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func init() {
+	Register(0, upgradeLegacy)
+	Register(1, upgradeIncidentType)
+}
+
+var (
+	// stringAttributes are written as S by irs-be
+	stringAttributes = []string{
+		"id", "title", "description", "report", "severity", "category", "insident_type", "incident_type",
+		"environment", "actionStatus", "status", "reporter", "createdAt",
+		"resolutionTime", "emailSentAt", "actionTaken",
+	}
+	// listAttributes are written as L of S by irs-be
+	listAttributes = []string{"suggestions", "affectedServices", "tags"}
+)
+
+// upgradeLegacy lifts version 0 items, written by the lambdas, the dummy data
+// script or irs-be before items were versioned, to version 1. Those writers did not
+// agree on attribute types: lists arrive as string sets or single strings,
+// emailSent as "true" or 1, and absent values as NULL.
+func upgradeLegacy(item map[string]types.AttributeValue) error {
+	for name, value := range item {
+		if _, ok := value.(*types.AttributeValueMemberNULL); ok {
+			delete(item, name)
+		}
+	}
+
+	for _, name := range stringAttributes {
+		if v, ok := item[name].(*types.AttributeValueMemberN); ok {
+			item[name] = &types.AttributeValueMemberS{Value: v.Value}
+		}
+	}
+
+	for _, name := range listAttributes {
+		switch v := item[name].(type) {
+		case *types.AttributeValueMemberSS:
+			item[name] = stringList(v.Value)
+		case *types.AttributeValueMemberS:
+			if strings.TrimSpace(v.Value) == "" {
+				delete(item, name)
+			} else {
+				item[name] = stringList([]string{v.Value})
+			}
+		case *types.AttributeValueMemberL:
+			values := make([]string, 0, len(v.Value))
+			for _, element := range v.Value {
+				switch e := element.(type) {
+				case *types.AttributeValueMemberS:
+					values = append(values, e.Value)
+				case *types.AttributeValueMemberN:
+					values = append(values, e.Value)
+				case *types.AttributeValueMemberNULL:
+				default:
+					return fmt.Errorf("%s has an element of unexpected type %T", name, element)
+				}
+			}
+			item[name] = stringList(values)
+		}
+	}
+
+	switch v := item["emailSent"].(type) {
+	case *types.AttributeValueMemberS:
+		sent, err := strconv.ParseBool(strings.TrimSpace(v.Value))
+		if err != nil {
+			return fmt.Errorf("emailSent %q is not a boolean", v.Value)
+		}
+		item["emailSent"] = &types.AttributeValueMemberBOOL{Value: sent}
+	case *types.AttributeValueMemberN:
+		item["emailSent"] = &types.AttributeValueMemberBOOL{Value: v.Value != "0"}
+	}
+
+	return nil
+}
+
+// upgradeIncidentType lifts version 1 items to version 2, which reads the incident
+// type from incident_type. Items the lambdas write, and those not yet backfilled
+// by the incident type migration, only have insident_type. When both are present
+// Decode still prefers insident_type, which the lambdas keep updating alone.
+func upgradeIncidentType(item map[string]types.AttributeValue) error {
+	if _, ok := item[IncidentTypeAttribute]; ok {
+		return nil
+	}
+	if v, ok := item[LegacyIncidentTypeAttribute]; ok {
+		item[IncidentTypeAttribute] = v
+	}
+	return nil
+}
+
+func stringList(values []string) *types.AttributeValueMemberL {
+	list := make([]types.AttributeValue, len(values))
+	for i, v := range values {
+		list[i] = &types.AttributeValueMemberS{Value: v}
+	}
+	return &types.AttributeValueMemberL{Value: list}
+}
@@ -0,0 +1,255 @@
+package ticketquery
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"irs-be/internal/models"
+)
+
+// Node is an element of a parsed query. The same tree is evaluated in memory
+// with Match and compiled to a DynamoDB FilterExpression with Compile.
+type Node interface {
+	// Match reports whether a ticket satisfies the node; relative times such as
+	// now-7d are resolved against now
+	Match(ticket models.IncidentTicket, now time.Time) bool
+	// String renders the node back as query text in a canonical form
+	String() string
+}
+
+// And matches when every node matches. An empty And matches every ticket.
+type And []Node
+
+// Or matches when any node matches
+type Or []Node
+
+// Not matches when its node does not
+type Not struct {
+	Node Node
+}
+
+// Term matches when a field equals one of the values; for list fields such as
+// tag, when any element does
+type Term struct {
+	Field  string
+	Values []string
+}
+
+// Range compares a time field with an absolute or relative time
+type Range struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// Text matches tickets whose title, description or report contain the text,
+// ignoring case
+type Text struct {
+	Value string
+}
+
+func (n And) Match(ticket models.IncidentTicket, now time.Time) bool {
+	for _, node := range n {
+		if !node.Match(ticket, now) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n Or) Match(ticket models.IncidentTicket, now time.Time) bool {
+	for _, node := range n {
+		if node.Match(ticket, now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n Not) Match(ticket models.IncidentTicket, now time.Time) bool {
+	return !n.Node.Match(ticket, now)
+}
+
+func (n Term) Match(ticket models.IncidentTicket, now time.Time) bool {
+	f, ok := lookupField(n.Field)
+	if !ok {
+		return false
+	}
+	for _, have := range f.values(ticket) {
+		for _, want := range n.Values {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (n Range) Match(ticket models.IncidentTicket, now time.Time) bool {
+	f, ok := lookupField(n.Field)
+	if !ok {
+		return false
+	}
+	have, ok := f.ticketTime(ticket)
+	if !ok {
+		return false
+	}
+	bound, err := resolveTime(n.Value, now)
+	if err != nil {
+		return false
+	}
+	switch n.Op {
+	case ">":
+		return have.After(bound)
+	case ">=":
+		return !have.Before(bound)
+	case "<":
+		return have.Before(bound)
+	case "<=":
+		return !have.After(bound)
+	}
+	return false
+}
+
+func (n Text) Match(ticket models.IncidentTicket, now time.Time) bool {
+	text := strings.ToLower(n.Value)
+	return strings.Contains(strings.ToLower(ticket.Title), text) ||
+		strings.Contains(strings.ToLower(ticket.Description), text) ||
+		strings.Contains(strings.ToLower(ticket.Report), text)
+}
+
+func (n And) String() string {
+	parts := make([]string, len(n))
+	for i, node := range n {
+		parts[i] = node.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+func (n Or) String() string {
+	parts := make([]string, len(n))
+	for i, node := range n {
+		parts[i] = node.String()
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
+
+func (n Not) String() string {
+	if and, ok := n.Node.(And); ok && len(and) > 1 {
+		return "-(" + and.String() + ")"
+	}
+	return "-" + n.Node.String()
+}
+
+func (n Term) String() string {
+	if len(n.Values) == 1 {
+		return n.Field + ":" + quote(n.Values[0])
+	}
+	values := make([]string, len(n.Values))
+	for i, v := range n.Values {
+		values[i] = quote(v)
+	}
+	return n.Field + ":(" + strings.Join(values, " OR ") + ")"
+}
+
+func (n Range) String() string {
+	return n.Field + n.Op + quote(n.Value)
+}
+
+func (n Text) String() string {
+	return strconv.Quote(n.Value)
+}
+
+// quote quotes a value when it would not be read back as a single word
+func quote(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\"():<>=") || isKeyword(value) {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// Conjuncts splits a query into the nodes that must all match
+func Conjuncts(node Node) []Node {
+	and, ok := node.(And)
+	if !ok {
+		return []Node{node}
+	}
+	var nodes []Node
+	for _, n := range and {
+		nodes = append(nodes, Conjuncts(n)...)
+	}
+	return nodes
+}
+
+// UsesText reports whether a query contains free text, which is also matched
+// against the ticket report
+func UsesText(node Node) bool {
+	switch n := node.(type) {
+	case Text:
+		return true
+	case Not:
+		return UsesText(n.Node)
+	case And:
+		for _, child := range n {
+			if UsesText(child) {
+				return true
+			}
+		}
+	case Or:
+		for _, child := range n {
+			if UsesText(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var relativeTime = regexp.MustCompile(`^now(?:([+-])(\d+)([mhdw]))?$`)
+
+// resolveTime turns now, now-7d, now+2h or an absolute timestamp into a time
+func resolveTime(value string, now time.Time) (time.Time, error) {
+	if m := relativeTime.FindStringSubmatch(value); m != nil {
+		if m[1] == "" {
+			return now, nil
+		}
+		n, _ := strconv.Atoi(m[2])
+		unit := map[string]time.Duration{"m": time.Minute, "h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[m[3]]
+		offset := time.Duration(n) * unit
+		if m[1] == "-" {
+			offset = -offset
+		}
+		return now.Add(offset), nil
+	}
+
+	t, err := models.ParseTimestamp(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, use now, now-7d, now-12h, 2006-01-02 or an RFC 3339 timestamp", value)
+	}
+	return t, nil
+}
+
+// FromFilters turns the single-value filter parameters into an equivalent query
+func FromFilters(f models.TicketFilters) And {
+	query := And{}
+	for _, filter := range []struct{ field, value string }{
+		{"severity", f.Severity},
+		{"category", f.Category},
+		{"env", f.Environment},
+		{"status", f.Status},
+		{"actionStatus", f.ActionStatus},
+		{"type", f.IncidentType},
+		{"team", f.Team},
+	} {
+		if filter.value != "" {
+			query = append(query, Term{Field: filter.field, Values: []string{filter.value}})
+		}
+	}
+	if f.Search != "" {
+		query = append(query, Text{Value: f.Search})
+	}
+	return query
+}
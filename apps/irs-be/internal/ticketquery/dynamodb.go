@@ -0,0 +1,158 @@
+package ticketquery
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"irs-be/internal/tenant"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Builder collects the placeholders of a DynamoDB expression
+type Builder struct {
+	Names  map[string]string
+	Values map[string]types.AttributeValue
+}
+
+// NewBuilder creates an empty builder
+func NewBuilder() *Builder {
+	return &Builder{
+		Names:  map[string]string{},
+		Values: map[string]types.AttributeValue{},
+	}
+}
+
+// Name returns the placeholder of an attribute name, reusing it when already added
+func (b *Builder) Name(attribute string) string {
+	for placeholder, name := range b.Names {
+		if name == attribute {
+			return placeholder
+		}
+	}
+	placeholder := fmt.Sprintf("#n%d", len(b.Names))
+	b.Names[placeholder] = attribute
+	return placeholder
+}
+
+// Value returns a new placeholder for a string value
+func (b *Builder) Value(value string) string {
+	placeholder := fmt.Sprintf(":v%d", len(b.Values))
+	b.Values[placeholder] = &types.AttributeValueMemberS{Value: value}
+	return placeholder
+}
+
+// Compile translates a node into a DynamoDB condition. ok is false when nothing of
+// the node can be expressed, such as free text, which must match case-insensitively.
+// exact is false when the condition only narrows the result and the node still has
+// to be evaluated in memory: time ranges are compared on the date alone, widened by
+// a day, because stored timestamps do not share one format.
+func Compile(node Node, b *Builder, now time.Time) (condition string, ok bool, exact bool) {
+	switch n := node.(type) {
+	case Term:
+		f, found := lookupField(n.Field)
+		if !found {
+			return "", false, false
+		}
+		name := b.Name(f.attribute)
+		var parts []string
+		for _, value := range n.Values {
+			if value == "" {
+				// A missing attribute reads as "" in memory but never equals "" in DynamoDB
+				return "", false, false
+			}
+			if f.kind == listField {
+				parts = append(parts, fmt.Sprintf("contains(%s, %s)", name, b.Value(value)))
+			} else {
+				parts = append(parts, b.Value(value))
+			}
+		}
+		if f.kind == listField {
+			return group(parts, " OR "), true, true
+		}
+		if f.kind == teamField {
+			for _, value := range n.Values {
+				if value == tenant.Default() {
+					condition := name + " IN (" + strings.Join(parts, ", ") + ")"
+					return group([]string{condition, "attribute_not_exists(" + name + ")"}, " OR "), true, true
+				}
+			}
+		}
+		if len(parts) == 1 {
+			return name + " = " + parts[0], true, true
+		}
+		return fmt.Sprintf("%s IN (%s)", name, strings.Join(parts, ", ")), true, true
+
+	case Range:
+		f, found := lookupField(n.Field)
+		if !found {
+			return "", false, false
+		}
+		bound, err := resolveTime(n.Value, now)
+		if err != nil {
+			return "", false, false
+		}
+		name := b.Name(f.attribute)
+		switch n.Op {
+		case ">", ">=":
+			return fmt.Sprintf("%s >= %s", name, b.Value(bound.Add(-24*time.Hour).Format("2006-01-02"))), true, false
+		default:
+			return fmt.Sprintf("%s < %s", name, b.Value(bound.Add(48*time.Hour).Format("2006-01-02"))), true, false
+		}
+
+	case Not:
+		// Negating a widened condition would drop matches, so only exact ones qualify
+		inner, innerOK, innerExact := Compile(n.Node, b, now)
+		if !innerOK || !innerExact {
+			return "", false, false
+		}
+		return "NOT " + paren(inner), true, true
+
+	case Or:
+		parts := make([]string, 0, len(n))
+		exact = true
+		for _, child := range n {
+			part, childOK, childExact := Compile(child, b, now)
+			if !childOK {
+				return "", false, false
+			}
+			parts = append(parts, paren(part))
+			exact = exact && childExact
+		}
+		return strings.Join(parts, " OR "), true, exact
+
+	case And:
+		parts := make([]string, 0, len(n))
+		exact = true
+		for _, child := range n {
+			part, childOK, childExact := Compile(child, b, now)
+			if !childOK {
+				exact = false
+				continue
+			}
+			parts = append(parts, paren(part))
+			exact = exact && childExact
+		}
+		if len(parts) == 0 {
+			return "", false, false
+		}
+		return strings.Join(parts, " AND "), true, exact
+	}
+
+	return "", false, false
+}
+
+func group(parts []string, sep string) string {
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, sep) + ")"
+}
+
+func paren(condition string) string {
+	if strings.Contains(condition, " AND ") || strings.Contains(condition, " OR ") {
+		return "(" + condition + ")"
+	}
+	return condition
+}
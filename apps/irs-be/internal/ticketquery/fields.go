@@ -0,0 +1,110 @@
+package ticketquery
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"irs-be/internal/models"
+	"irs-be/internal/tenant"
+)
+
+type fieldKind int
+
+const (
+	// stringField holds a single value compared for equality
+	stringField fieldKind = iota
+	// listField holds several values; a term matches when any of them is equal
+	listField
+	// timeField holds a timestamp compared with >, >=, < and <=
+	timeField
+	// teamField holds the owning team; items without one belong to the default team
+	teamField
+)
+
+// field is a ticket attribute that can be used in a query
+type field struct {
+	name      string
+	attribute string
+	kind      fieldKind
+	values    func(models.IncidentTicket) []string
+}
+
+func one(get func(models.IncidentTicket) string) func(models.IncidentTicket) []string {
+	return func(t models.IncidentTicket) []string { return []string{get(t)} }
+}
+
+func optional(get func(models.IncidentTicket) *string) func(models.IncidentTicket) []string {
+	return func(t models.IncidentTicket) []string {
+		if v := get(t); v != nil {
+			return []string{*v}
+		}
+		return nil
+	}
+}
+
+var fields = []field{
+	{name: "id", attribute: "id", kind: stringField, values: one(func(t models.IncidentTicket) string { return t.ID })},
+	{name: "severity", attribute: "severity", kind: stringField, values: one(func(t models.IncidentTicket) string { return string(t.Severity) })},
+	{name: "status", attribute: "status", kind: stringField, values: one(func(t models.IncidentTicket) string { return string(t.Status) })},
+	{name: "category", attribute: "category", kind: stringField, values: one(func(t models.IncidentTicket) string { return string(t.Category) })},
+	{name: "env", attribute: "environment", kind: stringField, values: one(func(t models.IncidentTicket) string { return string(t.Environment) })},
+	{name: "type", attribute: "insident_type", kind: stringField, values: one(func(t models.IncidentTicket) string { return string(t.IncidentType) })},
+	{name: "actionStatus", attribute: "actionStatus", kind: stringField, values: one(func(t models.IncidentTicket) string { return string(t.ActionStatus) })},
+	{name: "team", attribute: "team", kind: teamField, values: one(func(t models.IncidentTicket) string { return tenant.Resolve(t.Team) })},
+	{name: "reporter", attribute: "reporter", kind: stringField, values: one(func(t models.IncidentTicket) string { return t.Reporter })},
+	{name: "tag", attribute: "tags", kind: listField, values: func(t models.IncidentTicket) []string { return t.Tags }},
+	{name: "service", attribute: "affectedServices", kind: listField, values: func(t models.IncidentTicket) []string { return t.AffectedServices }},
+	{name: "createdAt", attribute: "createdAt", kind: timeField, values: one(func(t models.IncidentTicket) string { return t.CreatedAt })},
+	{name: "resolvedAt", attribute: "resolutionTime", kind: timeField, values: optional(func(t models.IncidentTicket) *string { return t.ResolutionTime })},
+}
+
+// aliases maps alternative spellings, including the query parameter and JSON names,
+// to field names
+var aliases = map[string]string{
+	"environment":      "env",
+	"incidenttype":     "type",
+	"insident_type":    "type",
+	"incident_type":    "type",
+	"tags":             "tag",
+	"services":         "service",
+	"affectedservices": "service",
+	"resolutiontime":   "resolvedAt",
+}
+
+// lookupField finds a field by name or alias, ignoring case
+func lookupField(name string) (*field, bool) {
+	lower := strings.ToLower(name)
+	if alias, ok := aliases[lower]; ok {
+		lower = strings.ToLower(alias)
+	}
+	for i := range fields {
+		if strings.ToLower(fields[i].name) == lower {
+			return &fields[i], true
+		}
+	}
+	return nil, false
+}
+
+// FieldNames lists the fields a query can use
+func FieldNames() []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ticketTime returns the time stored in a time field
+func (f *field) ticketTime(t models.IncidentTicket) (time.Time, bool) {
+	values := f.values(t)
+	if len(values) == 0 || values[0] == "" {
+		return time.Time{}, false
+	}
+	parsed, err := models.ParseTimestamp(values[0])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return parsed, true
+}
@@ -0,0 +1,352 @@
+package ticketquery
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"irs-be/internal/models"
+)
+
+// Error is a query syntax error. Pos is the byte offset of the offending token.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid query at position %d: %s", e.Pos+1, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenQuoted
+	tokenLParen
+	tokenRParen
+	tokenColon
+	tokenCompare
+	tokenMinus
+	tokenOr
+	tokenAnd
+	tokenNot
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func isKeyword(word string) bool {
+	return word == "OR" || word == "AND" || word == "NOT"
+}
+
+// lex splits a query into tokens. A '-' starts a negation only where a new term
+// begins, so values such as now-7d or 2024-01-01 stay whole, and a ':' after a
+// comparison is part of the value, so unquoted RFC 3339 timestamps do too.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	termStart := func() bool {
+		if len(tokens) == 0 {
+			return true
+		}
+		kind := tokens[len(tokens)-1].kind
+		return kind != tokenColon && kind != tokenCompare
+	}
+
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case c == ':':
+			tokens = append(tokens, token{kind: tokenColon, value: ":", pos: i})
+			i++
+		case c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(input) && input[i+1] == '=' {
+				op += "="
+			}
+			tokens = append(tokens, token{kind: tokenCompare, value: op, pos: i})
+			i += len(op)
+		case c == '=':
+			return nil, &Error{Pos: i, Msg: `unexpected "=", use field:value for equality`}
+		case c == '-' && termStart():
+			tokens = append(tokens, token{kind: tokenMinus, value: "-", pos: i})
+			i++
+		case c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(input) && input[j] != '"'; j++ {
+				if input[j] == '\\' && j+1 < len(input) {
+					j++
+				}
+				b.WriteByte(input[j])
+			}
+			if j == len(input) {
+				return nil, &Error{Pos: i, Msg: "unterminated quoted string"}
+			}
+			tokens = append(tokens, token{kind: tokenQuoted, value: b.String(), pos: i})
+			i = j + 1
+		default:
+			stop := " \t\n\r():<>=\""
+			if len(tokens) > 0 && tokens[len(tokens)-1].kind == tokenCompare {
+				stop = " \t\n\r()<>=\""
+			}
+			j := i
+			for j < len(input) && !strings.ContainsRune(stop, rune(input[j])) {
+				j++
+			}
+			word := input[i:j]
+			kind := tokenWord
+			switch word {
+			case "OR":
+				kind = tokenOr
+			case "AND":
+				kind = tokenAnd
+			case "NOT":
+				kind = tokenNot
+			}
+			tokens = append(tokens, token{kind: kind, value: word, pos: i})
+			i = j
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// Parse parses a query such as
+//
+//	severity:(critical OR high) env:production -status:closed createdAt>now-7d tag:eks "disk full"
+//
+// Terms next to each other must all match; OR binds looser than the implicit AND,
+// and parentheses group. A leading '-' or NOT negates a term or group. Words that
+// are not field terms, and quoted phrases, match the title, description or report.
+// An empty query matches every ticket.
+func Parse(input string) (Node, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return And{}, nil
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		if t.kind == tokenRParen {
+			return nil, &Error{Pos: t.pos, Msg: `unexpected ")" without a matching "("`}
+		}
+		return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.value)}
+	}
+	return node, nil
+}
+
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := Or{first}
+	for p.peek().kind == tokenOr {
+		or := p.next()
+		if k := p.peek().kind; k == tokenEOF || k == tokenRParen || k == tokenOr {
+			return nil, &Error{Pos: or.pos, Msg: `"OR" must be followed by a term`}
+		}
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return nodes, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	var nodes And
+	for {
+		switch p.peek().kind {
+		case tokenEOF, tokenRParen, tokenOr:
+			if len(nodes) == 0 {
+				t := p.peek()
+				return nil, &Error{Pos: t.pos, Msg: "expected a term"}
+			}
+			if len(nodes) == 1 {
+				return nodes[0], nil
+			}
+			return nodes, nil
+		case tokenAnd:
+			and := p.next()
+			if len(nodes) == 0 {
+				return nil, &Error{Pos: and.pos, Msg: `"AND" must follow a term`}
+			}
+			if k := p.peek().kind; k == tokenEOF || k == tokenRParen || k == tokenOr || k == tokenAnd {
+				return nil, &Error{Pos: and.pos, Msg: `"AND" must be followed by a term`}
+			}
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if k := p.peek().kind; k == tokenMinus || k == tokenNot {
+		neg := p.next()
+		if k := p.peek().kind; k == tokenEOF || k == tokenRParen || k == tokenOr || k == tokenAnd {
+			return nil, &Error{Pos: neg.pos, Msg: fmt.Sprintf("%q must be followed by a term", neg.value)}
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Node: node}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenRParen {
+			return nil, &Error{Pos: t.pos, Msg: `"(" is never closed`}
+		}
+		p.next()
+		return node, nil
+	case tokenQuoted:
+		return Text{Value: t.value}, nil
+	case tokenWord:
+		switch p.peek().kind {
+		case tokenColon:
+			p.next()
+			return p.parseTerm(t)
+		case tokenCompare:
+			return p.parseRange(t, p.next())
+		}
+		return Text{Value: t.value}, nil
+	case tokenEOF:
+		return nil, &Error{Pos: t.pos, Msg: "unexpected end of query"}
+	}
+	return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.value)}
+}
+
+// parseTerm parses the values after field:
+func (p *parser) parseTerm(name token) (Node, error) {
+	f, err := p.field(name)
+	if err != nil {
+		return nil, err
+	}
+	if f.kind == timeField {
+		return nil, &Error{Pos: name.pos, Msg: fmt.Sprintf("%s is a time, compare it with >, >=, < or <=", f.name)}
+	}
+
+	if p.peek().kind != tokenLParen {
+		value, err := p.value(f)
+		if err != nil {
+			return nil, err
+		}
+		return Term{Field: f.name, Values: []string{value}}, nil
+	}
+
+	open := p.next()
+	var values []string
+	for {
+		value, err := p.value(f)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		switch t := p.next(); t.kind {
+		case tokenOr:
+		case tokenRParen:
+			return Term{Field: f.name, Values: values}, nil
+		case tokenEOF:
+			return nil, &Error{Pos: open.pos, Msg: `"(" is never closed`}
+		default:
+			return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf(`expected "OR" or ")" between %s values, got %q`, f.name, t.value)}
+		}
+	}
+}
+
+// parseRange parses the time after field> and friends
+func (p *parser) parseRange(name, op token) (Node, error) {
+	f, err := p.field(name)
+	if err != nil {
+		return nil, err
+	}
+	if f.kind != timeField {
+		return nil, &Error{Pos: op.pos, Msg: fmt.Sprintf("%s cannot be compared with %s, use %s:value", f.name, op.value, f.name)}
+	}
+
+	t := p.next()
+	if t.kind != tokenWord && t.kind != tokenQuoted {
+		return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("expected a time after %s%s", f.name, op.value)}
+	}
+	if _, err := resolveTime(t.value, time.Now()); err != nil {
+		return nil, &Error{Pos: t.pos, Msg: err.Error()}
+	}
+	return Range{Field: f.name, Op: op.value, Value: t.value}, nil
+}
+
+func (p *parser) field(name token) (*field, error) {
+	f, ok := lookupField(name.value)
+	if !ok {
+		return nil, &Error{Pos: name.pos, Msg: fmt.Sprintf("unknown field %q, use one of %s", name.value, strings.Join(FieldNames(), ", "))}
+	}
+	return f, nil
+}
+
+func (p *parser) value(f *field) (string, error) {
+	t := p.next()
+	if t.kind != tokenWord && t.kind != tokenQuoted {
+		return "", &Error{Pos: t.pos, Msg: fmt.Sprintf("expected a value for %s", f.name)}
+	}
+	// "" still finds tickets without the attribute
+	if t.value != "" {
+		if err := models.ValidateEnum(f.attribute, t.value); err != nil {
+			return "", &Error{Pos: t.pos, Msg: err.Error()}
+		}
+	}
+	return t.value, nil
+}
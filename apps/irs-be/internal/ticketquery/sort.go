@@ -0,0 +1,96 @@
+package ticketquery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"irs-be/internal/models"
+)
+
+// SortKey orders tickets by one field
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// sortFields compare two tickets on a field, returning a negative number, zero or a
+// positive number
+var sortFields = map[string]func(a, b models.IncidentTicket) int{
+	"id":     func(a, b models.IncidentTicket) int { return strings.Compare(a.ID, b.ID) },
+	"title":  func(a, b models.IncidentTicket) int { return strings.Compare(a.Title, b.Title) },
+	"status": func(a, b models.IncidentTicket) int { return strings.Compare(string(a.Status), string(b.Status)) },
+	"severity": func(a, b models.IncidentTicket) int {
+		return models.SeverityRank(a.Severity) - models.SeverityRank(b.Severity)
+	},
+	"createdAt": func(a, b models.IncidentTicket) int {
+		return compareTimes(a.CreatedAt, b.CreatedAt)
+	},
+	"resolvedAt": func(a, b models.IncidentTicket) int {
+		return compareTimes(deref(a.ResolutionTime), deref(b.ResolutionTime))
+	},
+}
+
+// ParseSort parses a comma-separated sort order such as "-createdAt,severity",
+// where a leading '-' sorts descending
+func ParseSort(value string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key := SortKey{Field: strings.TrimPrefix(part, "-"), Descending: strings.HasPrefix(part, "-")}
+		if key.Field == "resolutionTime" {
+			key.Field = "resolvedAt"
+		}
+		if _, ok := sortFields[key.Field]; !ok {
+			names := make([]string, 0, len(sortFields))
+			for name := range sortFields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("cannot sort by %q, use one of %s", key.Field, strings.Join(names, ", "))
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Sort orders tickets by the keys, then by ID so the order is stable
+func Sort(tickets []models.IncidentTicket, keys []SortKey) {
+	sort.SliceStable(tickets, func(i, j int) bool {
+		for _, key := range keys {
+			c := sortFields[key.Field](tickets[i], tickets[j])
+			if key.Descending {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return tickets[i].ID < tickets[j].ID
+	})
+}
+
+// compareTimes orders timestamps chronologically; missing or unparseable ones sort first
+func compareTimes(a, b string) int {
+	ta, errA := models.ParseTimestamp(a)
+	tb, errB := models.ParseTimestamp(b)
+	switch {
+	case errA != nil && errB != nil:
+		return 0
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return ta.Compare(tb)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,156 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"irs-be/internal/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// setupInMemory installs a tracer provider that keeps finished spans in memory and
+// restores the previous provider and propagator when the test ends
+func setupInMemory(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := newProvider(config.Config{Tracing: config.TracingConfig{ServiceName: "irs-be-test", SampleRatio: 1}}, sdktrace.WithSyncer(exporter))
+	if err != nil {
+		t.Fatalf("newProvider: %v", err)
+	}
+	otel.SetTracerProvider(provider)
+	return exporter
+}
+
+// newDynamoDBStub answers every DynamoDB call with a ticket item
+func newDynamoDBStub(t *testing.T) *dynamodb.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		fmt.Fprint(w, `{"Item": {"id": {"S": "INC-1"}}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	return dynamodb.New(dynamodb.Options{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("test", "test", ""),
+		BaseEndpoint: aws.String(server.URL),
+	}, InstrumentDynamoDB)
+}
+
+func attributeValue(attributes []attribute.KeyValue, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestRequestSpans(t *testing.T) {
+	exporter := setupInMemory(t)
+	client := newDynamoDBStub(t)
+
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/api/tickets/:id", func(c *fiber.Ctx) error {
+		_, err := client.GetItem(c.UserContext(), &dynamodb.GetItemInput{
+			TableName: aws.String("insident"),
+			Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: c.Params("id")}},
+		})
+		if err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	const traceID, parentID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	req := httptest.NewRequest(http.MethodGet, "/api/tickets/INC-1", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	spans := exporter.GetSpans()
+	var server, dynamo *tracetest.SpanStub
+	for i := range spans {
+		switch spans[i].SpanKind {
+		case trace.SpanKindServer:
+			server = &spans[i]
+		case trace.SpanKindClient:
+			dynamo = &spans[i]
+		}
+	}
+	if server == nil || dynamo == nil {
+		t.Fatalf("spans = %d, want a server and a DynamoDB client span", len(spans))
+	}
+
+	// The server span continues the caller's trace and is named after the route
+	if got := server.SpanContext.TraceID().String(); got != traceID {
+		t.Errorf("server trace ID = %s, want %s from traceparent", got, traceID)
+	}
+	if got := server.Parent.SpanID().String(); got != parentID || !server.Parent.IsRemote() {
+		t.Errorf("server parent = %s remote=%v, want remote %s", got, server.Parent.IsRemote(), parentID)
+	}
+	if server.Name != "GET /api/tickets/:id" {
+		t.Errorf("server span name = %q, want the route template", server.Name)
+	}
+	if v, ok := attributeValue(server.Attributes, "http.response.status_code"); !ok || v.AsInt64() != http.StatusOK {
+		t.Errorf("server status code attribute = %v, want 200", v.Emit())
+	}
+
+	// The DynamoDB call is a child of the server span
+	if dynamo.SpanContext.TraceID() != server.SpanContext.TraceID() || dynamo.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("DynamoDB span parent = %s, want the server span %s", dynamo.Parent.SpanID(), server.SpanContext.SpanID())
+	}
+	if v, ok := attributeValue(dynamo.Attributes, "rpc.method"); !ok || v.AsString() != "GetItem" {
+		t.Errorf("DynamoDB rpc.method = %q, want GetItem", v.Emit())
+	}
+	if v, ok := attributeValue(dynamo.Attributes, "aws.dynamodb.table_names"); !ok || len(v.AsStringSlice()) != 1 || v.AsStringSlice()[0] != "insident" {
+		t.Errorf("DynamoDB table names = %v, want [insident]", v.Emit())
+	}
+}
+
+func TestRequestSpansStartNewTraceWithoutTraceparent(t *testing.T) {
+	exporter := setupInMemory(t)
+
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/health", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	if _, err := app.Test(httptest.NewRequest(http.MethodGet, "/health", nil)); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	if spans[0].Parent.IsValid() || !spans[0].SpanContext.IsValid() {
+		t.Errorf("span parent = %v, want a new root span", spans[0].Parent)
+	}
+}
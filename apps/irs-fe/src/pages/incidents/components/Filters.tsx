@@ -86,9 +86,8 @@ const Filters: React.FC<FiltersProps> = ({ filterState, onFilterChange, onClearF
               <option value="">All Incident Types</option>
               <option value="CPU_HIGH">CPU High</option>
               <option value="MEM_HIGH">Memory High</option>
-              <option value="POD_CRASH">Pod Crash</option>
-              <option value="IMAGE_PULL">Image Pull Error</option>
-              <option value="UNHEALTHY_POD">Unhealthy Pod</option>
+              <option value="APP_CRASH">App Crash</option>
+              <option value="APP_SHUTDOWN">App Shutdown</option>
               <option value="APP_ERROR">App Error</option>
               <option value="OTHER">Other</option>
             </select>
@@ -2,16 +2,26 @@ import type { IncidentTicket } from '../types/ticket';
 
 const API_BASE_URL = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080/api';
 
+// Last ticket list and its ETag, so polls can revalidate instead of re-downloading
+let cachedTickets: { etag: string; data: IncidentTicket[] } | null = null;
+
 export class APIService {
-  // Get all tickets
+  // Get all tickets. Returns the same array as the previous call when the list is unchanged.
   static async getAllTickets(): Promise<IncidentTicket[]> {
     try {
-      const response = await fetch(`${API_BASE_URL}/tickets`);
+      const headers: HeadersInit = cachedTickets ? { 'If-None-Match': cachedTickets.etag } : {};
+      const response = await fetch(`${API_BASE_URL}/tickets`, { headers });
+      if (response.status === 304 && cachedTickets) {
+        return cachedTickets.data;
+      }
       if (!response.ok) {
         throw new Error(`HTTP error! status: ${response.status}`);
       }
       const result = await response.json();
-      return result.data || [];
+      const data: IncidentTicket[] = result.data || [];
+      const etag = response.headers.get('ETag');
+      cachedTickets = etag ? { etag, data } : null;
+      return data;
     } catch (error) {
       console.error('Error fetching tickets:', error);
       throw error;
@@ -6,10 +6,10 @@ export interface IncidentTicket {
   suggestions?: string[];
   severity: 'critical' | 'high' | 'medium' | 'low';
   category: 'kubernetes' | 'infrastructure' | 'ci-cd' | 'other';
-  insident_type: 'CPU_HIGH' | 'MEM_HIGH' | 'POD_CRASH' | 'IMAGE_PULL' | 'UNHEALTHY_POD' | 'APP_ERROR' | 'OTHER';
+  insident_type: 'CPU_HIGH' | 'MEM_HIGH' | 'APP_CRASH' | 'APP_SHUTDOWN' | 'APP_ERROR' | 'OTHER';
   environment: 'production' | 'staging' | 'development';
   actionStatus: 'auto' | 'manual' | 'pending';
-  status: 'open' | 'in-progress' | 'solved' | 'closed' | 'pending';
+  status: 'open' | 'in-progress' | 'pending' | 'processing' | 'manual_intervention_required' | 'solved' | 'resolved' | 'closed' | 'failed';
   reporter: string;
   createdAt: string;
   resolutionTime?: string;